@@ -2,23 +2,124 @@ package main
 
 import (
 	"app/internal/application"
+	"app/internal/config"
+	"app/platform/logging"
+	"app/platform/tracing"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
 	// env
-	// ...
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		return
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// logger
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	// exit code
+	// - deferred first so the exporter is closed and the signals are released before exiting
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
 	// app
 	// - config
-	cfg := &application.ConfigServerChi{
-		ServerAddress: ":8080",
-		LoaderFilePath: "docs/db/vehicles_100.json",
+	watchInterval := cfg.Loader.WatchInterval
+	if watchInterval == 0 {
+		// a zero interval disables the watcher
+		watchInterval = -1
 	}
-	app := application.NewServerChi(cfg)
-	// - run
-	if err := app.Run(); err != nil {
-		fmt.Println(err)
+	var traceExporter tracing.Exporter
+	switch cfg.Tracing.Exporter {
+	case "stdout":
+		traceExporter = tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		exporter, err := tracing.NewFileExporter(cfg.Tracing.File)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		defer exporter.Close()
+		traceExporter = exporter
+	}
+	appCfg := &application.ConfigServerChi{
+		ServerAddress:            cfg.Server.Address,
+		ServerReadHeaderTimeout:  cfg.Server.ReadHeaderTimeout,
+		ServerReadTimeout:        cfg.Server.ReadTimeout,
+		ServerWriteTimeout:       cfg.Server.WriteTimeout,
+		ServerIdleTimeout:        cfg.Server.IdleTimeout,
+		ServerShutdownTimeout:    cfg.Server.ShutdownTimeout,
+		LoaderFilePath:           cfg.Loader.FilePath,
+		LoaderSources:            cfg.Loader.Sources,
+		LoaderConflictPolicy:     cfg.Loader.ConflictPolicy,
+		LoaderMode:               cfg.Loader.Mode,
+		LoaderWatchInterval:      watchInterval,
+		StorageMode:              cfg.Storage.Mode,
+		StoragePath:              cfg.Storage.Path,
+		StorageFlushInterval:     cfg.Storage.FlushInterval,
+		TraceExporter:            traceExporter,
+		AuthEnabled:              cfg.Auth.Enabled,
+		AuthAPIKeys:              cfg.Auth.APIKeys,
+		AuthJWTSecret:            cfg.Auth.JWTSecret,
+		AuthJWTPublicKeyFile:     cfg.Auth.JWTPublicKeyFile,
+		AuthRoles:                cfg.Auth.Roles,
+		AuthTenants:              cfg.Auth.Tenants,
+		RateLimitEnabled:         cfg.RateLimit.Enabled,
+		RateLimitRead:            cfg.RateLimit.Read,
+		RateLimitWrite:           cfg.RateLimit.Write,
+		RateLimitBatch:           cfg.RateLimit.Batch,
+		IdempotencyTTL:           cfg.Idempotency.TTL,
+		EventsHeartbeat:          cfg.Events.Heartbeat,
+		EventsReplaySize:         cfg.Events.ReplaySize,
+		EventsWSQueueSize:        cfg.Events.WSQueueSize,
+		EventsWSPingInterval:     cfg.Events.WSPingInterval,
+		WebhooksMaxAttempts:      cfg.Webhooks.MaxAttempts,
+		WebhooksBackoff:          cfg.Webhooks.Backoff,
+		WebhooksMaxBackoff:       cfg.Webhooks.MaxBackoff,
+		WebhooksTimeout:          cfg.Webhooks.Timeout,
+		WebhooksWorkers:          cfg.Webhooks.Workers,
+		WebhooksMaxDeliveries:    cfg.Webhooks.MaxDeliveries,
+		AuditPath:                cfg.Audit.Path,
+		TrashRetention:           cfg.Trash.Retention,
+		FleetsOrphanPolicy:       cfg.Fleets.OrphanPolicy,
+		MaintenanceCascadePolicy: cfg.Maintenance.CascadePolicy,
+		TenantDatasets:           cfg.Tenants.Datasets,
+	}
+	app := application.NewServerChi(appCfg)
+	// - run until SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := app.Run(ctx); err != nil {
+		slog.Error("server stopped", "error", err)
+		exitCode = 1
 		return
 	}
 }
\ No newline at end of file
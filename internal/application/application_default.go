@@ -1,11 +1,31 @@
 package application
 
 import (
+	"app/internal"
+	"app/internal/auth"
+	"app/internal/event"
 	"app/internal/handler"
 	"app/internal/loader"
 	"app/internal/repository"
 	"app/internal/service"
+	"app/internal/webhook"
+	"app/platform/idempotency"
+	"app/platform/logging"
+	"app/platform/metrics"
+	"app/platform/ratelimit"
+	"app/platform/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -15,28 +35,300 @@ import (
 type ConfigServerChi struct {
 	// ServerAddress is the address where the server will be listening
 	ServerAddress string
+	// ServerReadHeaderTimeout is the maximum time to read the headers of a request
+	ServerReadHeaderTimeout time.Duration
+	// ServerReadTimeout is the maximum time to read a whole request
+	ServerReadTimeout time.Duration
+	// ServerWriteTimeout is the maximum time to write a response
+	ServerWriteTimeout time.Duration
+	// ServerIdleTimeout is the maximum time to wait for the next request of a keep-alive connection
+	ServerIdleTimeout time.Duration
+	// ServerShutdownTimeout is the maximum time to drain the requests in flight when the server stops
+	ServerShutdownTimeout time.Duration
+	// ServerListener is the listener the server accepts the connections on instead of ServerAddress (e.g. in tests)
+	ServerListener net.Listener
 	// LoaderFilePath is the path to the file that contains the vehicles
 	LoaderFilePath string
+	// LoaderSources are the paths or globs of several files (JSON, CSV, NDJSON) to merge instead of LoaderFilePath
+	LoaderSources []string
+	// LoaderConflictPolicy is the policy used to merge duplicated ids or registrations (first-wins, last-wins, fail)
+	LoaderConflictPolicy string
+	// LoaderMode is the mode used to load the vehicles (strict, lenient)
+	LoaderMode string
+	// LoaderWatchInterval is the time between two polls of the file of the vehicles (a negative value disables the watcher)
+	LoaderWatchInterval time.Duration
+	// StorageMode is the storage mode of the vehicles (memory, file)
+	StorageMode string
+	// StoragePath is the file where the vehicles are persisted in file mode
+	StoragePath string
+	// StorageFlushInterval is the time between two flushes of the changes in file mode
+	StorageFlushInterval time.Duration
+	// TraceExporter is the destination of the spans of the requests (nil disables the tracing)
+	TraceExporter tracing.Exporter
+	// AuthEnabled requires the requests to /vehicles and /admin to be authenticated
+	AuthEnabled bool
+	// AuthAPIKeys are the SHA-256 hashes (hex) of the accepted API keys
+	AuthAPIKeys []string
+	// AuthJWTSecret is the secret used to verify HS256 tokens
+	AuthJWTSecret string
+	// AuthJWTPublicKeyFile is the path to the PEM public key used to verify RS256 tokens
+	AuthJWTPublicKeyFile string
+	// AuthRoles are the roles granted to the subjects, as subject=role entries (added to the roles claim of the tokens)
+	AuthRoles []string
+	// AuthTenants are the tenants the subjects are bound to, as subject=tenant entries (unless the tenant claim of the tokens is set)
+	AuthTenants []string
+	// RateLimitEnabled limits the requests of each client to /vehicles and /admin
+	RateLimitEnabled bool
+	// RateLimitRead is the limit of the read requests of a client
+	RateLimitRead ratelimit.Limit
+	// RateLimitWrite is the limit of the write requests of a client
+	RateLimitWrite ratelimit.Limit
+	// RateLimitBatch is the limit of the batch imports of a client
+	RateLimitBatch ratelimit.Limit
+	// RateLimitStore is the store of the buckets of the clients (nil keeps them in memory)
+	RateLimitStore ratelimit.Store
+	// IdempotencyTTL is the time the responses of the requests with an Idempotency-Key are kept to be replayed
+	IdempotencyTTL time.Duration
+	// IdempotencyStore is the store of the responses of the requests with an Idempotency-Key (nil keeps them in memory)
+	IdempotencyStore idempotency.Store
+	// EventsHeartbeat is the time between two heartbeats of an idle stream of the changes of the vehicles
+	EventsHeartbeat time.Duration
+	// EventsReplaySize is the number of the last changes of each tenant kept to resume the streams
+	EventsReplaySize int
+	// EventsWSQueueSize is the number of messages queued for a WebSocket client before it is disconnected
+	EventsWSQueueSize int
+	// EventsWSPingInterval is the time between two pings of a WebSocket client
+	EventsWSPingInterval time.Duration
+	// WebhooksMaxAttempts is the number of attempts before a webhook delivery becomes a dead letter
+	WebhooksMaxAttempts int
+	// WebhooksBackoff is the time before the first retry of a webhook delivery, doubled on each retry
+	WebhooksBackoff time.Duration
+	// WebhooksMaxBackoff is the maximum time between two attempts of a webhook delivery
+	WebhooksMaxBackoff time.Duration
+	// WebhooksTimeout is the maximum time of an attempt of a webhook delivery
+	WebhooksTimeout time.Duration
+	// WebhooksWorkers is the number of webhook deliveries attempted at the same time
+	WebhooksWorkers int
+	// WebhooksMaxDeliveries is the number of deliveries kept in the log of each webhook
+	WebhooksMaxDeliveries int
+	// AuditPath is the file the audit log of the changes is appended to (empty to keep it in memory only)
+	AuditPath string
+	// TrashRetention is the time the deleted vehicles are kept in the trash before they are purged (0 keeps them)
+	TrashRetention time.Duration
+	// FleetsOrphanPolicy is what happens to the vehicles and the sub-fleets of a deleted fleet (unassign, parent, restrict)
+	FleetsOrphanPolicy string
+	// MaintenanceCascadePolicy is what happens to the maintenance records of a deleted vehicle (keep, delete, restrict)
+	MaintenanceCascadePolicy string
+	// TenantDatasets are the tenants served besides the default one and the files of their vehicles, as tenant=path entries
+	TenantDatasets []string
 }
 
 // NewServerChi is a function that returns a new instance of ServerChi
 func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 	// default values
 	defaultConfig := &ConfigServerChi{
-		ServerAddress: ":8080",
+		ServerAddress:           ":8080",
+		ServerReadHeaderTimeout: 5 * time.Second,
+		ServerReadTimeout:       15 * time.Second,
+		ServerWriteTimeout:      30 * time.Second,
+		ServerIdleTimeout:       60 * time.Second,
+		ServerShutdownTimeout:   15 * time.Second,
+		LoaderConflictPolicy:    internal.ConflictFirstWins,
+		LoaderMode:              internal.LoadModeLenient,
+		LoaderWatchInterval:     5 * time.Second,
+		StorageMode:             internal.StorageModeMemory,
+		StorageFlushInterval:    10 * time.Second,
+		RateLimitRead:           ratelimit.Limit{Requests: 600, Period: time.Minute},
+		RateLimitWrite:          ratelimit.Limit{Requests: 120, Period: time.Minute},
+		RateLimitBatch:          ratelimit.Limit{Requests: 10, Period: time.Minute},
+		IdempotencyTTL:          24 * time.Hour,
+		EventsHeartbeat:         15 * time.Second,
+		EventsReplaySize:        1000,
+		EventsWSQueueSize:       64,
+		EventsWSPingInterval:    30 * time.Second,
+		WebhooksMaxAttempts:     5,
+		WebhooksBackoff:         time.Second,
+		WebhooksMaxBackoff:      5 * time.Minute,
+		WebhooksTimeout:         10 * time.Second,
+		WebhooksWorkers:         4,
+		WebhooksMaxDeliveries:   100,
 	}
 	if cfg != nil {
 		if cfg.ServerAddress != "" {
 			defaultConfig.ServerAddress = cfg.ServerAddress
 		}
+		if cfg.ServerReadHeaderTimeout != 0 {
+			defaultConfig.ServerReadHeaderTimeout = cfg.ServerReadHeaderTimeout
+		}
+		if cfg.ServerReadTimeout != 0 {
+			defaultConfig.ServerReadTimeout = cfg.ServerReadTimeout
+		}
+		if cfg.ServerWriteTimeout != 0 {
+			defaultConfig.ServerWriteTimeout = cfg.ServerWriteTimeout
+		}
+		if cfg.ServerIdleTimeout != 0 {
+			defaultConfig.ServerIdleTimeout = cfg.ServerIdleTimeout
+		}
+		if cfg.ServerShutdownTimeout != 0 {
+			defaultConfig.ServerShutdownTimeout = cfg.ServerShutdownTimeout
+		}
+		if cfg.ServerListener != nil {
+			defaultConfig.ServerListener = cfg.ServerListener
+		}
 		if cfg.LoaderFilePath != "" {
 			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
 		}
+		if len(cfg.LoaderSources) > 0 {
+			defaultConfig.LoaderSources = cfg.LoaderSources
+		}
+		if cfg.LoaderConflictPolicy != "" {
+			defaultConfig.LoaderConflictPolicy = cfg.LoaderConflictPolicy
+		}
+		if cfg.LoaderMode != "" {
+			defaultConfig.LoaderMode = cfg.LoaderMode
+		}
+		if cfg.LoaderWatchInterval != 0 {
+			defaultConfig.LoaderWatchInterval = cfg.LoaderWatchInterval
+		}
+		if cfg.StorageMode != "" {
+			defaultConfig.StorageMode = cfg.StorageMode
+		}
+		if cfg.StoragePath != "" {
+			defaultConfig.StoragePath = cfg.StoragePath
+		}
+		if cfg.StorageFlushInterval != 0 {
+			defaultConfig.StorageFlushInterval = cfg.StorageFlushInterval
+		}
+		if cfg.TraceExporter != nil {
+			defaultConfig.TraceExporter = cfg.TraceExporter
+		}
+		defaultConfig.AuthEnabled = cfg.AuthEnabled
+		if len(cfg.AuthAPIKeys) > 0 {
+			defaultConfig.AuthAPIKeys = cfg.AuthAPIKeys
+		}
+		if cfg.AuthJWTSecret != "" {
+			defaultConfig.AuthJWTSecret = cfg.AuthJWTSecret
+		}
+		if cfg.AuthJWTPublicKeyFile != "" {
+			defaultConfig.AuthJWTPublicKeyFile = cfg.AuthJWTPublicKeyFile
+		}
+		if len(cfg.AuthRoles) > 0 {
+			defaultConfig.AuthRoles = cfg.AuthRoles
+		}
+		if len(cfg.AuthTenants) > 0 {
+			defaultConfig.AuthTenants = cfg.AuthTenants
+		}
+		defaultConfig.RateLimitEnabled = cfg.RateLimitEnabled
+		if cfg.RateLimitRead.Requests > 0 {
+			defaultConfig.RateLimitRead = cfg.RateLimitRead
+		}
+		if cfg.RateLimitWrite.Requests > 0 {
+			defaultConfig.RateLimitWrite = cfg.RateLimitWrite
+		}
+		if cfg.RateLimitBatch.Requests > 0 {
+			defaultConfig.RateLimitBatch = cfg.RateLimitBatch
+		}
+		if cfg.RateLimitStore != nil {
+			defaultConfig.RateLimitStore = cfg.RateLimitStore
+		}
+		if cfg.IdempotencyTTL != 0 {
+			defaultConfig.IdempotencyTTL = cfg.IdempotencyTTL
+		}
+		if cfg.IdempotencyStore != nil {
+			defaultConfig.IdempotencyStore = cfg.IdempotencyStore
+		}
+		if cfg.EventsHeartbeat != 0 {
+			defaultConfig.EventsHeartbeat = cfg.EventsHeartbeat
+		}
+		if cfg.EventsReplaySize != 0 {
+			defaultConfig.EventsReplaySize = cfg.EventsReplaySize
+		}
+		if cfg.EventsWSQueueSize != 0 {
+			defaultConfig.EventsWSQueueSize = cfg.EventsWSQueueSize
+		}
+		if cfg.EventsWSPingInterval != 0 {
+			defaultConfig.EventsWSPingInterval = cfg.EventsWSPingInterval
+		}
+		if cfg.WebhooksMaxAttempts != 0 {
+			defaultConfig.WebhooksMaxAttempts = cfg.WebhooksMaxAttempts
+		}
+		if cfg.WebhooksBackoff != 0 {
+			defaultConfig.WebhooksBackoff = cfg.WebhooksBackoff
+		}
+		if cfg.WebhooksMaxBackoff != 0 {
+			defaultConfig.WebhooksMaxBackoff = cfg.WebhooksMaxBackoff
+		}
+		if cfg.WebhooksTimeout != 0 {
+			defaultConfig.WebhooksTimeout = cfg.WebhooksTimeout
+		}
+		if cfg.WebhooksWorkers != 0 {
+			defaultConfig.WebhooksWorkers = cfg.WebhooksWorkers
+		}
+		if cfg.WebhooksMaxDeliveries != 0 {
+			defaultConfig.WebhooksMaxDeliveries = cfg.WebhooksMaxDeliveries
+		}
+		if cfg.AuditPath != "" {
+			defaultConfig.AuditPath = cfg.AuditPath
+		}
+		if cfg.TrashRetention != 0 {
+			defaultConfig.TrashRetention = cfg.TrashRetention
+		}
+		if cfg.FleetsOrphanPolicy != "" {
+			defaultConfig.FleetsOrphanPolicy = cfg.FleetsOrphanPolicy
+		}
+		if cfg.MaintenanceCascadePolicy != "" {
+			defaultConfig.MaintenanceCascadePolicy = cfg.MaintenanceCascadePolicy
+		}
+		if len(cfg.TenantDatasets) > 0 {
+			defaultConfig.TenantDatasets = cfg.TenantDatasets
+		}
 	}
 
 	return &ServerChi{
-		serverAddress: defaultConfig.ServerAddress,
-		loaderFilePath: defaultConfig.LoaderFilePath,
+		serverAddress:            defaultConfig.ServerAddress,
+		serverReadHeaderTimeout:  defaultConfig.ServerReadHeaderTimeout,
+		serverReadTimeout:        defaultConfig.ServerReadTimeout,
+		serverWriteTimeout:       defaultConfig.ServerWriteTimeout,
+		serverIdleTimeout:        defaultConfig.ServerIdleTimeout,
+		serverShutdownTimeout:    defaultConfig.ServerShutdownTimeout,
+		serverListener:           defaultConfig.ServerListener,
+		loaderFilePath:           defaultConfig.LoaderFilePath,
+		loaderSources:            defaultConfig.LoaderSources,
+		loaderConflictPolicy:     defaultConfig.LoaderConflictPolicy,
+		loaderMode:               defaultConfig.LoaderMode,
+		loaderWatchInterval:      defaultConfig.LoaderWatchInterval,
+		storageMode:              defaultConfig.StorageMode,
+		storagePath:              defaultConfig.StoragePath,
+		storageFlushInterval:     defaultConfig.StorageFlushInterval,
+		traceExporter:            defaultConfig.TraceExporter,
+		authEnabled:              defaultConfig.AuthEnabled,
+		authAPIKeys:              defaultConfig.AuthAPIKeys,
+		authJWTSecret:            defaultConfig.AuthJWTSecret,
+		authJWTPublicKeyFile:     defaultConfig.AuthJWTPublicKeyFile,
+		authRoles:                defaultConfig.AuthRoles,
+		authTenants:              defaultConfig.AuthTenants,
+		rateLimitEnabled:         defaultConfig.RateLimitEnabled,
+		rateLimitRead:            defaultConfig.RateLimitRead,
+		rateLimitWrite:           defaultConfig.RateLimitWrite,
+		rateLimitBatch:           defaultConfig.RateLimitBatch,
+		rateLimitStore:           defaultConfig.RateLimitStore,
+		idempotencyTTL:           defaultConfig.IdempotencyTTL,
+		idempotencyStore:         defaultConfig.IdempotencyStore,
+		eventsHeartbeat:          defaultConfig.EventsHeartbeat,
+		eventsReplaySize:         defaultConfig.EventsReplaySize,
+		eventsWSQueueSize:        defaultConfig.EventsWSQueueSize,
+		eventsWSPingInterval:     defaultConfig.EventsWSPingInterval,
+		webhooksMaxAttempts:      defaultConfig.WebhooksMaxAttempts,
+		webhooksBackoff:          defaultConfig.WebhooksBackoff,
+		webhooksMaxBackoff:       defaultConfig.WebhooksMaxBackoff,
+		webhooksTimeout:          defaultConfig.WebhooksTimeout,
+		webhooksWorkers:          defaultConfig.WebhooksWorkers,
+		webhooksMaxDeliveries:    defaultConfig.WebhooksMaxDeliveries,
+		auditPath:                defaultConfig.AuditPath,
+		trashRetention:           defaultConfig.TrashRetention,
+		fleetsOrphanPolicy:       defaultConfig.FleetsOrphanPolicy,
+		maintenanceCascadePolicy: defaultConfig.MaintenanceCascadePolicy,
+		tenantDatasets:           defaultConfig.TenantDatasets,
 	}
 }
 
@@ -44,61 +336,679 @@ func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 type ServerChi struct {
 	// serverAddress is the address where the server will be listening
 	serverAddress string
+	// serverReadHeaderTimeout is the maximum time to read the headers of a request
+	serverReadHeaderTimeout time.Duration
+	// serverReadTimeout is the maximum time to read a whole request
+	serverReadTimeout time.Duration
+	// serverWriteTimeout is the maximum time to write a response
+	serverWriteTimeout time.Duration
+	// serverIdleTimeout is the maximum time to wait for the next request of a keep-alive connection
+	serverIdleTimeout time.Duration
+	// serverShutdownTimeout is the maximum time to drain the requests in flight when the server stops
+	serverShutdownTimeout time.Duration
+	// serverListener is the listener the server accepts the connections on instead of serverAddress
+	serverListener net.Listener
 	// loaderFilePath is the path to the file that contains the vehicles
 	loaderFilePath string
+	// loaderSources are the paths or globs of several files to merge instead of loaderFilePath
+	loaderSources []string
+	// loaderConflictPolicy is the policy used to merge duplicated ids or registrations
+	loaderConflictPolicy string
+	// loaderMode is the mode used to load the vehicles (strict, lenient)
+	loaderMode string
+	// loaderWatchInterval is the time between two polls of the file of the vehicles
+	loaderWatchInterval time.Duration
+	// storageMode is the storage mode of the vehicles (memory, file)
+	storageMode string
+	// storagePath is the file where the vehicles are persisted in file mode
+	storagePath string
+	// storageFlushInterval is the time between two flushes of the changes in file mode
+	storageFlushInterval time.Duration
+	// traceExporter is the destination of the spans of the requests
+	traceExporter tracing.Exporter
+	// authEnabled requires the requests to /vehicles and /admin to be authenticated
+	authEnabled bool
+	// authAPIKeys are the SHA-256 hashes (hex) of the accepted API keys
+	authAPIKeys []string
+	// authJWTSecret is the secret used to verify HS256 tokens
+	authJWTSecret string
+	// authJWTPublicKeyFile is the path to the PEM public key used to verify RS256 tokens
+	authJWTPublicKeyFile string
+	// authRoles are the roles granted to the subjects, as subject=role entries
+	authRoles []string
+	// authTenants are the tenants the subjects are bound to, as subject=tenant entries
+	authTenants []string
+	// rateLimitEnabled limits the requests of each client to /vehicles and /admin
+	rateLimitEnabled bool
+	// rateLimitRead is the limit of the read requests of a client
+	rateLimitRead ratelimit.Limit
+	// rateLimitWrite is the limit of the write requests of a client
+	rateLimitWrite ratelimit.Limit
+	// rateLimitBatch is the limit of the batch imports of a client
+	rateLimitBatch ratelimit.Limit
+	// rateLimitStore is the store of the buckets of the clients
+	rateLimitStore ratelimit.Store
+	// idempotencyTTL is the time the responses of the requests with an Idempotency-Key are kept
+	idempotencyTTL time.Duration
+	// idempotencyStore is the store of the responses of the requests with an Idempotency-Key
+	idempotencyStore idempotency.Store
+	// eventsHeartbeat is the time between two heartbeats of an idle stream of the changes
+	eventsHeartbeat time.Duration
+	// eventsReplaySize is the number of the last changes of each tenant kept to resume the streams
+	eventsReplaySize int
+	// eventsWSQueueSize is the number of messages queued for a WebSocket client before it is disconnected
+	eventsWSQueueSize int
+	// eventsWSPingInterval is the time between two pings of a WebSocket client
+	eventsWSPingInterval time.Duration
+	// webhooksMaxAttempts is the number of attempts before a webhook delivery becomes a dead letter
+	webhooksMaxAttempts int
+	// webhooksBackoff is the time before the first retry of a webhook delivery
+	webhooksBackoff time.Duration
+	// webhooksMaxBackoff is the maximum time between two attempts of a webhook delivery
+	webhooksMaxBackoff time.Duration
+	// webhooksTimeout is the maximum time of an attempt of a webhook delivery
+	webhooksTimeout time.Duration
+	// webhooksWorkers is the number of webhook deliveries attempted at the same time
+	webhooksWorkers int
+	// webhooksMaxDeliveries is the number of deliveries kept in the log of each webhook
+	webhooksMaxDeliveries int
+	// auditPath is the file the audit log of the changes is appended to
+	auditPath string
+	// trashRetention is the time the deleted vehicles are kept in the trash before they are purged
+	trashRetention time.Duration
+	// fleetsOrphanPolicy is what happens to the vehicles and the sub-fleets of a deleted fleet
+	fleetsOrphanPolicy string
+	// maintenanceCascadePolicy is what happens to the maintenance records of a deleted vehicle
+	maintenanceCascadePolicy string
+	// tenantDatasets are the tenants served besides the default one and the files of their vehicles
+	tenantDatasets []string
 }
 
-// Run is a method that runs the application
-func (a *ServerChi) Run() (err error) {
+// Run is a method that runs the application until the context is done.
+// Then the server stops accepting connections, drains the requests in flight within the shutdown timeout
+// and flushes the pending changes of the storage.
+func (a *ServerChi) Run(ctx context.Context) (err error) {
 	// dependencies
+	// - authentication and authorization: the permissions are declared per route with the minimum role
+	var authenticate []func(http.Handler) http.Handler
+	authorize := func(role string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if a.authEnabled {
+		var authenticators []internal.Authenticator
+		if authenticators, err = a.authenticators(); err != nil {
+			return
+		}
+		var roles map[string][]string
+		if roles, err = auth.ParseRoleMapping(a.authRoles); err != nil {
+			return
+		}
+		authenticate = append(authenticate, auth.Middleware("vehicles", authenticators...), auth.MapRoles(roles))
+		authorize = auth.Require
+	}
+	// - tenants: each request is scoped to the tenant of its principal or of its X-Tenant-ID header,
+	// which is ignored without authentication so an anonymous caller only reaches the default tenant
+	var tenantsBound map[string]string
+	if tenantsBound, err = auth.ParseTenantMapping(a.authTenants); err != nil {
+		return
+	}
+	var datasets map[string]string
+	if datasets, err = parseTenantDatasets(a.tenantDatasets); err != nil {
+		return
+	}
+	authenticate = append(authenticate, auth.ResolveTenant(tenantsBound, func(tenant string) bool {
+		_, ok := datasets[tenant]
+		return ok || tenant == internal.TenantDefault
+	}))
+	// - rate limits: the routes are limited by class (read, write, batch), each client with its own buckets
+	limit := func(name string, l ratelimit.Limit) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if a.rateLimitEnabled {
+		st := a.rateLimitStore
+		if st == nil {
+			st = ratelimit.NewMemoryStore(nil)
+		}
+		limit = func(name string, l ratelimit.Limit) func(http.Handler) http.Handler {
+			return ratelimit.Middleware(st, name, l, clientKey)
+		}
+	}
+	limitRead, limitWrite, limitBatch := limit("read", a.rateLimitRead), limit("write", a.rateLimitWrite), limit("batch", a.rateLimitBatch)
+	// - idempotency of the creations: the retries with the same Idempotency-Key get the first response
+	stIdempotency := a.idempotencyStore
+	if stIdempotency == nil {
+		stIdempotency = idempotency.NewMemoryStore(nil)
+	}
+	idempotent := idempotency.Middleware(stIdempotency, a.idempotencyTTL, idempotencyKey)
 	// - loader
-	ld := loader.NewVehicleJSONFile(a.loaderFilePath)
-	db, err := ld.Load()
+	var ld interface {
+		internal.VehicleLoader
+		internal.VehicleLoadReporter
+	}
+	watched := []string{a.loaderFilePath}
+	switch {
+	case len(a.loaderSources) > 0:
+		ldComposite := loader.NewVehicleComposite(a.loaderSources, a.loaderMode, a.loaderConflictPolicy)
+		if watched, err = ldComposite.Files(); err != nil {
+			return
+		}
+		ld = ldComposite
+	default:
+		ld = loader.NewVehicleJSONFile(a.loaderFilePath, a.loaderMode)
+	}
+	// - metrics
+	reg := metrics.NewRegistry()
+	mtRepository := metrics.NewHistogramVec("vehicles_repository_operation_duration_seconds", "Latency of the operations of the vehicle repository by method.", nil, "method")
+	mtReloads := metrics.NewCounterVec("vehicles_loader_reloads_total", "Number of loads of the dataset by trigger and outcome.", "trigger", "outcome")
+	reg.MustRegister(mtRepository, mtReloads)
+	// - repository: the vehicles of each tenant are kept apart, the default tenant owns the data source of the loader
+	maps := make(map[string]*repository.VehicleMap, len(datasets)+1)
+	temporals := make(map[string]*repository.VehicleTemporal, len(datasets)+1)
+	stores := make(map[string]*repository.VehiclePersisted)
+	newTenant := func(tenant string, mp *repository.VehicleMap) {
+		maps[tenant] = mp
+		var rpTenant internal.VehicleRepository = mp
+		if a.storageMode == internal.StorageModeFile {
+			stores[tenant] = repository.NewVehiclePersisted(rpTenant, a.tenantStoragePath(tenant), loader.WriteVehiclesDocument)
+			rpTenant = stores[tenant]
+		}
+		// - every version of the vehicles is kept for the point-in-time queries
+		temporals[tenant] = repository.NewVehicleTemporal(rpTenant)
+	}
+	newTenant(internal.TenantDefault, repository.NewVehicleMap(nil))
+	for tenant := range datasets {
+		newTenant(tenant, repository.NewVehicleMap(nil))
+	}
+	hs := repository.NewVehicleTenants(temporals)
+	var rp internal.VehicleRepository = hs
+	ps := stores[internal.TenantDefault]
+	// - the changes that succeed are published to the streams
+	bus := event.NewVehicleBus(a.eventsReplaySize, 0)
+	rp = repository.NewVehiclePublished(rp, bus)
+	rp = repository.NewVehicleInstrumented(rp, func(method string, elapsed time.Duration) {
+		mtRepository.Observe(elapsed.Seconds(), method)
+	})
+	// - tracing
+	var tracer *tracing.Tracer
+	if a.traceExporter != nil {
+		tracer = tracing.NewTracer(a.traceExporter)
+		rp = repository.NewVehicleTraced(rp, tracer)
+	}
+	reg.MustRegister(metrics.NewGaugeVecFunc("vehicles_by_fuel_type", "Number of vehicles by tenant and fuel type.", []string{"tenant", "fuel_type"}, func(set func(value float64, values ...string)) {
+		// read the maps of the tenants directly, so the scrapes are not measured as repository operations
+		for tenant, mp := range maps {
+			v, _ := mp.FindAll(context.Background())
+			count := make(map[string]float64)
+			for _, vh := range v {
+				count[vh.FuelType]++
+			}
+			for fuelType, n := range count {
+				set(n, tenant, fuelType)
+			}
+		}
+	}))
+	// - reloader: the first load goes through it, so it fails the same way a reload does
+	rl := loader.NewVehicleReloader(ld, rp)
+	rl.OnReload(func(res internal.ReloadResult) {
+		mtReloads.Inc(res.Trigger, res.Outcome)
+	})
+	// - in file mode the vehicles persisted by a previous run take precedence over the data source
+	restored := false
+	if ps != nil {
+		if _, e := os.Stat(a.storagePath); !errors.Is(e, fs.ErrNotExist) {
+			var res internal.ReloadResult
+			res, err = rl.ReloadWith(loader.NewVehicleJSONFile(a.storagePath, internal.LoadModeStrict), "storage")
+			if err != nil {
+				return
+			}
+			slog.Info("storage: vehicles restored", "path", a.storagePath, "loaded", res.Loaded)
+			restored = true
+		}
+	}
+	if !restored {
+		_, err = rl.Reload("startup")
+	}
+	// - log the data-quality report of the load
+	if rep := ld.Report(); rep.Skipped > 0 {
+		slog.Warn("loader: records skipped", "source", rep.Source, "skipped", rep.Skipped, "total", rep.Total, "duplicates", len(rep.Duplicates), "missing_fields", len(rep.MissingFields), "out_of_range", len(rep.OutOfRange))
+		for _, issues := range [][]internal.LoadIssue{rep.Duplicates, rep.MissingFields, rep.OutOfRange} {
+			for _, issue := range issues {
+				if issue.Skipped {
+					slog.Warn("loader: record skipped", "index", issue.Index, "id", issue.Id, "field", issue.Field, "kind", issue.Kind, "reason", issue.Message)
+				}
+			}
+		}
+	}
 	if err != nil {
 		return
 	}
-	// - repository
-	rp := repository.NewVehicleMap(db)
+	// - the datasets of the other tenants are loaded once, from their storage if a previous run persisted them
+	for _, tenant := range hs.Tenants() {
+		var ldTenant interface {
+			internal.VehicleLoader
+			internal.VehicleLoadReporter
+		}
+		_, errStat := os.Stat(a.tenantStoragePath(tenant))
+		switch {
+		case tenant == internal.TenantDefault:
+			continue
+		case stores[tenant] != nil && !errors.Is(errStat, fs.ErrNotExist):
+			ldTenant = loader.NewVehicleJSONFile(a.tenantStoragePath(tenant), internal.LoadModeStrict)
+		case datasets[tenant] != "":
+			ldTenant = loader.NewVehicleComposite([]string{datasets[tenant]}, a.loaderMode, a.loaderConflictPolicy)
+		default:
+			continue
+		}
+		var v map[int]internal.Vehicle
+		if v, err = ldTenant.Load(); err != nil {
+			err = fmt.Errorf("tenants: %s: %w", tenant, err)
+			return
+		}
+		if err = rp.ReplaceAll(internal.ContextWithTenant(context.Background(), tenant), v); err != nil {
+			return
+		}
+		slog.Info("tenants: vehicles loaded", "tenant", tenant, "loaded", len(v), "skipped", ldTenant.Report().Skipped)
+	}
+	// - audit log: every mutation of the service is recorded with its actor
+	rpAudit := repository.NewAuditLog()
+	if a.auditPath != "" {
+		if rpAudit, err = repository.OpenAuditLog(a.auditPath); err != nil {
+			return
+		}
+	}
+	defer rpAudit.Close()
+	// - trash of the deleted vehicles of each tenant, saved next to its vehicles in file mode
+	trashes := make(map[string]internal.VehicleTrash, len(temporals))
+	for tenant := range temporals {
+		tr := repository.NewVehicleTrashMap()
+		if stores[tenant] != nil {
+			if tr, err = repository.OpenVehicleTrashMap(a.tenantStoragePath(tenant)+".trash", loader.ReadTrashDocument, loader.WriteTrashDocument); err != nil {
+				return
+			}
+		}
+		trashes[tenant] = tr
+	}
+	rpTrash := repository.NewVehicleTrashTenants(trashes)
+	// - background tasks, stopped once the server is shut down
+	ctxTasks, cancelTasks := context.WithCancel(context.Background())
+	defer cancelTasks()
+	var tasks sync.WaitGroup
+	// - watcher
+	if a.loaderWatchInterval > 0 {
+		for _, path := range watched {
+			wt := loader.NewVehicleFileWatcher(path, a.loaderWatchInterval, rl)
+			tasks.Add(1)
+			go func() {
+				defer tasks.Done()
+				wt.Run(ctxTasks)
+			}()
+		}
+	}
+	// - persistence
+	for _, st := range stores {
+		tasks.Add(1)
+		go func(st *repository.VehiclePersisted) {
+			defer tasks.Done()
+			st.Run(ctxTasks, a.storageFlushInterval)
+		}(st)
+	}
+	// - webhooks: the mutations of the service are delivered to the subscriptions in the background
+	rpWebhook := repository.NewWebhookMap(a.webhooksMaxDeliveries)
+	dp := webhook.NewDispatcher(rpWebhook, webhook.ConfigDispatcher{
+		Timeout:     a.webhooksTimeout,
+		MaxAttempts: a.webhooksMaxAttempts,
+		Backoff:     a.webhooksBackoff,
+		MaxBackoff:  a.webhooksMaxBackoff,
+		Workers:     a.webhooksWorkers,
+	})
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		dp.Run(ctxTasks)
+	}()
+	// - fleets of each tenant, saved next to its vehicles in file mode
+	fleets := make(map[string]internal.FleetRepository, len(temporals))
+	for tenant := range temporals {
+		fl := repository.NewFleetMap()
+		if stores[tenant] != nil {
+			if fl, err = repository.OpenFleetMap(a.tenantStoragePath(tenant) + ".fleets"); err != nil {
+				return
+			}
+		}
+		fleets[tenant] = fl
+	}
+	rpFleet := repository.NewFleetTenants(fleets)
+	// - maintenance records of each tenant
+	maintenance := make(map[string]internal.MaintenanceRepository, len(temporals))
+	for tenant := range temporals {
+		maintenance[tenant] = repository.NewMaintenanceMap()
+	}
+	rpMaintenance := repository.NewMaintenanceTenants(maintenance)
 	// - service
-	sv := service.NewVehicleDefault(rp)
+	var sv internal.VehicleService = service.NewVehicleDefault(rp, hs, rpTrash)
+	sv = service.NewVehicleGrouped(sv, rpFleet)
+	sv = service.NewVehicleMaintained(sv, rpMaintenance, a.maintenanceCascadePolicy)
+	sv = service.NewVehicleAudited(sv, rpAudit)
+	sv = service.NewVehicleNotified(sv, dp)
+	if tracer != nil {
+		sv = service.NewVehicleTraced(sv, tracer)
+	}
+	// - retention of the trash of each tenant
+	if a.trashRetention > 0 {
+		pg := service.NewTrashPurger(sv, a.trashRetention, 0)
+		for _, tenant := range hs.Tenants() {
+			tasks.Add(1)
+			go func(ctx context.Context) {
+				defer tasks.Done()
+				pg.Run(ctx)
+			}(internal.ContextWithTenant(ctxTasks, tenant))
+		}
+	}
 	// - handler
 	hd := handler.NewVehicleDefault(sv)
+	sn := loader.NewVehicleSnapshot(rp)
+	hdAdmin := handler.NewAdminDefault(ld, rl, sn)
+	hdAudit := handler.NewAuditDefault(rpAudit)
+	hdWebhook := handler.NewWebhookDefault(service.NewWebhookDefault(rpWebhook, dp))
+	hdFleet := handler.NewFleetDefault(service.NewFleetDefault(rpFleet, rp, a.fleetsOrphanPolicy))
+	hdMaintenance := handler.NewMaintenanceDefault(service.NewMaintenanceDefault(rpMaintenance, rp))
+	hdEvents := handler.NewVehicleEventsDefault(bus, a.eventsHeartbeat)
+	hdSocket := handler.NewVehicleSocketDefault(bus, a.eventsWSQueueSize, 0, a.eventsWSPingInterval)
+	var hcStorage internal.HealthChecker = internal.HealthCheckerFunc(func() internal.ComponentHealth {
+		return internal.ComponentHealth{Status: internal.HealthUp, Details: map[string]any{"mode": internal.StorageModeMemory}}
+	})
+	if ps != nil {
+		hcStorage = ps
+	}
+	// - the repository is down if the one of a tenant is, with the number of vehicles of each tenant
+	hcRepository := internal.HealthCheckerFunc(func() (h internal.ComponentHealth) {
+		h = internal.ComponentHealth{Status: internal.HealthUp, Details: make(map[string]any, len(maps))}
+		for tenant, mp := range maps {
+			hc := mp.Health()
+			h.Details[tenant] = hc.Details
+			if hc.Status != internal.HealthUp {
+				h.Status = hc.Status
+				h.Message = tenant + ": " + hc.Message
+			}
+		}
+		return
+	})
+	hdHealth := handler.NewHealthDefault(rl, map[string]internal.HealthChecker{
+		"loader":      rl,
+		"repository":  hcRepository,
+		"persistence": hcStorage,
+	})
 	// router
 	rt := chi.NewRouter()
 	// - middlewares
-	rt.Use(middleware.Logger)
+	if tracer != nil {
+		rt.Use(tracing.Middleware(tracer))
+	}
+	rt.Use(logging.Middleware(slog.Default()))
+	rt.Use(metrics.NewHTTPMetrics(reg).Middleware)
 	rt.Use(middleware.Recoverer)
 	// - endpoints
+	// - GET /healthz
+	rt.Get("/healthz", hdHealth.Liveness())
+	// - GET /readyz
+	rt.Get("/readyz", hdHealth.Readiness())
+	// - GET /metrics
+	rt.Get("/metrics", metrics.Handler(reg))
+	// - GET /ws
+	rt.Group(func(rt chi.Router) {
+		rt.Use(authenticate...)
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/ws", hdSocket.Connect())
+	})
 	rt.Route("/vehicles", func(rt chi.Router) {
-		// - GET /vehicles
-		rt.Get("/", hd.GetAll())
+		rt.Use(authenticate...)
+		// - GET /vehicles?as_of={time}&tag={tag}&tag_match={all|any}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/", hd.GetAll())
+		// - GET /vehicles/events
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/events", hdEvents.Stream())
 		// - POST /vehicles
-		rt.Post("/", hd.Create())
+		rt.With(limitWrite, authorize(auth.RoleEditor), idempotent).Post("/", hd.Create())
 		// - GET /vehicles?color={color}&year={year}
-		rt.Get("/color/{color}/year/{year}", hd.GetByColorAndYear())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/color/{color}/year/{year}", hd.GetByColorAndYear())
 		// - GET /vehicles/average-speed/brand/{brand}
-		rt.Get("/average-speed/brand/{brand}", hd.GetAverageSpeedByBrand())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/average-speed/brand/{brand}", hd.GetAverageSpeedByBrand())
 		// - POST /vehicles/batch
-		rt.Post("/batch", hd.CreateBatch())
+		rt.With(limitBatch, authorize(auth.RoleAdmin), idempotent).Post("/batch", hd.CreateBatch())
 		// - PUT /vehicles/{id}/update_speed
-		rt.Put("/{id}/update_speed", hd.UpdateMaxSpeed())
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Put("/{id}/update_speed", hd.UpdateMaxSpeed())
 		// - GET /vehicles/fuel-type/{type}
-		rt.Get("/fuel-type/{type}", hd.GetByFuelType())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/fuel-type/{type}", hd.GetByFuelType())
 		// - DELETE /vehicles/{id}
-		rt.Delete("/{id}", hd.Delete())
+		rt.With(limitWrite, authorize(auth.RoleAdmin)).Delete("/{id}", hd.Delete())
 		// - GET /vehicles/transmission/{type}
-		rt.Get("/transmission/{type}", hd.GetByTransmissionType())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/transmission/{type}", hd.GetByTransmissionType())
 		// - PUT /vehicles/{id}/update_fuel
-		rt.Put("/{id}/update_fuel", hd.UpdateFuelType())
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Put("/{id}/update_fuel", hd.UpdateFuelType())
 		// - GET GET /vehicles/dimensions?length={min_length}-{max_length}&width={min_width}-{max_width}
-		rt.Get("/dimensions", hd.GetByDimensions())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/dimensions", hd.GetByDimensions())
 		// - GET /vehicles/weight?min={weight_min}&max={weight_max}
-		rt.Get("/weight", hd.GetByWeight())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/weight", hd.GetByWeight())
 		// - GET /vehicles/brand/{brand}/between/{start_year}/{end_year}
-		rt.Get("/brand/{brand}/between/{start_year}/{end_year}", hd.GetByBrandAndRange())
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/brand/{brand}/between/{start_year}/{end_year}", hd.GetByBrandAndRange())
+		// - GET /vehicles/trash
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/trash", hd.GetTrash())
+		// - POST /vehicles/trash/purge?older_than={duration}
+		rt.With(limitWrite, authorize(auth.RoleAdmin)).Post("/trash/purge", hd.PurgeTrash())
+		// - DELETE /vehicles/trash/{id}
+		rt.With(limitWrite, authorize(auth.RoleAdmin)).Delete("/trash/{id}", hd.Purge())
+		// - POST /vehicles/{id}/restore
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Post("/{id}/restore", hd.Restore())
+		// - GET /vehicles/{id}?as_of={time}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}", hd.GetById())
+		// - GET /vehicles/{id}/versions
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/versions", hd.GetVersions())
+		// - POST /vehicles/{id}/revert?version={version}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Post("/{id}/revert", hd.Revert())
+		// - GET /vehicles/{id}/history
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/history", hdAudit.History())
+		// - POST /vehicles/{id}/tags
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Post("/{id}/tags", hd.AddTags())
+		// - DELETE /vehicles/{id}/tags/{tag}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Delete("/{id}/tags/{tag}", hd.RemoveTag())
+		// - POST /vehicles/{id}/maintenance
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Post("/{id}/maintenance", hdMaintenance.Create())
+		// - GET /vehicles/{id}/maintenance
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/maintenance", hdMaintenance.GetAll())
+		// - GET /vehicles/{id}/maintenance/{record_id}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/maintenance/{record_id}", hdMaintenance.GetById())
+		// - PUT /vehicles/{id}/maintenance/{record_id}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Put("/{id}/maintenance/{record_id}", hdMaintenance.Update())
+		// - DELETE /vehicles/{id}/maintenance/{record_id}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Delete("/{id}/maintenance/{record_id}", hdMaintenance.Delete())
+	})
+	// - GET /tags
+	rt.Group(func(rt chi.Router) {
+		rt.Use(authenticate...)
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/tags", hd.GetTags())
+	})
+	// - GET /audit?actor={actor}&since={since}
+	rt.Group(func(rt chi.Router) {
+		rt.Use(authenticate...)
+		rt.With(limitRead, authorize(auth.RoleAdmin)).Get("/audit", hdAudit.Search())
+	})
+	rt.Route("/admin", func(rt chi.Router) {
+		rt.Use(authenticate...)
+		rt.Use(authorize(auth.RoleAdmin))
+		// - GET /admin/load-report
+		rt.With(limitRead, auth.RequireTenant(internal.TenantDefault)).Get("/load-report", hdAdmin.LoadReport())
+		// - POST /admin/reload
+		rt.With(limitWrite, auth.RequireTenant(internal.TenantDefault)).Post("/reload", hdAdmin.Reload())
+		// - GET /admin/snapshot
+		rt.With(limitRead).Get("/snapshot", hdAdmin.Snapshot())
+		// - POST /admin/restore
+		rt.With(limitWrite).Post("/restore", hdAdmin.Restore())
+	})
+	rt.Route("/webhooks", func(rt chi.Router) {
+		rt.Use(authenticate...)
+		rt.Use(authorize(auth.RoleAdmin))
+		// - POST /webhooks
+		rt.With(limitWrite).Post("/", hdWebhook.Create())
+		// - GET /webhooks
+		rt.With(limitRead).Get("/", hdWebhook.GetAll())
+		// - GET /webhooks/dead-letters
+		rt.With(limitRead).Get("/dead-letters", hdWebhook.GetDeadLetters())
+		// - POST /webhooks/deliveries/{id}/retry
+		rt.With(limitWrite).Post("/deliveries/{id}/retry", hdWebhook.Redeliver())
+		// - GET /webhooks/{id}
+		rt.With(limitRead).Get("/{id}", hdWebhook.GetById())
+		// - PUT /webhooks/{id}
+		rt.With(limitWrite).Put("/{id}", hdWebhook.Update())
+		// - DELETE /webhooks/{id}
+		rt.With(limitWrite).Delete("/{id}", hdWebhook.Delete())
+		// - GET /webhooks/{id}/deliveries
+		rt.With(limitRead).Get("/{id}/deliveries", hdWebhook.GetDeliveries())
+	})
+	rt.Route("/fleets", func(rt chi.Router) {
+		rt.Use(authenticate...)
+		// - POST /fleets
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Post("/", hdFleet.Create())
+		// - GET /fleets
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/", hdFleet.GetAll())
+		// - GET /fleets/{id}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}", hdFleet.GetById())
+		// - PUT /fleets/{id}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Put("/{id}", hdFleet.Update())
+		// - DELETE /fleets/{id}
+		rt.With(limitWrite, authorize(auth.RoleAdmin)).Delete("/{id}", hdFleet.Delete())
+		// - GET /fleets/{id}/vehicles?subfleets={bool}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/vehicles", hdFleet.GetVehicles())
+		// - GET /fleets/{id}/stats?subfleets={bool}
+		rt.With(limitRead, authorize(auth.RoleViewer)).Get("/{id}/stats", hdFleet.GetStats())
+		// - PUT /fleets/{id}/vehicles/{vehicle_id}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Put("/{id}/vehicles/{vehicle_id}", hdFleet.AssignVehicle())
+		// - DELETE /fleets/{id}/vehicles/{vehicle_id}
+		rt.With(limitWrite, authorize(auth.RoleEditor)).Delete("/{id}/vehicles/{vehicle_id}", hdFleet.UnassignVehicle())
 	})
 
+	// server
+	srv := &http.Server{
+		Addr:              a.serverAddress,
+		Handler:           rt,
+		ReadHeaderTimeout: a.serverReadHeaderTimeout,
+		ReadTimeout:       a.serverReadTimeout,
+		WriteTimeout:      a.serverWriteTimeout,
+		IdleTimeout:       a.serverIdleTimeout,
+	}
+	// - the event streams never end by themselves, they are closed so the shutdown can drain them
+	srv.RegisterOnShutdown(bus.Close)
+
 	// run server
-	err = http.ListenAndServe(a.serverAddress, rt)
+	errServe := make(chan error, 1)
+	go func() {
+		if a.serverListener != nil {
+			slog.Info("server: listening", "address", a.serverListener.Addr().String())
+			errServe <- srv.Serve(a.serverListener)
+			return
+		}
+		slog.Info("server: listening", "address", a.serverAddress)
+		errServe <- srv.ListenAndServe()
+	}()
+	select {
+	case err = <-errServe:
+		// the server could not start (e.g. the address is in use)
+	case <-ctx.Done():
+		slog.Info("server: shutting down", "drain_timeout", a.serverShutdownTimeout)
+		ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), a.serverShutdownTimeout)
+		defer cancelShutdown()
+		if err = srv.Shutdown(ctxShutdown); err != nil {
+			// the deadline is exceeded, the remaining connections are closed
+			err = fmt.Errorf("server: shutdown: %w", err)
+			srv.Close()
+		}
+	}
+
+	// stop the background tasks, so no reload happens after the last flush
+	cancelTasks()
+	tasks.Wait()
+	for tenant, st := range stores {
+		if e := st.Flush(); e != nil {
+			slog.Error("storage: flush failed", "path", a.tenantStoragePath(tenant), "error", e)
+			err = errors.Join(err, e)
+		}
+	}
 	return
+}
+
+// tenantStoragePath is a method that returns the file where the vehicles of a tenant are persisted in file mode:
+// the storage path for the default tenant, the storage path suffixed by the tenant otherwise (e.g. vehicles.acme.json)
+func (a *ServerChi) tenantStoragePath(tenant string) string {
+	if tenant == internal.TenantDefault {
+		return a.storagePath
+	}
+	ext := filepath.Ext(a.storagePath)
+	return strings.TrimSuffix(a.storagePath, ext) + "." + tenant + ext
+}
+
+// parseTenantDatasets is a function that parses the tenants served besides the default one and the files
+// of their vehicles, as tenant=path entries
+func parseTenantDatasets(entries []string) (datasets map[string]string, err error) {
+	datasets = make(map[string]string)
+	for _, entry := range entries {
+		tenant, path, ok := strings.Cut(entry, "=")
+		tenant, path = strings.TrimSpace(tenant), strings.TrimSpace(path)
+		if _, declared := datasets[tenant]; !ok || declared || !internal.IsTenantName(tenant) || tenant == internal.TenantDefault {
+			err = fmt.Errorf("tenants: invalid dataset %q, expected tenant=path of a tenant other than %s", entry, internal.TenantDefault)
+			datasets = nil
+			return
+		}
+		datasets[tenant] = path
+	}
+	return
+}
+
+// authenticators is a method that returns the authenticators of the configured credentials
+func (a *ServerChi) authenticators() (authenticators []internal.Authenticator, err error) {
+	// - jwt
+	var cfgJWT auth.ConfigJWT
+	if a.authJWTSecret != "" {
+		cfgJWT.Secret = []byte(a.authJWTSecret)
+	}
+	if a.authJWTPublicKeyFile != "" {
+		var data []byte
+		if data, err = os.ReadFile(a.authJWTPublicKeyFile); err != nil {
+			return
+		}
+		if cfgJWT.PublicKey, err = auth.ParseRSAPublicKey(data); err != nil {
+			return
+		}
+	}
+	if cfgJWT.Secret != nil || cfgJWT.PublicKey != nil {
+		authenticators = append(authenticators, auth.NewJWT(cfgJWT))
+	}
+	// - api keys
+	if len(a.authAPIKeys) > 0 {
+		var ak *auth.APIKeys
+		if ak, err = auth.NewAPIKeys(a.authAPIKeys); err != nil {
+			return
+		}
+		authenticators = append(authenticators, ak)
+	}
+	if len(authenticators) == 0 {
+		err = errors.New("auth: enabled without API keys or JWT keys")
+	}
+	return
+}
+
+// clientKey is a function that returns the client of a request for the rate limits: the authenticated subject
+// (e.g. the API key), or the IP of the connection. The tenant is not part of it, so a client has the same quota
+// whatever the X-Tenant-ID it sends.
+func clientKey(r *http.Request) string {
+	if p, ok := internal.PrincipalFromContext(r.Context()); ok {
+		return p.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// idempotencyKey is a function that returns the scope of the idempotency keys of a request: its client
+// within its tenant, so the same key sent for two tenants does not replay the response of the other one
+func idempotencyKey(r *http.Request) string {
+	return internal.Tenant(r.Context()) + "/" + clientKey(r)
 }
\ No newline at end of file
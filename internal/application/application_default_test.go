@@ -0,0 +1,199 @@
+package application_test
+
+import (
+	"app/internal/application"
+	"app/platform/ratelimit"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeyHash is a function that returns the SHA-256 hash (hex) of an API key, as configured
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// runServerChi is a function that runs the application with a configuration on a local port, with the vehicles
+// of the default dataset, returning its base URL. The application is stopped at the end of the test.
+func runServerChi(t *testing.T, cfg *application.ConfigServerChi) (url string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cfg.ServerListener = ln
+	cfg.LoaderFilePath = "../../docs/db/vehicles_100.json"
+	cfg.LoaderWatchInterval = -1
+	app := application.NewServerChi(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	url = "http://" + ln.Addr().String()
+	require.Eventually(t, func() bool {
+		res, err := http.Get(url + "/healthz")
+		if err != nil {
+			return false
+		}
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	return
+}
+
+// Tests for the roles of the routes of ServerChi
+func TestServerChi_Roles(t *testing.T) {
+	// an API key for each role: viewer-key, editor-key and admin-key
+	var hashes, roles []string
+	for _, role := range []string{"viewer", "editor", "admin"} {
+		hash := apiKeyHash(role + "-key")
+		hashes = append(hashes, hash)
+		roles = append(roles, "api-key:"+hash[:12]+"="+role)
+	}
+	url := runServerChi(t, &application.ConfigServerChi{
+		AuthEnabled: true,
+		AuthAPIKeys: hashes,
+		AuthRoles:   roles,
+	})
+	vehicle := `{"brand":"Ford","model":"Transit","registration":"RL-0001","color":"white","year":2020,"passengers":3,"max_speed":160,"fuel_type":"diesel","transmission":"manual","weight":2000,"height":250,"length":550,"width":200}`
+
+	cases := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "case 1: a request without credentials is not authenticated", method: http.MethodGet, path: "/vehicles", status: http.StatusUnauthorized},
+		{name: "case 2: a viewer reads the vehicles", key: "viewer-key", method: http.MethodGet, path: "/vehicles", status: http.StatusOK},
+		{name: "case 3: a viewer can not delete a vehicle", key: "viewer-key", method: http.MethodDelete, path: "/vehicles/3", status: http.StatusForbidden},
+		{name: "case 4: an editor creates a vehicle", key: "editor-key", method: http.MethodPost, path: "/vehicles", body: vehicle, status: http.StatusCreated},
+		{name: "case 5: an editor can not import a batch", key: "editor-key", method: http.MethodPost, path: "/vehicles/batch", body: `{"vehicles":[]}`, status: http.StatusForbidden},
+		{name: "case 6: an editor can not read the admin routes", key: "editor-key", method: http.MethodGet, path: "/admin/load-report", status: http.StatusForbidden},
+		{name: "case 7: an admin reads the load report", key: "admin-key", method: http.MethodGet, path: "/admin/load-report", status: http.StatusOK},
+		{name: "case 8: an admin takes a snapshot", key: "admin-key", method: http.MethodGet, path: "/admin/snapshot", status: http.StatusOK},
+		{name: "case 9: an admin deletes a vehicle", key: "admin-key", method: http.MethodDelete, path: "/vehicles/3", status: http.StatusNoContent},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			req, err := http.NewRequest(c.method, url+c.path, strings.NewReader(c.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if c.key != "" {
+				req.Header.Set("X-API-Key", c.key)
+			}
+
+			// act
+			res, err := http.DefaultClient.Do(req)
+
+			// assert
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, c.status, res.StatusCode)
+			if c.status == http.StatusForbidden {
+				require.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// get is a function that sends a GET request to the application for a tenant (the default one if empty),
+// with an API key if any, returning the status and the body of the response
+func get(t *testing.T, url string, tenant string, key string) (status int, body string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if tenant != "" {
+		req.Header.Set("X-Tenant-ID", tenant)
+	}
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(data)
+}
+
+// Tests for the tenants of ServerChi
+func TestServerChi_Tenants(t *testing.T) {
+	t.Run("case 1: a client has the same rate limit whatever the tenant it sends", func(t *testing.T) {
+		// arrange
+		url := runServerChi(t, &application.ConfigServerChi{
+			AuthEnabled:      true,
+			AuthAPIKeys:      []string{apiKeyHash("viewer-key")},
+			AuthRoles:        []string{"api-key:" + apiKeyHash("viewer-key")[:12] + "=viewer"},
+			RateLimitEnabled: true,
+			RateLimitRead:    ratelimit.Limit{Requests: 2, Period: time.Hour},
+			TenantDatasets:   []string{"acme=../../docs/db/vehicles_100.json"},
+		})
+
+		// act
+		first, _ := get(t, url+"/vehicles", "", "viewer-key")
+		second, _ := get(t, url+"/vehicles", "", "viewer-key")
+		rotated, _ := get(t, url+"/vehicles", "acme", "viewer-key")
+
+		// assert
+		require.Equal(t, http.StatusOK, first)
+		require.Equal(t, http.StatusOK, second)
+		require.Equal(t, http.StatusTooManyRequests, rotated)
+	})
+
+	t.Run("case 2: the vehicles of every tenant are measured and checked", func(t *testing.T) {
+		// arrange
+		url := runServerChi(t, &application.ConfigServerChi{
+			TenantDatasets: []string{"acme=../../docs/db/vehicles_100.json"},
+		})
+
+		// act
+		_, metrics := get(t, url+"/metrics", "", "")
+		status, readiness := get(t, url+"/readyz", "", "")
+
+		// assert
+		require.Contains(t, metrics, `vehicles_by_fuel_type{tenant="acme",fuel_type=`)
+		require.Contains(t, metrics, `vehicles_by_fuel_type{tenant="default",fuel_type=`)
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, readiness, `"acme"`)
+	})
+
+	t.Run("case 3: without authentication the tenant header is ignored, only the default tenant is served", func(t *testing.T) {
+		// arrange
+		url := runServerChi(t, &application.ConfigServerChi{
+			TenantDatasets: []string{"acme=../../docs/db/vehicles_100.json"},
+		})
+		status, _ := get(t, url+"/vehicles/3", "", "")
+		require.Equal(t, http.StatusOK, status)
+		req, err := http.NewRequest(http.MethodDelete, url+"/vehicles/3", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Tenant-ID", "acme")
+
+		// act
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		statusDefault, _ := get(t, url+"/vehicles/3", "", "")
+		statusAcme, _ := get(t, url+"/vehicles/3", "acme", "")
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.Equal(t, http.StatusNotFound, statusDefault)
+		require.Equal(t, http.StatusNotFound, statusAcme)
+	})
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// AuditCreate is the operation of a vehicle registered
+	AuditCreate = "create"
+	// AuditCreateBatch is the operation of a vehicle registered by a batch, one entry per vehicle
+	AuditCreateBatch = "create_batch"
+	// AuditUpdateSpeed is the operation of the maximum speed of a vehicle updated
+	AuditUpdateSpeed = "update_speed"
+	// AuditUpdateFuel is the operation of the fuel type of a vehicle updated
+	AuditUpdateFuel = "update_fuel"
+	// AuditDelete is the operation of a vehicle deleted
+	AuditDelete = "delete"
+	// AuditRevert is the operation of a vehicle restored to a previous version
+	AuditRevert = "revert"
+	// AuditRestore is the operation of a deleted vehicle restored from the trash
+	AuditRestore = "restore"
+	// AuditPurge is the operation of a deleted vehicle removed from the trash for good
+	AuditPurge = "purge"
+	// AuditAddTags is the operation of tags added to a vehicle
+	AuditAddTags = "add_tags"
+	// AuditRemoveTags is the operation of tags removed from a vehicle
+	AuditRemoveTags = "remove_tags"
+)
+
+// AuditAnonymous is the actor of the changes made by an anonymous request (e.g. authentication disabled)
+const AuditAnonymous = "anonymous"
+
+// Actor is a function that returns the subject of the principal of the context, AuditAnonymous if there is none
+func Actor(ctx context.Context) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+	return AuditAnonymous
+}
+
+// AuditEntry is a struct that represents a change of a vehicle recorded in the audit log
+type AuditEntry struct {
+	// Id is the sequence number of the entry, assigned when it is appended
+	Id uint64
+	// At is the time of the change
+	At time.Time
+	// Actor is the subject of the principal that made the change
+	Actor string
+	// Tenant is the tenant of the vehicle changed
+	Tenant string
+	// RequestId is the id of the request that made the change
+	RequestId string
+	// Operation is the operation of the change (e.g. update_fuel)
+	Operation string
+	// VehicleId is the id of the vehicle changed
+	VehicleId int
+	// Changes are the attributes changed, by name. From is nil for a creation and To is nil for a deletion.
+	Changes map[string]FieldChange
+}
+
+// AuditQuery is a struct that represents the filters of a search in the audit log, ignored when empty
+type AuditQuery struct {
+	// Tenant is the tenant of the vehicles changed
+	Tenant string
+	// VehicleId is the id of the vehicle changed
+	VehicleId int
+	// Actor is the subject that made the changes
+	Actor string
+	// Since is the time of the oldest change
+	Since time.Time
+}
+
+// AuditRepository is an interface that represents an append-only audit log
+type AuditRepository interface {
+	// Append is a method that appends entries to the log, assigning their ids
+	Append(ctx context.Context, entries ...AuditEntry) (err error)
+	// Find is a method that returns the entries matching the query, oldest first
+	Find(ctx context.Context, q AuditQuery) (entries []AuditEntry, err error)
+}
+
+// AuditChanges is a function that returns the changes of the attributes of a vehicle between two versions.
+// A nil before is a creation, every attribute is returned with a nil From, and a nil after is a deletion.
+func AuditChanges(before, after *VehicleAttributes) (changes map[string]FieldChange) {
+	switch {
+	case before == nil && after == nil:
+		changes = make(map[string]FieldChange)
+	case before == nil:
+		changes = make(map[string]FieldChange)
+		for name, value := range VehicleFields(*after) {
+			changes[name] = FieldChange{To: value}
+		}
+	case after == nil:
+		changes = make(map[string]FieldChange)
+		for name, value := range VehicleFields(*before) {
+			changes[name] = FieldChange{From: value}
+		}
+	default:
+		changes = VehicleChanges(*before, *after)
+	}
+	return
+}
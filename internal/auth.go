@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrNoCredentials is returned by an authenticator when the request carries no credentials of its kind
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned by an authenticator when the credentials of the request are not valid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is a struct that represents the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller (e.g. the sub claim of a token)
+	Subject string
+	// Method is the way the caller was authenticated (e.g. api_key, jwt)
+	Method string
+	// Roles are the roles granted to the caller
+	Roles []string
+	// Tenant is the tenant the caller is bound to (e.g. the tenant claim of a token), empty if it may choose one
+	Tenant string
+}
+
+// Authenticator is an interface that represents a way to authenticate the requests
+type Authenticator interface {
+	// Authenticate is a method that returns the principal of the credentials of the request,
+	// ErrNoCredentials if the request has none of its kind and ErrInvalidCredentials if they are not valid
+	Authenticate(r *http.Request) (p Principal, err error)
+
+	// Challenge is a method that returns the challenge of the WWW-Authenticate header of a rejected request
+	Challenge(realm string, err error) (challenge string)
+}
+
+// principalKey is the key of the principal in the context
+type principalKey struct{}
+
+// ContextWithPrincipal is a function that returns a copy of the context carrying the principal
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext is a function that returns the principal of the context, false if the request is anonymous
+func PrincipalFromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HeaderAPIKey is the header that carries the API key of a request
+const HeaderAPIKey = "X-API-Key"
+
+// NewAPIKeys is a function that returns a new instance of APIKeys from the SHA-256 hashes (hex) of the accepted keys
+func NewAPIKeys(hashes []string) (a *APIKeys, err error) {
+	a = &APIKeys{}
+	for _, h := range hashes {
+		var sum []byte
+		sum, err = hex.DecodeString(strings.TrimSpace(h))
+		if err != nil || len(sum) != sha256.Size {
+			err = fmt.Errorf("auth: invalid API key hash %q", h)
+			a = nil
+			return
+		}
+		a.hashes = append(a.hashes, sum)
+	}
+	return
+}
+
+// APIKeys is a struct that implements the Authenticator interface for static API keys.
+// Only the hashes of the keys are kept, so the configuration does not hold secrets.
+type APIKeys struct {
+	// hashes are the SHA-256 hashes of the accepted keys
+	hashes [][]byte
+}
+
+// Authenticate is a method that returns the principal of the API key of the X-API-Key header
+func (a *APIKeys) Authenticate(r *http.Request) (p internal.Principal, err error) {
+	key := r.Header.Get(HeaderAPIKey)
+	if key == "" {
+		err = internal.ErrNoCredentials
+		return
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	// compare with every hash in constant time, so the time does not reveal which one matched
+	match := -1
+	for ix, h := range a.hashes {
+		if subtle.ConstantTimeCompare(sum[:], h) == 1 {
+			match = ix
+		}
+	}
+	if match < 0 {
+		err = fmt.Errorf("%w: unknown API key", internal.ErrInvalidCredentials)
+		return
+	}
+
+	p = internal.Principal{
+		// the prefix of the hash identifies the key in the logs without revealing it
+		Subject: "api-key:" + hex.EncodeToString(a.hashes[match])[:12],
+		Method:  "api_key",
+	}
+	return
+}
+
+// Challenge is a method that returns the challenge of the WWW-Authenticate header
+func (a *APIKeys) Challenge(realm string, err error) (challenge string) {
+	return fmt.Sprintf(`ApiKey realm=%q, header=%q`, realm, HeaderAPIKey)
+}
@@ -0,0 +1,212 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sign is a function that returns a token with the header and the claims, signed by fn
+func sign(t *testing.T, header, claims map[string]any, fn func(signed []byte) []byte) string {
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(fn([]byte(signed)))
+}
+
+// hs256 is a function that returns a signer of HS256 tokens
+func hs256(secret []byte) func(signed []byte) []byte {
+	return func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+}
+
+// bearer is a function that returns a request with the token in the Authorization header
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// Tests for JWT
+func TestJWT_Authenticate(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKey, err := auth.ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	rs256 := func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+	claims := map[string]any{"sub": "alice", "exp": now.Add(time.Hour).Unix(), "roles": []string{"editor"}}
+
+	t.Run("case 1: a valid HS256 token authenticates its subject", func(t *testing.T) {
+		// arrange
+		a := auth.NewJWT(auth.ConfigJWT{Secret: secret, Now: func() time.Time { return now }})
+		token := sign(t, map[string]any{"alg": "HS256", "typ": "JWT"}, claims, hs256(secret))
+
+		// act
+		p, err := a.Authenticate(bearer(token))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.Principal{Subject: "alice", Method: "jwt", Roles: []string{"editor"}}, p)
+	})
+
+	t.Run("case 2: a valid RS256 token authenticates its subject", func(t *testing.T) {
+		// arrange
+		a := auth.NewJWT(auth.ConfigJWT{PublicKey: publicKey, Now: func() time.Time { return now }})
+		token := sign(t, map[string]any{"alg": "RS256"}, claims, rs256)
+
+		// act
+		p, err := a.Authenticate(bearer(token))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "alice", p.Subject)
+	})
+
+	t.Run("case 3: invalid tokens are rejected", func(t *testing.T) {
+		// arrange
+		a := auth.NewJWT(auth.ConfigJWT{PublicKey: publicKey, Now: func() time.Time { return now }})
+		expired := map[string]any{"sub": "alice", "exp": now.Add(-time.Hour).Unix()}
+		tokens := map[string]string{
+			"expired":           sign(t, map[string]any{"alg": "RS256"}, expired, rs256),
+			"without exp":       sign(t, map[string]any{"alg": "RS256"}, map[string]any{"sub": "alice"}, rs256),
+			"alg none":          sign(t, map[string]any{"alg": "none"}, claims, func([]byte) []byte { return nil }),
+			"HS256 not enabled": sign(t, map[string]any{"alg": "HS256"}, claims, hs256(der)),
+			"tampered":          sign(t, map[string]any{"alg": "RS256"}, claims, rs256)[:40] + "x",
+			"malformed":         "not-a-token",
+		}
+
+		for name, token := range tokens {
+			// act
+			_, err := a.Authenticate(bearer(token))
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrInvalidCredentials, name)
+		}
+	})
+
+	t.Run("case 4: a request without bearer token has no credentials", func(t *testing.T) {
+		// arrange
+		a := auth.NewJWT(auth.ConfigJWT{Secret: secret})
+
+		// act
+		_, err := a.Authenticate(httptest.NewRequest(http.MethodGet, "/vehicles", nil))
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrNoCredentials)
+	})
+}
+
+// Tests for APIKeys
+func TestAPIKeys_Authenticate(t *testing.T) {
+	sum := sha256.Sum256([]byte("key-1"))
+	a, err := auth.NewAPIKeys([]string{hex.EncodeToString(sum[:])})
+	require.NoError(t, err)
+
+	t.Run("case 1: a known key authenticates without revealing it", func(t *testing.T) {
+		// arrange
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set(auth.HeaderAPIKey, "key-1")
+
+		// act
+		p, err := a.Authenticate(r)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "api_key", p.Method)
+		require.Equal(t, "api-key:"+hex.EncodeToString(sum[:])[:12], p.Subject)
+	})
+
+	t.Run("case 2: an unknown key is rejected", func(t *testing.T) {
+		// arrange
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set(auth.HeaderAPIKey, "key-2")
+
+		// act
+		_, err := a.Authenticate(r)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrInvalidCredentials)
+	})
+}
+
+// Tests for Middleware
+func TestMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	sum := sha256.Sum256([]byte("key-1"))
+	ak, err := auth.NewAPIKeys([]string{hex.EncodeToString(sum[:])})
+	require.NoError(t, err)
+	mw := auth.Middleware("vehicles", auth.NewJWT(auth.ConfigJWT{Secret: secret}), ak)
+	var principal internal.Principal
+	hd := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = internal.PrincipalFromContext(r.Context())
+	}))
+
+	t.Run("case 1: a request without credentials gets 401 with every challenge", func(t *testing.T) {
+		// arrange
+		r := httptest.NewRequest(http.MethodDelete, "/vehicles/1", nil)
+
+		// act
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, r)
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, []string{`Bearer realm="vehicles"`, `ApiKey realm="vehicles", header="X-API-Key"`}, rr.Header().Values("WWW-Authenticate"))
+	})
+
+	t.Run("case 2: an invalid token gets 401 with the error of the bearer challenge", func(t *testing.T) {
+		// arrange
+		r := bearer("not-a-token")
+
+		// act
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, r)
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, []string{`Bearer realm="vehicles", error="invalid_token", error_description="malformed token"`}, rr.Header().Values("WWW-Authenticate"))
+	})
+
+	t.Run("case 3: the principal is put in the context of the request", func(t *testing.T) {
+		// arrange
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set(auth.HeaderAPIKey, "key-1")
+
+		// act
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "api_key", principal.Method)
+	})
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// AlgHS256 is the JWT algorithm HMAC with SHA-256
+	AlgHS256 = "HS256"
+	// AlgRS256 is the JWT algorithm RSASSA-PKCS1-v1_5 with SHA-256
+	AlgRS256 = "RS256"
+)
+
+// clockSkew is the tolerance applied to the time claims, so small clock differences do not reject valid tokens
+const clockSkew = 30 * time.Second
+
+// ConfigJWT is a struct that represents the configuration of JWT
+type ConfigJWT struct {
+	// Secret is the secret used to verify HS256 tokens (empty disables HS256)
+	Secret []byte
+	// PublicKey is the key used to verify RS256 tokens (nil disables RS256)
+	PublicKey *rsa.PublicKey
+	// Now returns the current time, time.Now by default
+	Now func() time.Time
+}
+
+// NewJWT is a function that returns a new instance of JWT
+func NewJWT(cfg ConfigJWT) *JWT {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &JWT{secret: cfg.Secret, publicKey: cfg.PublicKey, now: cfg.Now}
+}
+
+// JWT is a struct that implements the Authenticator interface for bearer JSON Web Tokens signed with local keys.
+// The algorithm of a token must be one with a configured key, so a token can not pick how it is verified
+// (e.g. alg none, or HS256 signed with the public RSA key).
+type JWT struct {
+	// secret is the secret used to verify HS256 tokens
+	secret []byte
+	// publicKey is the key used to verify RS256 tokens
+	publicKey *rsa.PublicKey
+	// now returns the current time
+	now func() time.Time
+}
+
+// jwtHeader is a struct that represents the header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims is a struct that represents the claims of a token used by the service
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Roles     []string `json:"roles"`
+	Tenant    string   `json:"tenant"`
+}
+
+// Authenticate is a method that returns the principal of the bearer token of the Authorization header
+func (j *JWT) Authenticate(r *http.Request) (p internal.Principal, err error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		err = internal.ErrNoCredentials
+		return
+	}
+
+	claims, err := j.verify(strings.TrimSpace(token))
+	if err != nil {
+		return
+	}
+	p = internal.Principal{
+		Subject: claims.Subject,
+		Method:  "jwt",
+		Roles:   claims.Roles,
+		Tenant:  claims.Tenant,
+	}
+	return
+}
+
+// Challenge is a method that returns the challenge of the WWW-Authenticate header (RFC 6750)
+func (j *JWT) Challenge(realm string, err error) (challenge string) {
+	challenge = fmt.Sprintf("Bearer realm=%q", realm)
+	if errors.Is(err, internal.ErrInvalidCredentials) {
+		description := strings.TrimPrefix(err.Error(), internal.ErrInvalidCredentials.Error()+": ")
+		challenge += fmt.Sprintf(`, error="invalid_token", error_description=%q`, description)
+	}
+	return
+}
+
+// verify is a method that verifies the signature and the time claims of a token and returns its claims
+func (j *JWT) verify(token string) (claims jwtClaims, err error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: "+format, append([]any{internal.ErrInvalidCredentials}, args...)...)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = invalid("malformed token")
+		return
+	}
+
+	// header
+	var header jwtHeader
+	if err = decodeSegment(parts[0], &header); err != nil {
+		err = invalid("malformed header")
+		return
+	}
+
+	// signature
+	signed := []byte(parts[0] + "." + parts[1])
+	signature, e := base64.RawURLEncoding.DecodeString(parts[2])
+	if e != nil {
+		err = invalid("malformed signature")
+		return
+	}
+	switch {
+	case header.Alg == AlgHS256 && len(j.secret) > 0:
+		mac := hmac.New(sha256.New, j.secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			err = invalid("invalid signature")
+			return
+		}
+	case header.Alg == AlgRS256 && j.publicKey != nil:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(j.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			err = invalid("invalid signature")
+			return
+		}
+	default:
+		err = invalid("unsupported algorithm %q", header.Alg)
+		return
+	}
+
+	// claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		err = invalid("malformed claims")
+		return
+	}
+	now := j.now()
+	switch {
+	case claims.Subject == "":
+		err = invalid("missing sub claim")
+	case claims.ExpiresAt == nil:
+		err = invalid("missing exp claim")
+	case now.After(time.Unix(*claims.ExpiresAt, 0).Add(clockSkew)):
+		err = invalid("token expired")
+	case claims.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*claims.NotBefore, 0)):
+		err = invalid("token not valid yet")
+	}
+	return
+}
+
+// decodeSegment is a function that decodes a base64url segment of a token as JSON
+func decodeSegment(segment string, v any) (err error) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, v)
+	return
+}
+
+// ParseRSAPublicKey is a function that parses a PEM encoded RSA public key (PKIX or PKCS #1)
+func ParseRSAPublicKey(data []byte) (key *rsa.PublicKey, err error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		err = errors.New("auth: no PEM block found")
+		return
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		var k any
+		if k, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return
+		}
+		var ok bool
+		if key, ok = k.(*rsa.PublicKey); !ok {
+			err = errors.New("auth: the public key is not an RSA key")
+		}
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("auth: unsupported PEM block %q", block.Type)
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"app/internal"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// Middleware is a function that returns a middleware that authenticates the requests with the first authenticator
+// whose credentials the request carries, and puts the principal in the context of the request.
+// Requests without valid credentials are rejected with 401 and the challenges of the WWW-Authenticate header.
+func Middleware(realm string, authenticators ...internal.Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// authenticate
+			err := internal.ErrNoCredentials
+			var failed internal.Authenticator
+			for _, a := range authenticators {
+				var p internal.Principal
+				p, err = a.Authenticate(r)
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(internal.ContextWithPrincipal(r.Context(), p)))
+					return
+				}
+				if !errors.Is(err, internal.ErrNoCredentials) {
+					failed = a
+					break
+				}
+			}
+
+			// reject
+			if failed != nil {
+				// the credentials were presented, only their scheme is challenged
+				w.Header().Add("WWW-Authenticate", failed.Challenge(realm, err))
+			} else {
+				for _, a := range authenticators {
+					w.Header().Add("WWW-Authenticate", a.Challenge(realm, err))
+				}
+			}
+			slog.InfoContext(r.Context(), "auth: request rejected", "error", err)
+			response.JSON(w, http.StatusUnauthorized, "401 Unauthorized: "+err.Error())
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// RoleViewer can read the vehicles
+	RoleViewer = "viewer"
+	// RoleEditor can also create and update vehicles
+	RoleEditor = "editor"
+	// RoleAdmin can also delete vehicles, import batches and use the admin endpoints
+	RoleAdmin = "admin"
+)
+
+// roleRanks are the ranks of the roles, each role is granted the permissions of the lower ranks
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// IsRole is a function that returns true if the role is known
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole is a function that returns true if the principal is granted the role, directly or by a higher one
+func HasRole(p internal.Principal, role string) bool {
+	required, ok := roleRanks[role]
+	if !ok {
+		return false
+	}
+	for _, r := range p.Roles {
+		if roleRanks[r] >= required {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRoleMapping is a function that parses the roles granted by the config, as subject=role entries
+// (e.g. alice=editor, api-key:1a2b3c4d5e6f=admin)
+func ParseRoleMapping(entries []string) (roles map[string][]string, err error) {
+	roles = make(map[string][]string)
+	for _, entry := range entries {
+		subject, role, ok := strings.Cut(entry, "=")
+		subject, role = strings.TrimSpace(subject), strings.TrimSpace(role)
+		if !ok || subject == "" || !IsRole(role) {
+			err = fmt.Errorf("auth: invalid role mapping %q, expected subject=viewer|editor|admin", entry)
+			roles = nil
+			return
+		}
+		roles[subject] = append(roles[subject], role)
+	}
+	return
+}
+
+// MapRoles is a function that returns a middleware that adds the roles granted by the config
+// to the roles of the principal of the request (e.g. the roles claim of a token)
+func MapRoles(roles map[string][]string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := internal.PrincipalFromContext(r.Context()); ok && len(roles[p.Subject]) > 0 {
+				p.Roles = append(append([]string(nil), p.Roles...), roles[p.Subject]...)
+				r = r.WithContext(internal.ContextWithPrincipal(r.Context(), p))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Require is a function that returns a middleware that rejects with 403 the requests whose principal
+// is not granted the role. It must run after the authentication middleware.
+func Require(role string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := internal.PrincipalFromContext(r.Context())
+			if !ok {
+				response.Problem(w, http.StatusUnauthorized, "the request is not authenticated", r.URL.Path)
+				return
+			}
+			if !HasRole(p, role) {
+				response.Problem(w, http.StatusForbidden, fmt.Sprintf("%s %s requires the role %s", r.Method, r.URL.Path, role), r.URL.Path)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// withPrincipal is a function that returns a request authenticated as the principal
+func withPrincipal(method, path string, p internal.Principal) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	return r.WithContext(internal.ContextWithPrincipal(context.Background(), p))
+}
+
+// Tests for HasRole
+func TestHasRole(t *testing.T) {
+	t.Run("case 1: a role grants the permissions of the lower roles", func(t *testing.T) {
+		// arrange
+		p := internal.Principal{Subject: "alice", Roles: []string{auth.RoleEditor}}
+
+		// act & assert
+		require.True(t, auth.HasRole(p, auth.RoleViewer))
+		require.True(t, auth.HasRole(p, auth.RoleEditor))
+		require.False(t, auth.HasRole(p, auth.RoleAdmin))
+	})
+
+	t.Run("case 2: unknown roles grant nothing", func(t *testing.T) {
+		// arrange
+		p := internal.Principal{Subject: "alice", Roles: []string{"superuser"}}
+
+		// act & assert
+		require.False(t, auth.HasRole(p, auth.RoleViewer))
+		require.False(t, auth.HasRole(p, "superuser"))
+	})
+}
+
+// Tests for ParseRoleMapping
+func TestParseRoleMapping(t *testing.T) {
+	t.Run("case 1: the entries are grouped by subject", func(t *testing.T) {
+		// act
+		roles, err := auth.ParseRoleMapping([]string{"alice=editor", " bob = viewer ", "alice=admin"})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, map[string][]string{"alice": {"editor", "admin"}, "bob": {"viewer"}}, roles)
+	})
+
+	t.Run("case 2: malformed entries and unknown roles are rejected", func(t *testing.T) {
+		for _, entry := range []string{"alice", "=editor", "alice=owner"} {
+			// act
+			roles, err := auth.ParseRoleMapping([]string{entry})
+
+			// assert
+			require.Error(t, err, entry)
+			require.Nil(t, roles)
+		}
+	})
+}
+
+// Tests for Require
+func TestRequire(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	t.Run("case 1: a principal with the role is let through", func(t *testing.T) {
+		// arrange
+		hd := auth.Require(auth.RoleEditor)(ok)
+		r := withPrincipal(http.MethodPut, "/vehicles/1/update_speed", internal.Principal{Subject: "alice", Roles: []string{auth.RoleAdmin}})
+		w := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("case 2: a principal without the role gets 403 with a problem body", func(t *testing.T) {
+		// arrange
+		hd := auth.Require(auth.RoleAdmin)(ok)
+		r := withPrincipal(http.MethodDelete, "/vehicles/1", internal.Principal{Subject: "alice", Roles: []string{auth.RoleEditor}})
+		w := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(w, r)
+
+		// assert
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		var problem map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		require.Equal(t, map[string]any{
+			"type":     "about:blank",
+			"title":    "Forbidden",
+			"status":   float64(http.StatusForbidden),
+			"detail":   "DELETE /vehicles/1 requires the role admin",
+			"instance": "/vehicles/1",
+		}, problem)
+	})
+
+	t.Run("case 3: a request without principal gets 401", func(t *testing.T) {
+		// arrange
+		hd := auth.Require(auth.RoleViewer)(ok)
+		w := httptest.NewRecorder()
+
+		// act
+		hd.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vehicles", nil))
+
+		// assert
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// Tests for MapRoles
+func TestMapRoles(t *testing.T) {
+	t.Run("case 1: the roles of the config are added to the roles of the token", func(t *testing.T) {
+		// arrange
+		var got internal.Principal
+		hd := auth.MapRoles(map[string][]string{"alice": {auth.RoleAdmin}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = internal.PrincipalFromContext(r.Context())
+		}))
+		r := withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "alice", Method: "jwt", Roles: []string{auth.RoleViewer}})
+
+		// act
+		hd.ServeHTTP(httptest.NewRecorder(), r)
+
+		// assert
+		require.Equal(t, internal.Principal{Subject: "alice", Method: "jwt", Roles: []string{auth.RoleViewer, auth.RoleAdmin}}, got)
+	})
+}
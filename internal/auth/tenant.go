@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HeaderTenant is the header that names the tenant of a request whose principal is not bound to one
+const HeaderTenant = "X-Tenant-ID"
+
+// ParseTenantMapping is a function that parses the tenants the subjects are bound to by the config,
+// as subject=tenant entries (e.g. alice=acme, api-key:1a2b3c4d5e6f=globex)
+func ParseTenantMapping(entries []string) (tenants map[string]string, err error) {
+	tenants = make(map[string]string)
+	for _, entry := range entries {
+		subject, tenant, ok := strings.Cut(entry, "=")
+		subject, tenant = strings.TrimSpace(subject), strings.TrimSpace(tenant)
+		if !ok || subject == "" || !internal.IsTenantName(tenant) {
+			err = fmt.Errorf("auth: invalid tenant mapping %q, expected subject=tenant", entry)
+			tenants = nil
+			return
+		}
+		if bound, ok := tenants[subject]; ok && bound != tenant {
+			err = fmt.Errorf("auth: the subject %q is bound to the tenants %q and %q", subject, bound, tenant)
+			tenants = nil
+			return
+		}
+		tenants[subject] = tenant
+	}
+	return
+}
+
+// ResolveTenant is a function that returns a middleware that scopes the context of the requests to a tenant:
+// the tenant of the principal (the tenant claim of a token, or else the one bound by the config), or else
+// the tenant of the X-Tenant-ID header, or else the default tenant. It must run after the authentication middleware, if any.
+// A principal bound to a tenant can not name another one (403), and the unknown tenants are rejected (404).
+// The header of a request without principal (e.g. with the authentication disabled) is ignored, so an anonymous
+// caller can only reach the default tenant.
+func ResolveTenant(tenants map[string]string, known func(tenant string) bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := strings.TrimSpace(r.Header.Get(HeaderTenant))
+
+			// resolve
+			var bound string
+			p, ok := internal.PrincipalFromContext(r.Context())
+			switch {
+			case !ok:
+				header = ""
+			case p.Tenant != "":
+				bound = p.Tenant
+			default:
+				bound = tenants[p.Subject]
+			}
+			tenant := bound
+			switch {
+			case bound != "" && header != "" && header != bound:
+				response.Problem(w, http.StatusForbidden, fmt.Sprintf("the caller is bound to another tenant than %q", header), r.URL.Path)
+				return
+			case tenant == "":
+				tenant = header
+			}
+			if tenant == "" {
+				tenant = internal.TenantDefault
+			}
+			if !known(tenant) {
+				response.Problem(w, http.StatusNotFound, fmt.Sprintf("the tenant %q is not served", tenant), r.URL.Path)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(internal.ContextWithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
+// RequireTenant is a function that returns a middleware that rejects with 403 the requests of another tenant
+// (e.g. the routes of the data source of the default tenant). It must run after ResolveTenant.
+func RequireTenant(tenant string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if internal.Tenant(r.Context()) != tenant {
+				response.Problem(w, http.StatusForbidden, fmt.Sprintf("%s %s is only available to the tenant %s", r.Method, r.URL.Path, tenant), r.URL.Path)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package auth_test
+
+import (
+	"app/internal"
+	"app/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseTenantMapping
+func TestParseTenantMapping(t *testing.T) {
+	t.Run("case 1: the entries bind the subjects to their tenant", func(t *testing.T) {
+		// act
+		tenants, err := auth.ParseTenantMapping([]string{"alice=acme", " api-key:1a2b3c4d5e6f = globex "})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"alice": "acme", "api-key:1a2b3c4d5e6f": "globex"}, tenants)
+	})
+
+	t.Run("case 2: malformed entries, invalid names and subjects bound twice are rejected", func(t *testing.T) {
+		for _, entries := range [][]string{{"alice"}, {"=acme"}, {"alice=Acme Corp"}, {"alice=acme", "alice=globex"}} {
+			// act
+			tenants, err := auth.ParseTenantMapping(entries)
+
+			// assert
+			require.Error(t, err, entries)
+			require.Nil(t, tenants)
+		}
+	})
+}
+
+// Tests for ResolveTenant
+func TestResolveTenant(t *testing.T) {
+	// arrange
+	known := func(tenant string) bool {
+		return tenant == internal.TenantDefault || tenant == "acme" || tenant == "globex"
+	}
+	serve := func(r *http.Request) (w *httptest.ResponseRecorder, tenant string) {
+		hd := auth.ResolveTenant(map[string]string{"bob": "globex"}, known)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant = internal.Tenant(r.Context())
+		}))
+		w = httptest.NewRecorder()
+		hd.ServeHTTP(w, r)
+		return
+	}
+
+	t.Run("case 1: the tenant of the principal scopes the request", func(t *testing.T) {
+		// act
+		w, tenant := serve(withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "alice", Tenant: "acme"}))
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "acme", tenant)
+	})
+
+	t.Run("case 2: the tenant bound by the config scopes the request", func(t *testing.T) {
+		// act
+		w, tenant := serve(withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "bob"}))
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "globex", tenant)
+	})
+
+	t.Run("case 3: the header names the tenant of an unbound caller, the default tenant otherwise", func(t *testing.T) {
+		// arrange
+		r := withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "carol"})
+		r.Header.Set(auth.HeaderTenant, "acme")
+
+		// act
+		w, tenant := serve(r)
+		wDefault, tenantDefault := serve(withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "carol"}))
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "acme", tenant)
+		require.Equal(t, http.StatusOK, wDefault.Code)
+		require.Equal(t, internal.TenantDefault, tenantDefault)
+	})
+
+	t.Run("case 4: a caller bound to a tenant can not name another one", func(t *testing.T) {
+		// arrange
+		r := withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "bob"})
+		r.Header.Set(auth.HeaderTenant, "acme")
+
+		// act
+		w, tenant := serve(r)
+
+		// assert
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Empty(t, tenant)
+	})
+
+	t.Run("case 5: an unknown tenant is rejected", func(t *testing.T) {
+		// arrange
+		r := withPrincipal(http.MethodGet, "/vehicles", internal.Principal{Subject: "carol"})
+		r.Header.Set(auth.HeaderTenant, "initech")
+
+		// act
+		w, tenant := serve(r)
+
+		// assert
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Empty(t, tenant)
+	})
+
+	t.Run("case 6: the header of an anonymous caller is ignored, it stays in the default tenant", func(t *testing.T) {
+		// arrange
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set(auth.HeaderTenant, "acme")
+
+		// act
+		w, tenant := serve(r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, internal.TenantDefault, tenant)
+	})
+}
@@ -0,0 +1,562 @@
+package config
+
+import (
+	"app/internal"
+	"app/platform/ratelimit"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidConfig is returned when the configuration has invalid values
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+const (
+	// SourceDefault is the source of a value that was not set
+	SourceDefault = "default"
+	// SourceFile is the source of a value set by the config file
+	SourceFile = "file"
+	// SourceEnv is the source of a value set by an environment variable
+	SourceEnv = "env"
+	// SourceFlag is the source of a value set by a command-line flag
+	SourceFlag = "flag"
+)
+
+// EnvPrefix is the prefix of the environment variables of the configuration
+const EnvPrefix = "APP_"
+
+// Server is a struct that represents the configuration of the http server
+type Server struct {
+	// Address is the address where the server will be listening
+	Address string
+	// ReadHeaderTimeout is the maximum time to read the headers of a request
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout is the maximum time to read a whole request
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum time to write a response
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum time to wait for the next request of a keep-alive connection
+	IdleTimeout time.Duration
+	// ShutdownTimeout is the maximum time to drain the requests in flight when the server stops
+	ShutdownTimeout time.Duration
+}
+
+// Loader is a struct that represents the configuration of the data source of the vehicles
+type Loader struct {
+	// FilePath is the path to the file that contains the vehicles
+	FilePath string
+	// Sources are the paths or globs of several files to merge instead of FilePath
+	Sources []string
+	// Mode is the mode used to load the vehicles (strict, lenient)
+	Mode string
+	// ConflictPolicy is the policy used to merge duplicated ids or registrations (first-wins, last-wins, fail)
+	ConflictPolicy string
+	// WatchInterval is the time between two polls of the data source (0 disables the watcher)
+	WatchInterval time.Duration
+}
+
+// Log is a struct that represents the configuration of the logs
+type Log struct {
+	// Level is the minimum level of the logs (debug, info, warn, error)
+	Level string
+	// Format is the format of the logs (text, json)
+	Format string
+}
+
+// Tracing is a struct that represents the configuration of the tracing
+type Tracing struct {
+	// Exporter is the destination of the spans (none, stdout, file)
+	Exporter string
+	// File is the file where the spans are appended in file mode
+	File string
+}
+
+// Auth is a struct that represents the configuration of the authentication
+type Auth struct {
+	// Enabled requires the requests to be authenticated
+	Enabled bool
+	// APIKeys are the SHA-256 hashes (hex) of the accepted API keys
+	APIKeys []string
+	// JWTSecret is the secret used to verify HS256 tokens
+	JWTSecret string
+	// JWTPublicKeyFile is the path to the PEM public key used to verify RS256 tokens
+	JWTPublicKeyFile string
+	// Roles are the roles granted to the subjects, as subject=role entries (e.g. alice=editor)
+	Roles []string
+	// Tenants are the tenants the subjects are bound to, as subject=tenant entries (e.g. alice=acme)
+	Tenants []string
+}
+
+// RateLimit is a struct that represents the configuration of the rate limits of the clients
+type RateLimit struct {
+	// Enabled limits the requests of each client
+	Enabled bool
+	// Read is the limit of the read requests
+	Read ratelimit.Limit
+	// Write is the limit of the write requests
+	Write ratelimit.Limit
+	// Batch is the limit of the batch imports
+	Batch ratelimit.Limit
+}
+
+// Idempotency is a struct that represents the configuration of the idempotent requests
+type Idempotency struct {
+	// TTL is the time the responses of the requests with an Idempotency-Key are kept to be replayed
+	TTL time.Duration
+}
+
+// Events is a struct that represents the configuration of the streams of the changes of the vehicles
+type Events struct {
+	// Heartbeat is the time between two heartbeats of an idle stream
+	Heartbeat time.Duration
+	// ReplaySize is the number of the last events of each tenant kept to resume the streams
+	ReplaySize int
+	// WSQueueSize is the number of messages queued for a WebSocket client before it is disconnected
+	WSQueueSize int
+	// WSPingInterval is the time between two pings of a WebSocket client
+	WSPingInterval time.Duration
+}
+
+// Webhooks is a struct that represents the configuration of the deliveries to the webhooks
+type Webhooks struct {
+	// MaxAttempts is the number of attempts before a delivery becomes a dead letter
+	MaxAttempts int
+	// Backoff is the time before the first retry, doubled on each retry
+	Backoff time.Duration
+	// MaxBackoff is the maximum time between two attempts
+	MaxBackoff time.Duration
+	// Timeout is the maximum time of an attempt
+	Timeout time.Duration
+	// Workers is the number of deliveries attempted at the same time
+	Workers int
+	// MaxDeliveries is the number of deliveries kept in the log of each subscription
+	MaxDeliveries int
+}
+
+// Audit is a struct that represents the configuration of the audit log of the changes of the vehicles
+type Audit struct {
+	// Path is the file the entries are appended to (empty to keep them in memory only)
+	Path string
+}
+
+// Trash is a struct that represents the configuration of the trash of the deleted vehicles
+type Trash struct {
+	// Retention is the time the deleted vehicles are kept before they are purged (0 keeps them until an admin purges them)
+	Retention time.Duration
+}
+
+// Fleets is a struct that represents the configuration of the fleets of vehicles
+type Fleets struct {
+	// OrphanPolicy is what happens to the vehicles and the sub-fleets of a deleted fleet (unassign, parent, restrict)
+	OrphanPolicy string
+}
+
+// Maintenance is a struct that represents the configuration of the maintenance records of the vehicles
+type Maintenance struct {
+	// CascadePolicy is what happens to the maintenance records of a deleted vehicle (keep, delete, restrict)
+	CascadePolicy string
+}
+
+// Tenants is a struct that represents the configuration of the tenants served besides the default one
+type Tenants struct {
+	// Datasets are the tenants and the files of their vehicles loaded at startup, as tenant=path entries
+	// (e.g. acme=docs/db/acme.json), an empty path for a tenant that starts without vehicles
+	Datasets []string
+}
+
+// Storage is a struct that represents the configuration of the persistence of the vehicles
+type Storage struct {
+	// Mode is the storage mode (memory, file)
+	Mode string
+	// Path is the file where the vehicles are persisted in file mode
+	Path string
+	// FlushInterval is the time between two flushes of the changes in file mode
+	FlushInterval time.Duration
+}
+
+// Config is a struct that represents the configuration of the application
+type Config struct {
+	Server      Server
+	Loader      Loader
+	Log         Log
+	Tracing     Tracing
+	Auth        Auth
+	RateLimit   RateLimit
+	Idempotency Idempotency
+	Events      Events
+	Webhooks    Webhooks
+	Audit       Audit
+	Trash       Trash
+	Fleets      Fleets
+	Maintenance Maintenance
+	Tenants     Tenants
+	Storage     Storage
+
+	// PrintConfig is true when the effective configuration must be printed instead of running the application
+	PrintConfig bool
+	// sources is the layer that set each setting
+	sources map[string]string
+}
+
+// Default is a function that returns the default configuration
+func Default() (c Config) {
+	c = Config{
+		Server: Server{
+			Address:           ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Loader: Loader{
+			FilePath:       "docs/db/vehicles_100.json",
+			Mode:           internal.LoadModeLenient,
+			ConflictPolicy: internal.ConflictFirstWins,
+			WatchInterval:  5 * time.Second,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: Tracing{
+			Exporter: "none",
+		},
+		// - the authentication is on unless it is disabled explicitly, so it needs credentials to start
+		Auth: Auth{
+			Enabled: true,
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			Read:    ratelimit.Limit{Requests: 600, Period: time.Minute},
+			Write:   ratelimit.Limit{Requests: 120, Period: time.Minute},
+			Batch:   ratelimit.Limit{Requests: 10, Period: time.Minute},
+		},
+		Idempotency: Idempotency{
+			TTL: 24 * time.Hour,
+		},
+		Events: Events{
+			Heartbeat:      15 * time.Second,
+			ReplaySize:     1000,
+			WSQueueSize:    64,
+			WSPingInterval: 30 * time.Second,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:   5,
+			Backoff:       time.Second,
+			MaxBackoff:    5 * time.Minute,
+			Timeout:       10 * time.Second,
+			Workers:       4,
+			MaxDeliveries: 100,
+		},
+		Trash: Trash{
+			Retention: 30 * 24 * time.Hour,
+		},
+		Fleets: Fleets{
+			OrphanPolicy: internal.FleetOrphanUnassign,
+		},
+		Maintenance: Maintenance{
+			CascadePolicy: internal.MaintenanceCascadeKeep,
+		},
+		Storage: Storage{
+			Mode:          internal.StorageModeMemory,
+			FlushInterval: 10 * time.Second,
+		},
+		sources: make(map[string]string),
+	}
+	return
+}
+
+// Load is a function that returns the configuration layering, in order of precedence,
+// the defaults, a config file, the environment variables and the command-line flags.
+// The config file is set by the flag -config or the environment variable APP_CONFIG.
+func Load(args []string, lookupEnv func(key string) (string, bool)) (c Config, err error) {
+	c = Default()
+
+	// flags
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a JSON config file (env "+EnvPrefix+"CONFIG)")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the effective configuration and exit")
+	flagValues := make(map[string]*string)
+	for _, s := range settings {
+		flagValues[s.key] = fs.String(s.flag(), "", s.usage+" (env "+s.env()+")")
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	flagsSet := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { flagsSet[f.Name] = true })
+
+	// config file
+	path := *configFile
+	if !flagsSet["config"] {
+		path, _ = lookupEnv(EnvPrefix + "CONFIG")
+	}
+	if path != "" {
+		var values map[string]string
+		if values, err = readFile(path); err != nil {
+			err = fmt.Errorf("%w: config file %s: %v", ErrInvalidConfig, path, err)
+			return
+		}
+		if err = c.apply(values, SourceFile); err != nil {
+			return
+		}
+	}
+
+	// environment variables
+	values := make(map[string]string)
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env()); ok {
+			values[s.key] = value
+		}
+	}
+	if err = c.apply(values, SourceEnv); err != nil {
+		return
+	}
+
+	// flags
+	values = make(map[string]string)
+	for _, s := range settings {
+		if flagsSet[s.flag()] {
+			values[s.key] = *flagValues[s.key]
+		}
+	}
+	if err = c.apply(values, SourceFlag); err != nil {
+		return
+	}
+
+	err = c.Validate()
+	return
+}
+
+// apply is a method that sets the values of a layer
+func (c *Config) apply(values map[string]string, source string) (err error) {
+	var errs []error
+	for _, s := range settings {
+		value, ok := values[s.key]
+		if !ok {
+			continue
+		}
+		if e := s.set(c, strings.TrimSpace(value)); e != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %v", s.key, source, e))
+			continue
+		}
+		c.sources[s.key] = source
+	}
+	// - unknown settings are reported, so typos in the config file are not silently ignored
+	unknown := make([]string, 0)
+	for key := range values {
+		if _, ok := settingByKey(key); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("%s (%s): unknown setting", key, source))
+	}
+	if len(errs) > 0 {
+		err = fmt.Errorf("%w:\n%w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return
+}
+
+// Validate is a method that validates the values of the configuration
+func (c *Config) Validate() (err error) {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+		}
+	}
+	oneOf := func(value string, options ...string) bool {
+		for _, option := range options {
+			if value == option {
+				return true
+			}
+		}
+		return false
+	}
+
+	// server
+	_, port, e := net.SplitHostPort(c.Server.Address)
+	check(e == nil, "server.address", "must be host:port, got %q", c.Server.Address)
+	if e == nil {
+		_, e = strconv.ParseUint(port, 10, 16)
+		check(e == nil, "server.address", "invalid port %q", port)
+	}
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout", "must be positive")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+
+	// loader
+	check(c.Loader.FilePath != "" || len(c.Loader.Sources) > 0, "loader.file_path", "a data source is required (loader.file_path or loader.sources)")
+	check(oneOf(c.Loader.Mode, internal.LoadModeStrict, internal.LoadModeLenient), "loader.mode", "must be strict or lenient, got %q", c.Loader.Mode)
+	check(oneOf(c.Loader.ConflictPolicy, internal.ConflictFirstWins, internal.ConflictLastWins, internal.ConflictFail), "loader.conflict_policy", "must be first-wins, last-wins or fail, got %q", c.Loader.ConflictPolicy)
+	check(c.Loader.WatchInterval >= 0, "loader.watch_interval", "must not be negative")
+
+	// log
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	check(oneOf(c.Log.Format, "text", "json"), "log.format", "must be text or json, got %q", c.Log.Format)
+
+	// tracing
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "file"), "tracing.exporter", "must be none, stdout or file, got %q", c.Tracing.Exporter)
+	if c.Tracing.Exporter == "file" {
+		check(c.Tracing.File != "", "tracing.file", "is required by the file exporter")
+	}
+
+	// auth
+	for _, hash := range c.Auth.APIKeys {
+		_, e := hex.DecodeString(hash)
+		check(e == nil && len(hash) == 64, "auth.api_keys", "must be SHA-256 hashes in hex, got %q", hash)
+	}
+	if c.Auth.Enabled {
+		check(len(c.Auth.APIKeys) > 0 || c.Auth.JWTSecret != "" || c.Auth.JWTPublicKeyFile != "", "auth.enabled", "requires auth.api_keys, auth.jwt_secret or auth.jwt_public_key_file, or must be set to false to serve without authentication")
+	}
+	for _, entry := range c.Auth.Roles {
+		subject, role, ok := strings.Cut(entry, "=")
+		check(ok && subject != "" && oneOf(role, "viewer", "editor", "admin"), "auth.roles", "must be subject=viewer|editor|admin entries, got %q", entry)
+	}
+	if c.Auth.JWTPublicKeyFile != "" {
+		_, e := os.Stat(c.Auth.JWTPublicKeyFile)
+		check(e == nil, "auth.jwt_public_key_file", "%v", e)
+	}
+
+	// tenants
+	tenants := map[string]bool{internal.TenantDefault: true}
+	for _, entry := range c.Tenants.Datasets {
+		tenant, path, ok := strings.Cut(entry, "=")
+		check(ok && internal.IsTenantName(tenant), "tenants.datasets", "must be tenant=path entries with lowercase tenant names, got %q", entry)
+		check(!tenants[tenant], "tenants.datasets", "declares the tenant %q twice", tenant)
+		tenants[tenant] = true
+		if path != "" {
+			_, e := os.Stat(path)
+			check(e == nil, "tenants.datasets", "%v", e)
+		}
+	}
+	for _, entry := range c.Auth.Tenants {
+		subject, tenant, ok := strings.Cut(entry, "=")
+		check(ok && subject != "" && tenants[tenant], "auth.tenants", "must be subject=tenant entries of the default tenant or one of tenants.datasets, got %q", entry)
+	}
+
+	// idempotency
+	check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+
+	// events
+	check(c.Events.Heartbeat > 0, "events.heartbeat", "must be positive")
+	check(c.Events.ReplaySize > 0, "events.replay_size", "must be positive")
+	check(c.Events.WSQueueSize > 0, "events.ws_queue_size", "must be positive")
+	check(c.Events.WSPingInterval > 0, "events.ws_ping_interval", "must be positive")
+
+	// webhooks
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts", "must be positive")
+	check(c.Webhooks.Backoff > 0, "webhooks.backoff", "must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.Backoff, "webhooks.max_backoff", "must not be less than webhooks.backoff")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout", "must be positive")
+	check(c.Webhooks.Workers > 0, "webhooks.workers", "must be positive")
+	check(c.Webhooks.MaxDeliveries > 0, "webhooks.max_deliveries", "must be positive")
+
+	// trash
+	check(c.Trash.Retention >= 0, "trash.retention", "must not be negative")
+
+	// fleets
+	check(oneOf(c.Fleets.OrphanPolicy, internal.FleetOrphanUnassign, internal.FleetOrphanParent, internal.FleetOrphanRestrict), "fleets.orphan_policy", "must be unassign, parent or restrict, got %q", c.Fleets.OrphanPolicy)
+
+	// maintenance
+	check(oneOf(c.Maintenance.CascadePolicy, internal.MaintenanceCascadeKeep, internal.MaintenanceCascadeDelete, internal.MaintenanceCascadeRestrict), "maintenance.cascade_policy", "must be keep, delete or restrict, got %q", c.Maintenance.CascadePolicy)
+
+	// storage
+	check(oneOf(c.Storage.Mode, internal.StorageModeMemory, internal.StorageModeFile), "storage.mode", "must be memory or file, got %q", c.Storage.Mode)
+	if c.Storage.Mode == internal.StorageModeFile {
+		check(c.Storage.Path != "", "storage.path", "is required in file mode")
+		check(c.Storage.FlushInterval > 0, "storage.flush_interval", "must be positive")
+	}
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("%w:\n%w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return
+}
+
+// Print is a method that writes the effective configuration, one setting per line with the layer that set it.
+// Secrets are masked.
+func (c *Config) Print(w io.Writer) (err error) {
+	keys := make([]string, len(settings))
+	for ix, s := range settings {
+		keys[ix] = s.key
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s, _ := settingByKey(key)
+		value := s.get(c)
+		if s.secret && value != "" {
+			value = "********"
+		}
+		source := c.sources[key]
+		if source == "" {
+			source = SourceDefault
+		}
+		if _, err = fmt.Fprintf(w, "%-28s = %-30q # %s\n", key, value, source); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// readFile is a function that reads a JSON config file and flattens its values by setting key
+// (e.g. {"server":{"address":":8080"}} is the value ":8080" of "server.address")
+func readFile(path string) (values map[string]string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	// - numbers are kept as written, a float64 would print 1000000 as 1e+06
+	var document map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&document); err != nil {
+		return
+	}
+
+	values = make(map[string]string)
+	var flatten func(prefix string, value any)
+	flatten = func(prefix string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				flatten(key, child)
+			}
+		case []any:
+			items := make([]string, len(v))
+			for ix, item := range v {
+				items[ix] = fmt.Sprint(item)
+			}
+			values[prefix] = strings.Join(items, ",")
+		case json.Number:
+			values[prefix] = v.String()
+		case nil:
+			values[prefix] = ""
+		default:
+			values[prefix] = fmt.Sprint(v)
+		}
+	}
+	flatten("", document)
+	return
+}
@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"app/internal/config"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// envOf is a function that returns a lookup of environment variables backed by a map
+func envOf(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (value string, ok bool) {
+		value, ok = env[key]
+		return
+	}
+}
+
+// Tests for Load
+func TestLoad(t *testing.T) {
+	t.Run("case 1: without layers the defaults are used, besides the credentials of the authentication", func(t *testing.T) {
+		// arrange
+		env := map[string]string{"APP_AUTH_JWT_SECRET": "top-secret"}
+
+		// act
+		cfg, err := config.Load(nil, envOf(env))
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, cfg.Auth.Enabled)
+		require.Equal(t, ":8080", cfg.Server.Address)
+		require.Equal(t, "docs/db/vehicles_100.json", cfg.Loader.FilePath)
+		require.Equal(t, "memory", cfg.Storage.Mode)
+	})
+
+	t.Run("case 2: flags take precedence over env, and env over the config file", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "config.json")
+		file := `{"server":{"address":":7000","read_timeout":"20s"},"loader":{"mode":"strict","sources":["a.json","b.csv"]},"log":{"level":"debug"}}`
+		require.NoError(t, os.WriteFile(path, []byte(file), 0o644))
+		env := map[string]string{"APP_CONFIG": path, "APP_SERVER_ADDRESS": ":7001", "APP_LOG_LEVEL": "warn", "APP_AUTH_ENABLED": "false"}
+		args := []string{"--server-address", ":7002"}
+
+		// act
+		cfg, err := config.Load(args, envOf(env))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, ":7002", cfg.Server.Address)
+		require.Equal(t, 20*time.Second, cfg.Server.ReadTimeout)
+		require.Equal(t, "strict", cfg.Loader.Mode)
+		require.Equal(t, []string{"a.json", "b.csv"}, cfg.Loader.Sources)
+		require.Equal(t, "warn", cfg.Log.Level)
+	})
+
+	t.Run("case 3: invalid values are reported by setting", func(t *testing.T) {
+		// arrange
+		env := map[string]string{"APP_LOADER_MODE": "sloppy", "APP_STORAGE_MODE": "file"}
+
+		// act
+		_, err := config.Load([]string{"--server-read-timeout", "soon"}, envOf(env))
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalidConfig)
+		require.Contains(t, err.Error(), "server.read_timeout (flag)")
+	})
+
+	t.Run("case 4: the config is validated after every layer", func(t *testing.T) {
+		// arrange
+		env := map[string]string{"APP_LOADER_MODE": "sloppy", "APP_STORAGE_MODE": "file", "APP_AUTH_ROLES": "alice=owner", "APP_AUTH_TENANTS": "bob=initech"}
+
+		// act
+		_, err := config.Load(nil, envOf(env))
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalidConfig)
+		require.Contains(t, err.Error(), `loader.mode: must be strict or lenient, got "sloppy"`)
+		require.Contains(t, err.Error(), "storage.path: is required in file mode")
+		require.Contains(t, err.Error(), `auth.roles: must be subject=viewer|editor|admin entries, got "alice=owner"`)
+		require.Contains(t, err.Error(), `auth.tenants: must be subject=tenant entries of the default tenant or one of tenants.datasets, got "bob=initech"`)
+	})
+
+	t.Run("case 5: unknown settings of the config file are reported", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"server":{"adress":":7000"}}`), 0o644))
+
+		// act
+		_, err := config.Load([]string{"--config", path}, envOf(nil))
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalidConfig)
+		require.Contains(t, err.Error(), "server.adress (file): unknown setting")
+	})
+
+	t.Run("case 6: large integers of the config file are read as written", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"auth":{"enabled":false},"events":{"replay_size":1000000},"webhooks":{"max_deliveries":25000000}}`), 0o644))
+
+		// act
+		cfg, err := config.Load([]string{"--config", path}, envOf(nil))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1000000, cfg.Events.ReplaySize)
+		require.Equal(t, 25000000, cfg.Webhooks.MaxDeliveries)
+	})
+
+	t.Run("case 7: the authentication is on by default, it needs credentials or to be disabled explicitly", func(t *testing.T) {
+		// act
+		_, err := config.Load(nil, envOf(nil))
+		cfg, errDisabled := config.Load([]string{"--auth-enabled=false"}, envOf(nil))
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalidConfig)
+		require.Contains(t, err.Error(), "auth.enabled: requires auth.api_keys")
+		require.NoError(t, errDisabled)
+		require.False(t, cfg.Auth.Enabled)
+	})
+}
+
+// Tests for Config.Print
+func TestConfig_Print(t *testing.T) {
+	t.Run("case 1: the values are printed with their source and the secrets masked", func(t *testing.T) {
+		// arrange
+		env := map[string]string{"APP_AUTH_JWT_SECRET": "top-secret"}
+		cfg, err := config.Load([]string{"--print-config", "--log-format", "json"}, envOf(env))
+		require.NoError(t, err)
+		require.True(t, cfg.PrintConfig)
+
+		// act
+		var buf bytes.Buffer
+		err = cfg.Print(&buf)
+
+		// assert
+		require.NoError(t, err)
+		out := buf.String()
+		require.NotContains(t, out, "top-secret")
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			switch {
+			case strings.HasPrefix(line, "auth.jwt_secret "):
+				require.Contains(t, line, `"********"`)
+				require.True(t, strings.HasSuffix(line, "# env"))
+			case strings.HasPrefix(line, "log.format "):
+				require.True(t, strings.HasSuffix(line, "# flag"))
+			case strings.HasPrefix(line, "server.address "):
+				require.True(t, strings.HasSuffix(line, "# default"))
+			}
+		}
+	})
+}
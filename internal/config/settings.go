@@ -0,0 +1,191 @@
+package config
+
+import (
+	"app/platform/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is a struct that represents a configurable value, which can be set by every layer
+type setting struct {
+	// key is the name of the setting in the config file (e.g. server.address)
+	key string
+	// usage is the description of the setting
+	usage string
+	// secret is true when the value must be masked when printed
+	secret bool
+	// set is the function that parses the value and sets it in the configuration
+	set func(c *Config, value string) (err error)
+	// get is the function that returns the value of the configuration
+	get func(c *Config) (value string)
+}
+
+// env is a method that returns the name of the environment variable of the setting (e.g. APP_SERVER_ADDRESS)
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// flag is a method that returns the name of the command-line flag of the setting (e.g. server-address)
+func (s setting) flag() string {
+	return strings.ReplaceAll(strings.ReplaceAll(s.key, ".", "-"), "_", "-")
+}
+
+// settingByKey is a function that returns the setting with the given key
+func settingByKey(key string) (s setting, ok bool) {
+	for _, s = range settings {
+		if s.key == key {
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// stringSetting is a function that returns a setting of a string value
+func stringSetting(key, usage string, secret bool, field func(c *Config) *string) setting {
+	return setting{
+		key:    key,
+		usage:  usage,
+		secret: secret,
+		set: func(c *Config, value string) (err error) {
+			*field(c) = value
+			return
+		},
+		get: func(c *Config) string { return *field(c) },
+	}
+}
+
+// listSetting is a function that returns a setting of a comma-separated list of values
+func listSetting(key, usage string, field func(c *Config) *[]string) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) (err error) {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*field(c) = items
+			return
+		},
+		get: func(c *Config) string { return strings.Join(*field(c), ",") },
+	}
+}
+
+// durationSetting is a function that returns a setting of a duration value (e.g. 5s, 1m30s)
+func durationSetting(key, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) (err error) {
+			*field(c), err = time.ParseDuration(value)
+			return
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// intSetting is a function that returns a setting of an integer value
+func intSetting(key, usage string, field func(c *Config) *int) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) (err error) {
+			*field(c), err = strconv.Atoi(value)
+			return
+		},
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+	}
+}
+
+// boolSetting is a function that returns a setting of a boolean value
+func boolSetting(key, usage string, field func(c *Config) *bool) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) (err error) {
+			*field(c), err = strconv.ParseBool(value)
+			return
+		},
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+	}
+}
+
+// limitSetting is a function that returns a setting of a rate limit (e.g. 100/1m)
+func limitSetting(key, usage string, field func(c *Config) *ratelimit.Limit) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) (err error) {
+			*field(c), err = ratelimit.ParseLimit(value)
+			return
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// settings are the configurable values of the application
+var settings = []setting{
+	// server
+	stringSetting("server.address", "address where the server will be listening", false, func(c *Config) *string { return &c.Server.Address }),
+	durationSetting("server.read_header_timeout", "maximum time to read the headers of a request", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("server.read_timeout", "maximum time to read a whole request", func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationSetting("server.write_timeout", "maximum time to write a response", func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationSetting("server.idle_timeout", "maximum time to wait for the next request of a keep-alive connection", func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	durationSetting("server.shutdown_timeout", "maximum time to drain the requests in flight when the server stops", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	// loader
+	stringSetting("loader.file_path", "path to the file that contains the vehicles", false, func(c *Config) *string { return &c.Loader.FilePath }),
+	listSetting("loader.sources", "comma-separated paths or globs of several files to merge instead of loader.file_path", func(c *Config) *[]string { return &c.Loader.Sources }),
+	stringSetting("loader.mode", "load mode: strict or lenient", false, func(c *Config) *string { return &c.Loader.Mode }),
+	stringSetting("loader.conflict_policy", "policy for duplicated ids or registrations: first-wins, last-wins or fail", false, func(c *Config) *string { return &c.Loader.ConflictPolicy }),
+	durationSetting("loader.watch_interval", "time between two polls of the data source, 0 disables the watcher", func(c *Config) *time.Duration { return &c.Loader.WatchInterval }),
+	// log
+	stringSetting("log.level", "minimum level of the logs: debug, info, warn or error", false, func(c *Config) *string { return &c.Log.Level }),
+	stringSetting("log.format", "format of the logs: text or json", false, func(c *Config) *string { return &c.Log.Format }),
+	// tracing
+	stringSetting("tracing.exporter", "destination of the spans: none, stdout or file", false, func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringSetting("tracing.file", "file where the spans are appended by the file exporter", false, func(c *Config) *string { return &c.Tracing.File }),
+	// auth
+	boolSetting("auth.enabled", "require the requests to be authenticated, false only serves the default tenant anonymously (development)", func(c *Config) *bool { return &c.Auth.Enabled }),
+	listSetting("auth.api_keys", "comma-separated SHA-256 hashes (hex) of the accepted API keys", func(c *Config) *[]string { return &c.Auth.APIKeys }),
+	stringSetting("auth.jwt_secret", "secret used to verify HS256 tokens", true, func(c *Config) *string { return &c.Auth.JWTSecret }),
+	listSetting("auth.roles", "comma-separated roles granted to the subjects, as subject=role (viewer, editor or admin)", func(c *Config) *[]string { return &c.Auth.Roles }),
+	stringSetting("auth.jwt_public_key_file", "path to the PEM public key used to verify RS256 tokens", false, func(c *Config) *string { return &c.Auth.JWTPublicKeyFile }),
+	listSetting("auth.tenants", "comma-separated tenants the subjects are bound to, as subject=tenant (the others choose it with X-Tenant-ID)", func(c *Config) *[]string { return &c.Auth.Tenants }),
+	// rate limit
+	boolSetting("ratelimit.enabled", "limit the requests of each client (API key, token subject or IP) to /vehicles and /admin", func(c *Config) *bool { return &c.RateLimit.Enabled }),
+	limitSetting("ratelimit.read", "limit of the read requests of a client, as requests/period", func(c *Config) *ratelimit.Limit { return &c.RateLimit.Read }),
+	limitSetting("ratelimit.write", "limit of the write requests of a client, as requests/period", func(c *Config) *ratelimit.Limit { return &c.RateLimit.Write }),
+	limitSetting("ratelimit.batch", "limit of the batch imports of a client, as requests/period", func(c *Config) *ratelimit.Limit { return &c.RateLimit.Batch }),
+	// idempotency
+	durationSetting("idempotency.ttl", "time the responses of the requests with an Idempotency-Key are kept to be replayed", func(c *Config) *time.Duration { return &c.Idempotency.TTL }),
+	// events
+	durationSetting("events.heartbeat", "time between two heartbeats of an idle event stream", func(c *Config) *time.Duration { return &c.Events.Heartbeat }),
+	intSetting("events.replay_size", "number of the last events of each tenant kept to resume the streams with Last-Event-ID", func(c *Config) *int { return &c.Events.ReplaySize }),
+	intSetting("events.ws_queue_size", "number of messages queued for a WebSocket client before it is disconnected as a slow consumer", func(c *Config) *int { return &c.Events.WSQueueSize }),
+	durationSetting("events.ws_ping_interval", "time between two pings of a WebSocket client, which is disconnected after two unanswered", func(c *Config) *time.Duration { return &c.Events.WSPingInterval }),
+	// webhooks
+	intSetting("webhooks.max_attempts", "number of attempts of a webhook delivery before it becomes a dead letter", func(c *Config) *int { return &c.Webhooks.MaxAttempts }),
+	durationSetting("webhooks.backoff", "time before the first retry of a webhook delivery, doubled on each retry", func(c *Config) *time.Duration { return &c.Webhooks.Backoff }),
+	durationSetting("webhooks.max_backoff", "maximum time between two attempts of a webhook delivery", func(c *Config) *time.Duration { return &c.Webhooks.MaxBackoff }),
+	durationSetting("webhooks.timeout", "maximum time of an attempt of a webhook delivery", func(c *Config) *time.Duration { return &c.Webhooks.Timeout }),
+	intSetting("webhooks.workers", "number of webhook deliveries attempted at the same time", func(c *Config) *int { return &c.Webhooks.Workers }),
+	intSetting("webhooks.max_deliveries", "number of deliveries kept in the log of each webhook", func(c *Config) *int { return &c.Webhooks.MaxDeliveries }),
+	// audit
+	stringSetting("audit.path", "file where the audit log of the changes is appended, empty to keep it in memory only", false, func(c *Config) *string { return &c.Audit.Path }),
+	// trash
+	durationSetting("trash.retention", "time the deleted vehicles are kept in the trash before they are purged, 0 keeps them until an admin purges them", func(c *Config) *time.Duration { return &c.Trash.Retention }),
+	// fleets
+	stringSetting("fleets.orphan_policy", "what happens to the vehicles and the sub-fleets of a deleted fleet: unassign, parent (moved to its parent) or restrict (only empty fleets are deleted)", false, func(c *Config) *string { return &c.Fleets.OrphanPolicy }),
+	// maintenance
+	stringSetting("maintenance.cascade_policy", "what happens to the maintenance records of a deleted vehicle: keep (until it is purged from the trash), delete or restrict (only vehicles without records are deleted)", false, func(c *Config) *string { return &c.Maintenance.CascadePolicy }),
+	// tenants
+	listSetting("tenants.datasets", "comma-separated tenants served besides the default one and the files of their vehicles, as tenant=path (empty path to start without vehicles)", func(c *Config) *[]string { return &c.Tenants.Datasets }),
+	// storage
+	stringSetting("storage.mode", "storage mode: memory or file", false, func(c *Config) *string { return &c.Storage.Mode }),
+	stringSetting("storage.path", "file where the vehicles are persisted in file mode", false, func(c *Config) *string { return &c.Storage.Path }),
+	durationSetting("storage.flush_interval", "time between two flushes of the changes in file mode", func(c *Config) *time.Duration { return &c.Storage.FlushInterval }),
+}
@@ -0,0 +1,193 @@
+package event
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+	"time"
+)
+
+// NewVehicleBus is a function that returns a new instance of VehicleBus
+func NewVehicleBus(replaySize int, subscriberSize int) *VehicleBus {
+	// default sizes
+	if replaySize <= 0 {
+		replaySize = 1000
+	}
+	if subscriberSize <= 0 {
+		subscriberSize = 64
+	}
+	return &VehicleBus{
+		replays:        make(map[string]*vehicleReplay),
+		replaySize:     replaySize,
+		subscriberSize: subscriberSize,
+		subscriptions:  make(map[*VehicleBusSubscription]struct{}),
+		now:            time.Now,
+	}
+}
+
+// VehicleBus is a struct that delivers the changes of the vehicles to the subscribers of their tenant in process.
+// The last events of each tenant are kept in a bounded buffer, so a subscriber can resume after a disconnection
+// however busy the other tenants are. A subscriber that does not keep up is disconnected instead of slowing down the publishers.
+type VehicleBus struct {
+	// mu protects the state of the bus, and orders the events
+	mu sync.Mutex
+	// seq is the id of the last event published
+	seq uint64
+	// replays are the last events published by tenant
+	replays map[string]*vehicleReplay
+	// replaySize is the maximum number of events of each tenant kept to be replayed
+	replaySize int
+	// subscriberSize is the number of events buffered for each subscriber
+	subscriberSize int
+	// subscriptions are the current subscriptions
+	subscriptions map[*VehicleBusSubscription]struct{}
+	// now is the clock of the bus
+	now func() time.Time
+	// closed is true once the bus is closed
+	closed bool
+}
+
+// vehicleReplay is a struct that represents the last events published to a tenant
+type vehicleReplay struct {
+	// events are the last events, oldest first
+	events []internal.VehicleEvent
+	// evicted is the id of the last event dropped from the buffer, 0 if none
+	evicted uint64
+}
+
+// Publish is a method that assigns an id, and the tenant of the context if they have none, to the events
+// and delivers them to the subscribers of their tenant
+func (b *VehicleBus) Publish(ctx context.Context, events ...internal.VehicleEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ev := range events {
+		b.seq++
+		ev.Id = b.seq
+		ev.OccurredAt = b.now()
+		if ev.Tenant == "" {
+			ev.Tenant = internal.Tenant(ctx)
+		}
+
+		// - replay buffer of the tenant
+		buffer, ok := b.replays[ev.Tenant]
+		if !ok {
+			buffer = &vehicleReplay{}
+			b.replays[ev.Tenant] = buffer
+		}
+		if len(buffer.events) == b.replaySize {
+			buffer.evicted = buffer.events[0].Id
+			copy(buffer.events, buffer.events[1:])
+			buffer.events = buffer.events[:len(buffer.events)-1]
+		}
+		buffer.events = append(buffer.events, ev)
+
+		// - subscribers of the tenant
+		for s := range b.subscriptions {
+			if s.tenant != ev.Tenant {
+				continue
+			}
+			select {
+			case s.events <- ev:
+			default:
+				// the subscriber is too slow, it is disconnected and may resume from the last event it got
+				s.overflowed = true
+				b.remove(s)
+			}
+		}
+	}
+}
+
+// Subscribe is a method that subscribes to the events of the tenant of the context published after the event with id after
+func (b *VehicleBus) Subscribe(ctx context.Context, after uint64) (s internal.VehicleSubscription, replay []internal.VehicleEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tenant := internal.Tenant(ctx)
+	sub := &VehicleBusSubscription{bus: b, tenant: tenant, events: make(chan internal.VehicleEvent, b.subscriberSize)}
+	b.subscriptions[sub] = struct{}{}
+	s = sub
+	if b.closed {
+		b.remove(sub)
+	}
+
+	complete = true
+	if after == 0 {
+		return
+	}
+	// - the events of the tenant after are complete if none of them was dropped from its buffer
+	buffer, ok := b.replays[tenant]
+	switch {
+	case after > b.seq:
+		complete = false
+	case !ok:
+		return
+	case after < buffer.evicted:
+		complete = false
+	}
+	for _, ev := range buffer.events {
+		if ev.Id > after {
+			replay = append(replay, ev)
+		}
+	}
+	return
+}
+
+// Subscribers is a method that returns the number of current subscriptions
+func (b *VehicleBus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscriptions)
+}
+
+// Close is a method that ends all the subscriptions, and the ones made afterwards,
+// so the streams end when the server shuts down
+func (b *VehicleBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subscriptions {
+		b.remove(s)
+	}
+}
+
+// remove is a method that ends a subscription, the lock must be held
+func (b *VehicleBus) remove(s *VehicleBusSubscription) {
+	if _, ok := b.subscriptions[s]; !ok {
+		return
+	}
+	delete(b.subscriptions, s)
+	close(s.events)
+}
+
+// VehicleBusSubscription is a struct that represents a subscription to a VehicleBus
+type VehicleBusSubscription struct {
+	// bus is the bus of the subscription
+	bus *VehicleBus
+	// tenant is the tenant of the events of the subscriber
+	tenant string
+	// events is the channel of the events of the subscriber
+	events chan internal.VehicleEvent
+	// overflowed is true if the subscriber was disconnected for being too slow, protected by the lock of the bus
+	overflowed bool
+}
+
+// Events is a method that returns the channel of the events
+func (s *VehicleBusSubscription) Events() <-chan internal.VehicleEvent {
+	return s.events
+}
+
+// Overflowed is a method that returns true if the subscriber was disconnected for being too slow
+func (s *VehicleBusSubscription) Overflowed() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.overflowed
+}
+
+// Close is a method that ends the subscription
+func (s *VehicleBusSubscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
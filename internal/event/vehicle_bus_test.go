@@ -0,0 +1,143 @@
+package event_test
+
+import (
+	"app/internal"
+	"app/internal/event"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// created is a function that returns a created event of the vehicle
+func created(id int) internal.VehicleEvent {
+	return internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: id}
+}
+
+// ids is a function that returns the ids of the vehicles of the events
+func ids(events []internal.VehicleEvent) (v []int) {
+	for _, ev := range events {
+		v = append(v, ev.VehicleId)
+	}
+	return
+}
+
+// Tests for VehicleBus
+func TestVehicleBus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: the subscribers get the events in order with increasing ids", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		sub, replay, complete := bus.Subscribe(ctx, 0)
+		defer sub.Close()
+
+		// act
+		bus.Publish(ctx, created(1), created(2))
+
+		// assert
+		require.Empty(t, replay)
+		require.True(t, complete)
+		ev1, ev2 := <-sub.Events(), <-sub.Events()
+		require.Equal(t, []uint64{1, 2}, []uint64{ev1.Id, ev2.Id})
+		require.Equal(t, []int{1, 2}, []int{ev1.VehicleId, ev2.VehicleId})
+		require.False(t, ev1.OccurredAt.IsZero())
+	})
+
+	t.Run("case 2: a subscriber resumes after the last event it got", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		bus.Publish(ctx, created(1), created(2), created(3))
+
+		// act
+		sub, replay, complete := bus.Subscribe(ctx, 1)
+		defer sub.Close()
+
+		// assert
+		require.True(t, complete)
+		require.Equal(t, []int{2, 3}, ids(replay))
+	})
+
+	t.Run("case 3: a subscriber that missed events no longer buffered gets an incomplete replay", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(2, 10)
+		bus.Publish(ctx, created(1), created(2), created(3), created(4))
+
+		// act
+		_, replay1, complete1 := bus.Subscribe(ctx, 1)
+		_, replay2, complete2 := bus.Subscribe(ctx, 2)
+		_, _, complete3 := bus.Subscribe(ctx, 99)
+
+		// assert
+		require.False(t, complete1)
+		require.Equal(t, []int{3, 4}, ids(replay1))
+		require.True(t, complete2)
+		require.Equal(t, []int{3, 4}, ids(replay2))
+		require.False(t, complete3)
+	})
+
+	t.Run("case 4: a slow subscriber is disconnected without blocking the publisher", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 1)
+		slow, _, _ := bus.Subscribe(ctx, 0)
+		fast, _, _ := bus.Subscribe(ctx, 0)
+		defer fast.Close()
+
+		// act
+		bus.Publish(ctx, created(1))
+		<-fast.Events()
+		bus.Publish(ctx, created(2))
+
+		// assert
+		require.Equal(t, 1, (<-slow.Events()).VehicleId)
+		_, ok := <-slow.Events()
+		require.False(t, ok)
+		require.True(t, slow.Overflowed())
+		require.False(t, fast.Overflowed())
+		require.Equal(t, 1, bus.Subscribers())
+	})
+
+	t.Run("case 5: closing the bus ends every subscription", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		sub, _, _ := bus.Subscribe(ctx, 0)
+
+		// act
+		bus.Close()
+		late, _, _ := bus.Subscribe(ctx, 0)
+
+		// assert
+		_, ok := <-sub.Events()
+		require.False(t, ok)
+		_, ok = <-late.Events()
+		require.False(t, ok)
+		require.False(t, sub.Overflowed())
+		sub.Close()
+	})
+
+	t.Run("case 6: each tenant has its own replay buffer and subscribers", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(2, 10)
+		acme := internal.ContextWithTenant(ctx, "acme")
+		globex := internal.ContextWithTenant(ctx, "globex")
+		live, _, _ := bus.Subscribe(acme, 0)
+		defer live.Close()
+		bus.Publish(acme, created(1), created(2))
+		bus.Publish(globex, created(3), created(4), created(5))
+
+		// act
+		_, replayAcme, completeAcme := bus.Subscribe(acme, 1)
+		_, replayGlobex, completeGlobex := bus.Subscribe(globex, 3)
+		_, _, completeMissed := bus.Subscribe(globex, 2)
+
+		// assert
+		require.True(t, completeAcme)
+		require.Equal(t, []int{2}, ids(replayAcme))
+		require.True(t, completeGlobex)
+		require.Equal(t, []int{4, 5}, ids(replayGlobex))
+		require.False(t, completeMissed)
+		require.Equal(t, 1, (<-live.Events()).VehicleId)
+		require.Equal(t, 2, (<-live.Events()).VehicleId)
+		require.Empty(t, live.Events())
+	})
+}
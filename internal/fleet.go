@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFleetNotFound is returned when a fleet does not exist
+	ErrFleetNotFound = errors.New("fleet not found")
+	// ErrInvalidFleet is returned when a fleet has invalid values
+	ErrInvalidFleet = errors.New("invalid fleet")
+	// ErrFleetAlreadyExists is returned when the name of a fleet is already used
+	ErrFleetAlreadyExists = errors.New("fleet already exists")
+	// ErrFleetNotEmpty is returned when a fleet with vehicles or sub-fleets is deleted under the restrict policy
+	ErrFleetNotEmpty = errors.New("fleet not empty")
+	// ErrVehicleNotInFleet is returned when a vehicle is removed from a fleet it is not a member of
+	ErrVehicleNotInFleet = errors.New("vehicle not in fleet")
+)
+
+const (
+	// FleetOrphanUnassign leaves the vehicles of a deleted fleet without fleet, and its sub-fleets at the top level
+	FleetOrphanUnassign = "unassign"
+	// FleetOrphanParent moves the vehicles and the sub-fleets of a deleted fleet to its parent fleet
+	FleetOrphanParent = "parent"
+	// FleetOrphanRestrict rejects the deletion of a fleet with vehicles or sub-fleets
+	FleetOrphanRestrict = "restrict"
+)
+
+// Fleet is a struct that represents a named group of vehicles, e.g. a depot or one of its sub-fleets
+type Fleet struct {
+	// Id is the unique identifier of the fleet
+	Id int
+	// Name is the name of the fleet, unique per tenant
+	Name string
+	// Depot is the depot where the vehicles of the fleet are based
+	Depot string
+	// ParentId is the id of the fleet it is a sub-fleet of, 0 for a top-level fleet
+	ParentId int
+	// CreatedAt is the time when the fleet was created
+	CreatedAt time.Time
+}
+
+// VehicleStats is a struct that represents the statistics of a group of vehicles
+type VehicleStats struct {
+	// Count is the number of vehicles
+	Count int
+	// AverageMaxSpeed is the average of the maximum speeds, 0 without vehicles
+	AverageMaxSpeed float64
+	// FuelMix is the number of vehicles by fuel type
+	FuelMix map[string]int
+}
+
+// NewVehicleStats is a function that aggregates the statistics of vehicles
+func NewVehicleStats(v []Vehicle) (st VehicleStats) {
+	st.FuelMix = make(map[string]int)
+	var sum float64
+	for _, value := range v {
+		sum += value.MaxSpeed
+		st.FuelMix[value.FuelType]++
+	}
+	st.Count = len(v)
+	if st.Count > 0 {
+		st.AverageMaxSpeed = sum / float64(st.Count)
+	}
+	return
+}
+
+// FleetRepository is an interface that represents the storage of the fleets and of the fleet of each vehicle
+type FleetRepository interface {
+	// CreateFleet is a method that registers a fleet, assigning its id
+	CreateFleet(ctx context.Context, f Fleet) (created Fleet, err error)
+	// FindFleets is a method that returns all the fleets, by id
+	FindFleets(ctx context.Context) (f []Fleet, err error)
+	// FindFleetById is a method that returns a fleet by id
+	FindFleetById(ctx context.Context, id int) (f Fleet, err error)
+	// UpdateFleet is a method that replaces the name, the depot and the parent of a fleet
+	UpdateFleet(ctx context.Context, f Fleet) (err error)
+	// DeleteFleet is a method that deletes a fleet, moving its vehicles and its sub-fleets to the fleet moveTo
+	// (without fleet and to the top level if 0)
+	DeleteFleet(ctx context.Context, id int, moveTo int) (err error)
+	// AssignVehicle is a method that makes a vehicle a member of a fleet, leaving its previous fleet if any
+	AssignVehicle(ctx context.Context, vehicleId int, fleetId int) (err error)
+	// UnassignVehicle is a method that leaves a vehicle without fleet
+	UnassignVehicle(ctx context.Context, vehicleId int) (err error)
+	// FindFleetOf is a method that returns the id of the fleet of a vehicle, 0 if it has none
+	FindFleetOf(ctx context.Context, vehicleId int) (fleetId int, err error)
+	// FindMembers is a method that returns the ids of the vehicles of a fleet, in order
+	FindMembers(ctx context.Context, fleetId int) (vehicleIds []int, err error)
+}
+
+// FleetService is an interface that represents the management of the fleets
+type FleetService interface {
+	// CreateFleet is a method that validates and registers a fleet
+	CreateFleet(ctx context.Context, f Fleet) (created Fleet, err error)
+	// FindFleets is a method that returns all the fleets, by id
+	FindFleets(ctx context.Context) (f []Fleet, err error)
+	// FindFleetById is a method that returns a fleet by id
+	FindFleetById(ctx context.Context, id int) (f Fleet, err error)
+	// UpdateFleet is a method that validates and replaces the name, the depot and the parent of a fleet
+	UpdateFleet(ctx context.Context, f Fleet) (updated Fleet, err error)
+	// DeleteFleet is a method that deletes a fleet, handling its vehicles and its sub-fleets by the orphan policy
+	DeleteFleet(ctx context.Context, id int) (err error)
+	// AssignVehicle is a method that makes a vehicle a member of a fleet, leaving its previous fleet if any
+	AssignVehicle(ctx context.Context, fleetId int, vehicleId int) (err error)
+	// UnassignVehicle is a method that removes a vehicle from a fleet
+	UnassignVehicle(ctx context.Context, fleetId int, vehicleId int) (err error)
+	// FindFleetVehicles is a method that returns the vehicles of a fleet and, if subfleets, of its sub-fleets, by id
+	FindFleetVehicles(ctx context.Context, id int, subfleets bool) (v []Vehicle, err error)
+	// FindFleetStats is a method that returns the statistics of the vehicles of a fleet and, if subfleets, of its sub-fleets
+	FindFleetStats(ctx context.Context, id int, subfleets bool) (st VehicleStats, err error)
+}
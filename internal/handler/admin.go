@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// LoadIssueJSON is a struct that represents an issue of the load report in JSON format
+type LoadIssueJSON struct {
+	Index   int    `json:"index"`
+	ID      int    `json:"id"`
+	Source  string `json:"source"`
+	Field   string `json:"field"`
+	Kind    string `json:"kind"`
+	Value   any    `json:"value"`
+	Message string `json:"message"`
+	Skipped bool   `json:"skipped"`
+}
+
+// LoadReportJSON is a struct that represents the load report in JSON format
+type LoadReportJSON struct {
+	Source        string          `json:"source"`
+	Mode          string          `json:"mode"`
+	Policy        string          `json:"conflict_policy"`
+	LoadedAt      time.Time       `json:"loaded_at"`
+	Total         int             `json:"total"`
+	Loaded        int             `json:"loaded"`
+	Skipped       int             `json:"skipped"`
+	Duplicates    []LoadIssueJSON `json:"duplicates"`
+	MissingFields []LoadIssueJSON `json:"missing_fields"`
+	OutOfRange    []LoadIssueJSON `json:"out_of_range"`
+}
+
+// ReloadResultJSON is a struct that represents the result of a reload in JSON format
+type ReloadResultJSON struct {
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Outcome    string    `json:"outcome"`
+	Loaded     int       `json:"loaded"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// NewAdminDefault is a function that returns a new instance of AdminDefault
+func NewAdminDefault(rt internal.VehicleLoadReporter, rl internal.VehicleReloader, sn internal.VehicleSnapshotter) *AdminDefault {
+	return &AdminDefault{rt: rt, rl: rl, sn: sn}
+}
+
+// AdminDefault is a struct with methods that represent handlers for the administration of the service
+type AdminDefault struct {
+	// rt is the reporter of the last load of the vehicles
+	rt internal.VehicleLoadReporter
+	// rl is the reloader of the vehicles dataset
+	rl internal.VehicleReloader
+	// sn is the snapshotter of the vehicles
+	sn internal.VehicleSnapshotter
+}
+
+// maxSnapshotSize is the maximum size of a snapshot uploaded to restore the vehicles
+const maxSnapshotSize = 64 << 20
+
+// LoadReport is a method that returns a handler for the route GET /admin/load-report
+func (h *AdminDefault) LoadReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// - get the report of the last load
+		rp := h.rt.Report()
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    loadReportToJSON(rp),
+		})
+	}
+}
+
+// Reload is a method that returns a handler for the route POST /admin/reload
+func (h *AdminDefault) Reload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// - reload the dataset
+		res, err := h.rl.Reload("manual")
+
+		// response
+		data := ReloadResultJSON{
+			Trigger:    res.Trigger,
+			StartedAt:  res.StartedAt,
+			FinishedAt: res.FinishedAt,
+			Outcome:    res.Outcome,
+			Loaded:     res.Loaded,
+			Error:      res.Error,
+		}
+		if err != nil {
+			response.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+				"message": "422 Unprocessable Entity: the new dataset failed validation, the previous one is kept",
+				"data":    data,
+			})
+			return
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// Snapshot is a method that returns a handler for the route GET /admin/snapshot
+func (h *AdminDefault) Snapshot() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// - stream a consistent copy of the vehicles
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="vehicles-%s.json"`, time.Now().UTC().Format("20060102T150405Z")))
+		w.WriteHeader(http.StatusOK)
+		if _, err := h.sn.Snapshot(r.Context(), w); err != nil {
+			// the status is already sent, the client gets a truncated document
+			slog.ErrorContext(r.Context(), "admin: snapshot failed", "error", err)
+		}
+	}
+}
+
+// Restore is a method that returns a handler for the route POST /admin/restore
+func (h *AdminDefault) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		body := http.MaxBytesReader(w, r.Body, maxSnapshotSize)
+		var allowEmpty bool
+		if value := r.URL.Query().Get("allow_empty"); value != "" {
+			var err error
+			if allowEmpty, err = strconv.ParseBool(value); err != nil {
+				response.JSON(w, http.StatusBadRequest, "400 Bad Request: allow_empty must be true or false")
+				return
+			}
+		}
+
+		// process
+		// - validate the snapshot and replace the vehicles
+		rp, err := h.sn.Restore(r.Context(), body, allowEmpty)
+		if err != nil {
+			var errSize *http.MaxBytesError
+			switch {
+			case errors.As(err, &errSize):
+				response.JSON(w, http.StatusRequestEntityTooLarge, "413 Request Entity Too Large: the snapshot is too large")
+			case errors.Is(err, internal.ErrDatasetMalformed):
+				response.JSON(w, http.StatusBadRequest, "400 Bad Request: the snapshot is not a vehicles document")
+			case errors.Is(err, internal.ErrDatasetEmpty):
+				response.JSON(w, http.StatusUnprocessableEntity, "422 Unprocessable Entity: the snapshot has no vehicles, set allow_empty=true to delete every vehicle")
+			case errors.Is(err, internal.ErrDatasetRejected), errors.Is(err, internal.ErrDatasetConflict):
+				response.JSON(w, http.StatusUnprocessableEntity, map[string]any{
+					"message": "422 Unprocessable Entity: the snapshot failed validation, the vehicles were not replaced",
+					"error":   err.Error(),
+					"data":    loadReportToJSON(rp),
+				})
+			default:
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    loadReportToJSON(rp),
+		})
+	}
+}
+
+// loadReportToJSON is a function that serializes a load report to its JSON format
+func loadReportToJSON(rp internal.LoadReport) (data LoadReportJSON) {
+	issuesToJSON := func(issues []internal.LoadIssue) (data []LoadIssueJSON) {
+		data = make([]LoadIssueJSON, len(issues))
+		for key, value := range issues {
+			data[key] = LoadIssueJSON{
+				Index:   value.Index,
+				ID:      value.Id,
+				Source:  value.Source,
+				Field:   value.Field,
+				Kind:    value.Kind,
+				Value:   value.Value,
+				Message: value.Message,
+				Skipped: value.Skipped,
+			}
+		}
+		return
+	}
+
+	data = LoadReportJSON{
+		Source:        rp.Source,
+		Mode:          rp.Mode,
+		Policy:        rp.ConflictPolicy,
+		LoadedAt:      rp.LoadedAt,
+		Total:         rp.Total,
+		Loaded:        rp.Loaded,
+		Skipped:       rp.Skipped,
+		Duplicates:    issuesToJSON(rp.Duplicates),
+		MissingFields: issuesToJSON(rp.MissingFields),
+		OutOfRange:    issuesToJSON(rp.OutOfRange),
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"app/internal"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// AuditEntryJSON is a struct that represents an entry of the audit log in JSON format
+type AuditEntryJSON struct {
+	ID        uint64                     `json:"id"`
+	At        time.Time                  `json:"at"`
+	Actor     string                     `json:"actor"`
+	RequestID string                     `json:"request_id,omitempty"`
+	Operation string                     `json:"operation"`
+	VehicleID int                        `json:"vehicle_id"`
+	Changes   map[string]FieldChangeJSON `json:"changes"`
+}
+
+// NewAuditDefault is a function that returns a new instance of AuditDefault
+func NewAuditDefault(rp internal.AuditRepository) *AuditDefault {
+	return &AuditDefault{rp: rp}
+}
+
+// AuditDefault is a struct with methods that represent handlers for the audit log of the vehicles
+type AuditDefault struct {
+	// rp is the audit log
+	rp internal.AuditRepository
+}
+
+// History is a method that returns a handler for the route GET /vehicles/{id}/history.
+// The history of a deleted vehicle is still returned.
+func (h *AuditDefault) History() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil || id <= 0 {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		// - the changes of the vehicle of the tenant, oldest first
+		entries, err := h.rp.Find(r.Context(), internal.AuditQuery{Tenant: internal.Tenant(r.Context()), VehicleId: id})
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    auditEntriesToJSON(entries),
+		})
+	}
+}
+
+// Search is a method that returns a handler for the route GET /audit?actor={actor}&since={since}
+func (h *AuditDefault) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		q := internal.AuditQuery{Tenant: internal.Tenant(r.Context()), Actor: r.URL.Query().Get("actor")}
+		if since := r.URL.Query().Get("since"); since != "" {
+			var err error
+			if q.Since, err = time.Parse(time.RFC3339, since); err != nil {
+				response.JSON(w, http.StatusBadRequest, "400 Bad Request: since must be an RFC 3339 time (e.g. 2024-01-02T15:04:05Z)")
+				return
+			}
+		}
+
+		// process
+		// - the changes of the tenant matching the filters, oldest first
+		entries, err := h.rp.Find(r.Context(), q)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    auditEntriesToJSON(entries),
+		})
+	}
+}
+
+// auditEntriesToJSON is a function that serializes entries of the audit log to their JSON format
+func auditEntriesToJSON(entries []internal.AuditEntry) (data []AuditEntryJSON) {
+	data = make([]AuditEntryJSON, len(entries))
+	for key, value := range entries {
+		data[key] = AuditEntryJSON{
+			ID:        value.Id,
+			At:        value.At,
+			Actor:     value.Actor,
+			RequestID: value.RequestId,
+			Operation: value.Operation,
+			VehicleID: value.VehicleId,
+			Changes:   make(map[string]FieldChangeJSON, len(value.Changes)),
+		}
+		for name, change := range value.Changes {
+			data[key].Changes[name] = FieldChangeJSON{From: change.From, To: change.To}
+		}
+	}
+	return
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// FleetJSON is a struct that represents a fleet in JSON format
+type FleetJSON struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Depot     string    `json:"depot,omitempty"`
+	ParentID  int       `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BodyRequestFleetJSON is a struct that represents the body of a request to create or update a fleet
+type BodyRequestFleetJSON struct {
+	Name     string `json:"name"`
+	Depot    string `json:"depot"`
+	ParentID int    `json:"parent_id"`
+}
+
+// VehicleStatsJSON is a struct that represents the statistics of a group of vehicles in JSON format
+type VehicleStatsJSON struct {
+	Count           int            `json:"count"`
+	AverageMaxSpeed float64        `json:"average_max_speed"`
+	FuelMix         map[string]int `json:"fuel_mix"`
+}
+
+// NewFleetDefault is a function that returns a new instance of FleetDefault
+func NewFleetDefault(sv internal.FleetService) *FleetDefault {
+	return &FleetDefault{sv: sv}
+}
+
+// FleetDefault is a struct with methods that represent handlers for the fleets
+type FleetDefault struct {
+	// sv is the service of the fleets
+	sv internal.FleetService
+}
+
+// Create is a method that returns a handler for the route POST /fleets
+func (h *FleetDefault) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var body BodyRequestFleetJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
+			return
+		}
+
+		// process
+		f, err := h.sv.CreateFleet(r.Context(), internal.Fleet{Name: body.Name, Depot: body.Depot, ParentId: body.ParentID})
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "success",
+			"data":    fleetToJSON(f),
+		})
+	}
+}
+
+// GetAll is a method that returns a handler for the route GET /fleets
+func (h *FleetDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		f, err := h.sv.FindFleets(r.Context())
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		data := make([]FleetJSON, len(f))
+		for key, value := range f {
+			data[key] = fleetToJSON(value)
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetById is a method that returns a handler for the route GET /fleets/{id}
+func (h *FleetDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		f, err := h.sv.FindFleetById(r.Context(), id)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    fleetToJSON(f),
+		})
+	}
+}
+
+// Update is a method that returns a handler for the route PUT /fleets/{id}
+func (h *FleetDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		var body BodyRequestFleetJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
+			return
+		}
+
+		// process
+		f, err := h.sv.UpdateFleet(r.Context(), internal.Fleet{Id: id, Name: body.Name, Depot: body.Depot, ParentId: body.ParentID})
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    fleetToJSON(f),
+		})
+	}
+}
+
+// Delete is a method that returns a handler for the route DELETE /fleets/{id}.
+// Its vehicles and its sub-fleets are handled by the orphan policy.
+func (h *FleetDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		if err := h.sv.DeleteFleet(r.Context(), id); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetVehicles is a method that returns a handler for the route GET /fleets/{id}/vehicles?subfleets={bool}
+func (h *FleetDefault) GetVehicles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, subfleets, ok := fleetQuery(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		v, err := h.sv.FindFleetVehicles(r.Context(), id, subfleets)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		data := make([]VehicleJSON, len(v))
+		for key, value := range v {
+			data[key] = vehicleToJSON(value)
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetStats is a method that returns a handler for the route GET /fleets/{id}/stats?subfleets={bool}
+func (h *FleetDefault) GetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, subfleets, ok := fleetQuery(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		st, err := h.sv.FindFleetStats(r.Context(), id, subfleets)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data": VehicleStatsJSON{
+				Count:           st.Count,
+				AverageMaxSpeed: st.AverageMaxSpeed,
+				FuelMix:         st.FuelMix,
+			},
+		})
+	}
+}
+
+// AssignVehicle is a method that returns a handler for the route PUT /fleets/{id}/vehicles/{vehicle_id}.
+// The vehicle leaves its previous fleet, if any.
+func (h *FleetDefault) AssignVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, vehicleId, ok := fleetVehicleParams(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		if err := h.sv.AssignVehicle(r.Context(), id, vehicleId); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// UnassignVehicle is a method that returns a handler for the route DELETE /fleets/{id}/vehicles/{vehicle_id}
+func (h *FleetDefault) UnassignVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, vehicleId, ok := fleetVehicleParams(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		if err := h.sv.UnassignVehicle(r.Context(), id, vehicleId); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// fail is a method that writes the response of an error of the service
+func (h *FleetDefault) fail(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, internal.ErrFleetNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: fleet not found")
+	case errors.Is(err, internal.ErrVehicleNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: vehicle not found")
+	case errors.Is(err, internal.ErrVehicleNotInFleet):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: the vehicle is not a member of the fleet")
+	case errors.Is(err, internal.ErrInvalidFleet):
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: "+err.Error())
+	case errors.Is(err, internal.ErrFleetAlreadyExists):
+		response.JSON(w, http.StatusConflict, "409 Conflict: a fleet with this name already exists")
+	case errors.Is(err, internal.ErrFleetNotEmpty):
+		response.JSON(w, http.StatusConflict, "409 Conflict: "+err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "error", err)
+		response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
+
+// fleetQuery is a function that parses the id of a fleet and the subfleets parameter of a request,
+// writing a 400 response if they are invalid
+func fleetQuery(w http.ResponseWriter, r *http.Request) (id int, subfleets bool, ok bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+		return
+	}
+	if value := r.URL.Query().Get("subfleets"); value != "" {
+		if subfleets, err = strconv.ParseBool(value); err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: subfleets must be true or false")
+			return
+		}
+	}
+	ok = true
+	return
+}
+
+// fleetVehicleParams is a function that parses the id of a fleet and the id of a vehicle of a request,
+// writing a 400 response if they are invalid
+func fleetVehicleParams(w http.ResponseWriter, r *http.Request) (id int, vehicleId int, ok bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+		return
+	}
+	if vehicleId, err = strconv.Atoi(chi.URLParam(r, "vehicle_id")); err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid vehicle_id parameter")
+		return
+	}
+	ok = true
+	return
+}
+
+// fleetToJSON is a function that serializes a fleet to its JSON format
+func fleetToJSON(f internal.Fleet) FleetJSON {
+	return FleetJSON{
+		ID:        f.Id,
+		Name:      f.Name,
+		Depot:     f.Depot,
+		ParentID:  f.ParentId,
+		CreatedAt: f.CreatedAt,
+	}
+}
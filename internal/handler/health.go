@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"app/internal"
+	"net/http"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// ComponentHealthJSON is a struct that represents the status of a component in JSON format
+type ComponentHealthJSON struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// DatasetVersionJSON is a struct that represents the version of the dataset in JSON format
+type DatasetVersionJSON struct {
+	Generation    int       `json:"generation"`
+	SchemaVersion int       `json:"schema_version"`
+	Trigger       string    `json:"trigger"`
+	LoadedAt      time.Time `json:"loaded_at"`
+}
+
+// NewHealthDefault is a function that returns a new instance of HealthDefault
+func NewHealthDefault(dv internal.DatasetVersioner, components map[string]internal.HealthChecker) *HealthDefault {
+	return &HealthDefault{dv: dv, components: components}
+}
+
+// HealthDefault is a struct with methods that represent handlers for the probes of the orchestrator
+type HealthDefault struct {
+	// dv is the source of the version of the dataset in use
+	dv internal.DatasetVersioner
+	// components are the components checked by the readiness probe, by name
+	components map[string]internal.HealthChecker
+}
+
+// Liveness is a method that returns a handler for the route GET /healthz.
+// The process is alive as long as it serves requests, so the components are not checked.
+func (h *HealthDefault) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// ...
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "alive",
+			"data":    map[string]any{"status": internal.HealthUp},
+		})
+	}
+}
+
+// Readiness is a method that returns a handler for the route GET /readyz.
+// The service is ready only when every component is up.
+func (h *HealthDefault) Readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// - check the components
+		ready := true
+		components := make(map[string]ComponentHealthJSON, len(h.components))
+		for name, hc := range h.components {
+			ch := hc.Health()
+			if ch.Status != internal.HealthUp {
+				ready = false
+			}
+			components[name] = ComponentHealthJSON{
+				Status:  ch.Status,
+				Message: ch.Message,
+				Details: ch.Details,
+			}
+		}
+		// - get the version of the dataset
+		dv := h.dv.DatasetVersion()
+
+		// response
+		code, message, status := http.StatusOK, "ready", internal.HealthUp
+		if !ready {
+			code, message, status = http.StatusServiceUnavailable, "not ready", internal.HealthDown
+		}
+		response.JSON(w, code, map[string]any{
+			"message": message,
+			"data": map[string]any{
+				"status": status,
+				"dataset_version": DatasetVersionJSON{
+					Generation:    dv.Generation,
+					SchemaVersion: dv.SchemaVersion,
+					Trigger:       dv.Trigger,
+					LoadedAt:      dv.LoadedAt,
+				},
+				"components": components,
+			},
+		})
+	}
+}
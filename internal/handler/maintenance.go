@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// MaintenancePartJSON is a struct that represents a part used in a maintenance in JSON format
+type MaintenancePartJSON struct {
+	Name     string `json:"name"`
+	Number   string `json:"number,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// MaintenanceRecordJSON is a struct that represents a maintenance record in JSON format
+type MaintenanceRecordJSON struct {
+	ID        int                   `json:"id"`
+	VehicleID int                   `json:"vehicle_id"`
+	Date      string                `json:"date"`
+	Odometer  float64               `json:"odometer"`
+	Type      string                `json:"type"`
+	Cost      float64               `json:"cost"`
+	Notes     string                `json:"notes,omitempty"`
+	Parts     []MaintenancePartJSON `json:"parts"`
+}
+
+// BodyRequestMaintenanceRecordJSON is a struct that represents the body of a request to create or update a maintenance record
+type BodyRequestMaintenanceRecordJSON struct {
+	Date     string                `json:"date"`
+	Odometer float64               `json:"odometer"`
+	Type     string                `json:"type"`
+	Cost     float64               `json:"cost"`
+	Notes    string                `json:"notes"`
+	Parts    []MaintenancePartJSON `json:"parts"`
+}
+
+// NewMaintenanceDefault is a function that returns a new instance of MaintenanceDefault
+func NewMaintenanceDefault(sv internal.MaintenanceService) *MaintenanceDefault {
+	return &MaintenanceDefault{sv: sv}
+}
+
+// MaintenanceDefault is a struct with methods that represent handlers for the maintenance records of the vehicles
+type MaintenanceDefault struct {
+	// sv is the service of the maintenance records
+	sv internal.MaintenanceService
+}
+
+// Create is a method that returns a handler for the route POST /vehicles/{id}/maintenance
+func (h *MaintenanceDefault) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		vehicleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		rc, ok := maintenanceBody(w, r)
+		if !ok {
+			return
+		}
+		rc.VehicleId = vehicleId
+
+		// process
+		created, err := h.sv.CreateRecord(r.Context(), rc)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "success",
+			"data":    maintenanceRecordToJSON(created),
+		})
+	}
+}
+
+// GetAll is a method that returns a handler for the route GET /vehicles/{id}/maintenance, the oldest record first
+func (h *MaintenanceDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		vehicleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		rc, err := h.sv.FindRecords(r.Context(), vehicleId)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		data := make([]MaintenanceRecordJSON, len(rc))
+		for key, value := range rc {
+			data[key] = maintenanceRecordToJSON(value)
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetById is a method that returns a handler for the route GET /vehicles/{id}/maintenance/{record_id}
+func (h *MaintenanceDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		vehicleId, id, ok := maintenanceParams(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		rc, err := h.sv.FindRecordById(r.Context(), vehicleId, id)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    maintenanceRecordToJSON(rc),
+		})
+	}
+}
+
+// Update is a method that returns a handler for the route PUT /vehicles/{id}/maintenance/{record_id}
+func (h *MaintenanceDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		vehicleId, id, ok := maintenanceParams(w, r)
+		if !ok {
+			return
+		}
+		rc, ok := maintenanceBody(w, r)
+		if !ok {
+			return
+		}
+		rc.Id = id
+		rc.VehicleId = vehicleId
+
+		// process
+		updated, err := h.sv.UpdateRecord(r.Context(), rc)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    maintenanceRecordToJSON(updated),
+		})
+	}
+}
+
+// Delete is a method that returns a handler for the route DELETE /vehicles/{id}/maintenance/{record_id}
+func (h *MaintenanceDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		vehicleId, id, ok := maintenanceParams(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		if err := h.sv.DeleteRecord(r.Context(), vehicleId, id); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// fail is a method that writes the response of an error of the service
+func (h *MaintenanceDefault) fail(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, internal.ErrVehicleNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: vehicle not found")
+	case errors.Is(err, internal.ErrMaintenanceRecordNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: maintenance record not found")
+	case errors.Is(err, internal.ErrInvalidMaintenanceRecord):
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: "+err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "error", err)
+		response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
+
+// maintenanceParams is a function that parses the id of a vehicle and the id of a maintenance record of a request,
+// writing a 400 response if they are invalid
+func maintenanceParams(w http.ResponseWriter, r *http.Request) (vehicleId int, id int, ok bool) {
+	vehicleId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+		return
+	}
+	if id, err = strconv.Atoi(chi.URLParam(r, "record_id")); err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid record_id parameter")
+		return
+	}
+	ok = true
+	return
+}
+
+// maintenanceBody is a function that parses the body of a request to create or update a maintenance record,
+// writing a 400 response if it is invalid. The date is a day (e.g. 2024-01-02).
+func maintenanceBody(w http.ResponseWriter, r *http.Request) (rc internal.MaintenanceRecord, ok bool) {
+	var body BodyRequestMaintenanceRecordJSON
+	if err := request.JSON(r, &body); err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
+		return
+	}
+	date, err := time.Parse(time.DateOnly, body.Date)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: date must be a day (e.g. 2024-01-02)")
+		return
+	}
+	rc = internal.MaintenanceRecord{
+		Date:     date,
+		Odometer: body.Odometer,
+		Type:     body.Type,
+		Cost:     body.Cost,
+		Notes:    body.Notes,
+	}
+	for _, part := range body.Parts {
+		rc.Parts = append(rc.Parts, internal.MaintenancePart{Name: part.Name, Number: part.Number, Quantity: part.Quantity})
+	}
+	ok = true
+	return
+}
+
+// maintenanceRecordToJSON is a function that serializes a maintenance record to its JSON format
+func maintenanceRecordToJSON(rc internal.MaintenanceRecord) MaintenanceRecordJSON {
+	data := MaintenanceRecordJSON{
+		ID:        rc.Id,
+		VehicleID: rc.VehicleId,
+		Date:      rc.Date.Format(time.DateOnly),
+		Odometer:  rc.Odometer,
+		Type:      rc.Type,
+		Cost:      rc.Cost,
+		Notes:     rc.Notes,
+		Parts:     make([]MaintenancePartJSON, len(rc.Parts)),
+	}
+	for key, value := range rc.Parts {
+		data.Parts[key] = MaintenancePartJSON{Name: value.Name, Number: value.Number, Quantity: value.Quantity}
+	}
+	return data
+}
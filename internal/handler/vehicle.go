@@ -2,46 +2,49 @@ package handler
 
 import (
 	"app/internal"
-	"net/http"
-	"github.com/bootcamp-go/web/response"
 	"app/platform/web/request"
 	"errors"
-	"strconv"
+	"github.com/bootcamp-go/web/response"
 	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"strconv"
 )
 
 // VehicleJSON is a struct that represents a vehicle in JSON format
 type VehicleJSON struct {
-	ID              int     `json:"id"`
-	Brand           string  `json:"brand"`
-	Model           string  `json:"model"`
-	Registration    string  `json:"registration"`
-	Color           string  `json:"color"`
-	FabricationYear int     `json:"year"`
-	Capacity        int     `json:"passengers"`
-	MaxSpeed        float64 `json:"max_speed"`
-	FuelType        string  `json:"fuel_type"`
-	Transmission    string  `json:"transmission"`
-	Weight          float64 `json:"weight"`
-	Height          float64 `json:"height"`
-	Length          float64 `json:"length"`
-	Width           float64 `json:"width"`
+	ID              int      `json:"id"`
+	Brand           string   `json:"brand"`
+	Model           string   `json:"model"`
+	Registration    string   `json:"registration"`
+	Color           string   `json:"color"`
+	FabricationYear int      `json:"year"`
+	Capacity        int      `json:"passengers"`
+	MaxSpeed        float64  `json:"max_speed"`
+	FuelType        string   `json:"fuel_type"`
+	Transmission    string   `json:"transmission"`
+	Weight          float64  `json:"weight"`
+	Height          float64  `json:"height"`
+	Length          float64  `json:"length"`
+	Width           float64  `json:"width"`
+	Tags            []string `json:"tags,omitempty"`
 }
 
 type BodyRequestVehicleJSON struct {
-	Brand           string  `json:"brand"`
-	Model           string  `json:"model"`
-	Registration    string  `json:"registration"`
-	Color           string  `json:"color"`
-	FabricationYear int     `json:"year"`
-	Capacity        int     `json:"passengers"`
-	MaxSpeed        float64 `json:"max_speed"`
-	FuelType        string  `json:"fuel_type"`
-	Transmission	string  `json:"transmission"`
-	Weight          float64 `json:"weight"`
-	Height          float64 `json:"height"`
-	Length          float64 `json:"length"`
-	Width           float64 `json:"width"`
+	Brand           string   `json:"brand"`
+	Model           string   `json:"model"`
+	Registration    string   `json:"registration"`
+	Color           string   `json:"color"`
+	FabricationYear int      `json:"year"`
+	Capacity        int      `json:"passengers"`
+	MaxSpeed        float64  `json:"max_speed"`
+	FuelType        string   `json:"fuel_type"`
+	Transmission    string   `json:"transmission"`
+	Weight          float64  `json:"weight"`
+	Height          float64  `json:"height"`
+	Length          float64  `json:"length"`
+	Width           float64  `json:"width"`
+	Tags            []string `json:"tags"`
 }
 
 type BodyRequestVehicleBatchJSON struct {
@@ -73,17 +76,40 @@ type VehicleDefault struct {
 	sv internal.VehicleService
 }
 
-// GetAll is a method that returns a handler for the route GET /vehicles
+// GetAll is a method that returns a handler for the route GET /vehicles?as_of={time}&tag={tag}&tag_match={all|any}.
+// The tag parameter can be repeated, the vehicles must have every tag (all, the default) or at least one of them (any).
 func (h *VehicleDefault) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
-		// ...
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: as_of must be an RFC 3339 time (e.g. 2024-01-02T15:04:05Z)")
+			return
+		}
+		tags, match, ok := tagQuery(w, r)
+		if !ok {
+			return
+		}
 
 		// process
-		// - get all vehicles
-		v, err := h.sv.FindAll()
+		// - get all vehicles, now or as they were at as_of, with the tags queried if any
+		var v map[int]internal.Vehicle
+		switch {
+		case asOf.IsZero() && len(tags) > 0:
+			v, err = h.sv.FindByTags(r.Context(), tags, match)
+		case asOf.IsZero():
+			v, err = h.sv.FindAll(r.Context())
+		default:
+			v, err = h.sv.FindAllAsOf(r.Context(), asOf)
+			for id, value := range v {
+				if len(tags) > 0 && !internal.MatchTags(value.Tags, tags, match) {
+					delete(v, id)
+				}
+			}
+		}
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError,  "500 Internal Server Error")
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			return
 		}
 
@@ -105,6 +131,7 @@ func (h *VehicleDefault) GetAll() http.HandlerFunc {
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 		response.JSON(w, http.StatusOK, map[string]any{
@@ -120,35 +147,35 @@ func (h *VehicleDefault) GetAll() http.HandlerFunc {
 - 400 Bad Request: Datos del vehículo mal formados o incompletos.
 - 409 Conflict: Identificador del vehículo ya existente.*/
 func (h *VehicleDefault) Create() http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        
+	return func(w http.ResponseWriter, r *http.Request) {
+
 		// request
-        var body BodyRequestVehicleJSON
+		var body BodyRequestVehicleJSON
 
 		// validate request body is correctly formed
-        if err := request.JSON(r, &body); err != nil {
+		if err := request.JSON(r, &body); err != nil {
 			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Vehicle data incorrectly formed")
 			return
 		}
 
-        // process
+		// process
 
-		vehicles, err := h.sv.FindAll()
+		//increment by 1 the highest id taken in the tenant, deleted vehicles included
+		lastId, err := h.sv.FindLastId(r.Context())
 		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
 			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			return
 		}
-		//increment by 1 the last id of the vehicle
-		vehiclesCount := len(vehicles)
 
 		// Incrementar el ID en uno
-		id := vehiclesCount + 1
+		id := lastId + 1
 
-        // - create vehicle
+		// - create vehicle
 		vehicle := internal.Vehicle{
-		Id: id, // Set the incremented ID
-		// Set the vehicle attributes
-		VehicleAttributes: internal.VehicleAttributes{
+			Id: id, // Set the incremented ID
+			// Set the vehicle attributes
+			VehicleAttributes: internal.VehicleAttributes{
 				Brand:           body.Brand,
 				Model:           body.Model,
 				Registration:    body.Registration,
@@ -164,6 +191,7 @@ func (h *VehicleDefault) Create() http.HandlerFunc {
 					Length: body.Length,
 					Width:  body.Width,
 				},
+				Tags: internal.CleanTags(body.Tags),
 			},
 		}
 
@@ -174,42 +202,44 @@ func (h *VehicleDefault) Create() http.HandlerFunc {
 		}
 
 		// Validate if the vehicle already exists and otherwise create it
-		if err := h.sv.CreateVehicle(vehicle); err != nil {
+		if err := h.sv.CreateVehicle(r.Context(), vehicle); err != nil {
 			switch {
-				case errors.Is(err, internal.ErrVehicleAlreadyExists):
-					response.JSON(w, http.StatusConflict, "409 Conflict")
-				default:
-					response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
-				}
-			return
+			case errors.Is(err, internal.ErrVehicleAlreadyExists):
+				response.JSON(w, http.StatusConflict, "409 Conflict")
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
+			return
+		}
 
-        // response
+		// response
 		// create variable data with the vehicle data in JSON format
-        data := VehicleJSON{
-            ID:              vehicle.Id,
-            Brand:           vehicle.Brand,
-            Model:           vehicle.Model,
-            Registration:    vehicle.Registration,
-            Color:           vehicle.Color,
-            FabricationYear: vehicle.FabricationYear,
-            Capacity:        vehicle.Capacity,
-            MaxSpeed:        vehicle.MaxSpeed,
-            FuelType:        vehicle.FuelType,
-            Transmission:    vehicle.Transmission,
-            Weight:          vehicle.Weight,
-            Height:          vehicle.Height,
-            Length:          vehicle.Length,
-            Width:           vehicle.Width,
-        }
+		data := VehicleJSON{
+			ID:              vehicle.Id,
+			Brand:           vehicle.Brand,
+			Model:           vehicle.Model,
+			Registration:    vehicle.Registration,
+			Color:           vehicle.Color,
+			FabricationYear: vehicle.FabricationYear,
+			Capacity:        vehicle.Capacity,
+			MaxSpeed:        vehicle.MaxSpeed,
+			FuelType:        vehicle.FuelType,
+			Transmission:    vehicle.Transmission,
+			Weight:          vehicle.Weight,
+			Height:          vehicle.Height,
+			Length:          vehicle.Length,
+			Width:           vehicle.Width,
+			Tags:            vehicle.Tags,
+		}
 		// return the response with the status code 201 and the data in JSON format
-        response.JSON(w, http.StatusCreated, map[string]interface{}{
-            "message": "201 Created: Vehículo creado exitosamente.",
-            "data":    data,
-        })
+		response.JSON(w, http.StatusCreated, map[string]interface{}{
+			"message": "201 Created: Vehículo creado exitosamente.",
+			"data":    data,
+		})
 	}
 }
-			
+
 // GetByColorAndYear is a method that returns a handler for the route GET /vehicles?color={color}&year={year}
 func (h *VehicleDefault) GetByColorAndYear() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -227,8 +257,9 @@ func (h *VehicleDefault) GetByColorAndYear() http.HandlerFunc {
 
 		// process
 		// - get vehicles by color and year
-		v, err := h.sv.FindByColorAndYear(color, year)
+		v, err := h.sv.FindByColorAndYear(r.Context(), color, year)
 		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
 			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			return
 		}
@@ -253,6 +284,7 @@ func (h *VehicleDefault) GetByColorAndYear() http.HandlerFunc {
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 
@@ -278,13 +310,14 @@ func (h *VehicleDefault) GetAverageSpeedByBrand() http.HandlerFunc {
 
 		// process
 		// calculate the average speed of the vehicles by brand
-		averageSpeed, err := h.sv.FindAverageSpeedByBrand(brand)
+		averageSpeed, err := h.sv.FindAverageSpeedByBrand(r.Context(), brand)
 		// Verify if an error occurred and return a 404 Not Found response if it did
 		if err != nil {
 			switch {
 			case errors.Is(err, internal.ErrNoVehiclesWithBrand):
 				response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos con esa marca.")
 			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
 				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
 			return
@@ -310,27 +343,27 @@ func (h *VehicleDefault) CreateBatch() http.HandlerFunc {
 
 		// process
 
-		lenVericles, err := h.sv.FindAll()
+		//increment by 1 the highest id taken in the tenant, deleted vehicles included
+		vehiclesCount, err := h.sv.FindLastId(r.Context())
 		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
 			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			return
 		}
-		//increment by 1 the last id of the vehicle
-		vehiclesCount := len(lenVericles)
 
 		// - create vehicles
 		vehicles := make([]internal.Vehicle, len(body.Vehicles))
 		for key, value := range body.Vehicles {
-			
+
 			id := vehiclesCount + key + 1
 
 			// Verificar si el ID ya existe
 			/*
-			_, err := h.sv.FindById(id)
-			if err != nil {
-				response.JSON(w, http.StatusConflict, "409 Conflict: Duplicate ID found")
-				return
-			}*/
+				_, err := h.sv.FindById(r.Context(), id)
+				if err != nil {
+					response.JSON(w, http.StatusConflict, "409 Conflict: Duplicate ID found")
+					return
+				}*/
 
 			vehicles[key] = internal.Vehicle{
 				Id: id, // Set the incremented ID
@@ -349,17 +382,19 @@ func (h *VehicleDefault) CreateBatch() http.HandlerFunc {
 						Length: value.Length,
 						Width:  value.Width,
 					},
+					Tags: internal.CleanTags(value.Tags),
 				},
 			}
 		}
 
-		if err := h.sv.CreateVehicles(vehicles); err != nil {
+		if err := h.sv.CreateVehicles(r.Context(), vehicles); err != nil {
 			switch {
 			case errors.Is(err, internal.ErrVehicleAlreadyExists):
 				response.JSON(w, http.StatusConflict, "409 Conflict")
 			case errors.Is(err, internal.ErrInvalidVehicle):
 				response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
 			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
 				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
 			return
@@ -383,6 +418,7 @@ func (h *VehicleDefault) CreateBatch() http.HandlerFunc {
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 
@@ -417,13 +453,14 @@ func (h *VehicleDefault) UpdateMaxSpeed() http.HandlerFunc {
 
 		// process
 		// - update max speed
-		if err := h.sv.UpdateSpeed(id, body.MaxSpeed); err != nil {
+		if err := h.sv.UpdateSpeed(r.Context(), id, body.MaxSpeed); err != nil {
 			switch {
 			case errors.Is(err, internal.ErrVehicleNotFound):
 				response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontró el vehículo")
 			case errors.Is(err, internal.ErrInvalidVehicle):
 				response.JSON(w, http.StatusBadRequest, "400 Bad Request: Velocidad mal formada o fuera de rango")
 			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
 				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
 			return
@@ -445,7 +482,7 @@ func (h *VehicleDefault) GetByFuelType() http.HandlerFunc {
 
 		// process
 		// - get vehicles by fuel type
-		vehicles, err := h.sv.FindByFuelType(fuelType)
+		vehicles, err := h.sv.FindByFuelType(r.Context(), fuelType)
 		if err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos con ese tipo de combustible.")
 			return
@@ -469,6 +506,7 @@ func (h *VehicleDefault) GetByFuelType() http.HandlerFunc {
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 		response.JSON(w, http.StatusOK, map[string]interface{}{
@@ -493,18 +531,21 @@ func (h *VehicleDefault) Delete() http.HandlerFunc {
 		}
 
 		// validate id in vehicle list
-		if _, err := h.sv.FindById(id); err != nil {
+		if _, err := h.sv.FindById(r.Context(), id); err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontró el vehículo")
 			return
 		}
 
 		// process
 		// - delete vehicle
-		if err := h.sv.DeleteVehicle(id); err != nil {
+		if err := h.sv.DeleteVehicle(r.Context(), id); err != nil {
 			switch {
 			case errors.Is(err, internal.ErrVehicleNotFound):
 				response.JSON(w, http.StatusNotFound, "404 Not Found")
+			case errors.Is(err, internal.ErrVehicleHasMaintenance):
+				response.JSON(w, http.StatusConflict, "409 Conflict: "+err.Error())
 			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
 				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
 			return
@@ -517,7 +558,7 @@ func (h *VehicleDefault) Delete() http.HandlerFunc {
 	}
 }
 
-// GetByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.) 
+// GetByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.)
 func (h *VehicleDefault) GetByTransmissionType() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -526,7 +567,7 @@ func (h *VehicleDefault) GetByTransmissionType() http.HandlerFunc {
 
 		// process
 		// - get vehicles by transmission type
-		vehicles, err := h.sv.FindByTransmissionType(transmissionType)
+		vehicles, err := h.sv.FindByTransmissionType(r.Context(), transmissionType)
 		if err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos con ese tipo de transmisión.")
 			return
@@ -550,6 +591,7 @@ func (h *VehicleDefault) GetByTransmissionType() http.HandlerFunc {
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 		response.JSON(w, http.StatusOK, map[string]interface{}{
@@ -583,13 +625,14 @@ func (h *VehicleDefault) UpdateFuelType() http.HandlerFunc {
 
 		// process
 		// - update fuel type
-		if err := h.sv.UpdateFuel(id, body.FuelType); err != nil {
+		if err := h.sv.UpdateFuel(r.Context(), id, body.FuelType); err != nil {
 			switch {
 			case errors.Is(err, internal.ErrVehicleNotFound):
 				response.JSON(w, http.StatusNotFound, "404 Not Found")
 			case errors.Is(err, internal.ErrInvalidVehicle):
 				response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
 			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
 				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
 			}
 			return
@@ -651,7 +694,7 @@ func (h *VehicleDefault) GetByDimensions() http.HandlerFunc {
 
 		// process
 		// - get vehicles by dimensions
-		vehicles, err := h.sv.FindByDimensions(minLengthFloat64, maxLengthFloat64, minWidthFloat64, maxWidthFloat64)
+		vehicles, err := h.sv.FindByDimensions(r.Context(), minLengthFloat64, maxLengthFloat64, minWidthFloat64, maxWidthFloat64)
 		if err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos con esas dimensiones.")
 			return
@@ -668,13 +711,14 @@ func (h *VehicleDefault) GetByDimensions() http.HandlerFunc {
 				Color:           value.Color,
 				FabricationYear: value.FabricationYear,
 				Capacity:        value.Capacity,
-				MaxSpeed:		value.MaxSpeed,
+				MaxSpeed:        value.MaxSpeed,
 				FuelType:        value.FuelType,
 				Transmission:    value.Transmission,
 				Weight:          value.Weight,
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 		response.JSON(w, http.StatusOK, map[string]interface{}{
@@ -709,7 +753,7 @@ func (h *VehicleDefault) GetByWeight() http.HandlerFunc {
 
 		// process
 		// - get vehicles by weight
-		vehicles, err := h.sv.FindByWeight(minWeightFloat64, maxWeightFloat64)
+		vehicles, err := h.sv.FindByWeight(r.Context(), minWeightFloat64, maxWeightFloat64)
 		if err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos en ese rango de peso.")
 			return
@@ -726,13 +770,14 @@ func (h *VehicleDefault) GetByWeight() http.HandlerFunc {
 				Color:           value.Color,
 				FabricationYear: value.FabricationYear,
 				Capacity:        value.Capacity,
-				MaxSpeed:		 value.MaxSpeed,
+				MaxSpeed:        value.MaxSpeed,
 				FuelType:        value.FuelType,
 				Transmission:    value.Transmission,
 				Weight:          value.Weight,
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 		response.JSON(w, http.StatusOK, map[string]interface{}{
@@ -745,7 +790,7 @@ func (h *VehicleDefault) GetByWeight() http.HandlerFunc {
 // GetByBrandAndYear is a method that returns a list of vehicles based on a brand and a range of years.
 func (h *VehicleDefault) GetByBrandAndRange() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		
+
 		// request
 		// Extract brand from the URL query parameters
 		brand := chi.URLParam(r, "brand")
@@ -768,10 +813,9 @@ func (h *VehicleDefault) GetByBrandAndRange() http.HandlerFunc {
 			return
 		}
 
-
 		// process
 		// - get vehicles by brand and year
-		vehicles, err := h.sv.FindByBrandAndYearRange(brand, startYear, endYear)
+		vehicles, err := h.sv.FindByBrandAndYearRange(r.Context(), brand, startYear, endYear)
 		if err != nil {
 			response.JSON(w, http.StatusNotFound, "404 Not Found: No se encontraron vehículos con esos criterios.")
 			return
@@ -788,13 +832,14 @@ func (h *VehicleDefault) GetByBrandAndRange() http.HandlerFunc {
 				Color:           value.Color,
 				FabricationYear: value.FabricationYear,
 				Capacity:        value.Capacity,
-				MaxSpeed:		 value.MaxSpeed,
+				MaxSpeed:        value.MaxSpeed,
 				FuelType:        value.FuelType,
 				Transmission:    value.Transmission,
 				Weight:          value.Weight,
 				Height:          value.Height,
 				Length:          value.Length,
 				Width:           value.Width,
+				Tags:            value.Tags,
 			}
 		}
 
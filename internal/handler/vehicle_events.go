@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// FieldChangeJSON is a struct that represents the change of an attribute in JSON format
+type FieldChangeJSON struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// VehicleEventJSON is a struct that represents a change of the vehicles in JSON format
+type VehicleEventJSON struct {
+	ID         uint64                     `json:"id"`
+	Type       string                     `json:"type"`
+	VehicleID  int                        `json:"vehicle_id,omitempty"`
+	Vehicle    *VehicleJSON               `json:"vehicle,omitempty"`
+	Changes    map[string]FieldChangeJSON `json:"changes,omitempty"`
+	Count      int                        `json:"count,omitempty"`
+	OccurredAt time.Time                  `json:"occurred_at"`
+}
+
+// NewVehicleEventsDefault is a function that returns a new instance of VehicleEventsDefault
+func NewVehicleEventsDefault(sb internal.VehicleEventSubscriber, heartbeat time.Duration) *VehicleEventsDefault {
+	// default heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &VehicleEventsDefault{sb: sb, heartbeat: heartbeat}
+}
+
+// VehicleEventsDefault is a struct with methods that represent handlers for the changes of the vehicles
+type VehicleEventsDefault struct {
+	// sb is the source of the changes of the vehicles
+	sb internal.VehicleEventSubscriber
+	// heartbeat is the time between two comments sent to keep an idle stream open
+	heartbeat time.Duration
+}
+
+// Stream is a method that returns a handler for the route GET /vehicles/events.
+// The changes of the tenant of the request are streamed as Server-Sent Events, filtered by the query parameters brand and fuel_type.
+// A client that reconnects with the Last-Event-ID header gets the events it missed, if they are still buffered;
+// otherwise it gets a reset event first, meaning it must read the vehicles again.
+func (h *VehicleEventsDefault) Stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		brand := r.URL.Query().Get("brand")
+		fuelType := r.URL.Query().Get("fuel_type")
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			// EventSource can not set headers on the first connection
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+		var after uint64
+		if lastEventID != "" {
+			var err error
+			if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+				response.JSON(w, http.StatusBadRequest, "400 Bad Request: Last-Event-ID must be the id of an event")
+				return
+			}
+		}
+		tenant := internal.Tenant(r.Context())
+		match := func(ev internal.VehicleEvent) bool {
+			if ev.Tenant != tenant {
+				return false
+			}
+			if ev.Type == internal.VehicleEventReplaced {
+				return true
+			}
+			return (brand == "" || ev.Vehicle.Brand == brand) && (fuelType == "" || ev.Vehicle.FuelType == fuelType)
+		}
+
+		// process
+		// - subscribe before the headers are sent, so no event is missed
+		sub, replay, complete := h.sb.Subscribe(r.Context(), after)
+		defer sub.Close()
+
+		// - the stream outlives the write timeout of the server
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		// response
+		send := func(write func(w io.Writer) error) bool {
+			if err := write(w); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+		if !complete {
+			if !send(writeResetEvent) {
+				return
+			}
+			replay = nil
+		}
+		for _, ev := range replay {
+			if match(ev) && !send(eventWriter(ev)) {
+				return
+			}
+		}
+		if !send(func(w io.Writer) (err error) {
+			_, err = io.WriteString(w, ": connected\n\n")
+			return
+		}) {
+			return
+		}
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				if !send(func(w io.Writer) (err error) {
+					_, err = io.WriteString(w, ": heartbeat\n\n")
+					return
+				}) {
+					return
+				}
+			case ev, ok := <-sub.Events():
+				if !ok {
+					// the bus disconnected a slow client, it resumes with the Last-Event-ID of the last event it got
+					if sub.Overflowed() {
+						slog.WarnContext(r.Context(), "events: slow client disconnected")
+					}
+					return
+				}
+				if match(ev) && !send(eventWriter(ev)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// eventWriter is a function that returns the writer of an event in the Server-Sent Events format
+func eventWriter(ev internal.VehicleEvent) func(w io.Writer) error {
+	return func(w io.Writer) (err error) {
+		data, err := json.Marshal(vehicleEventToJSON(ev))
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Id, ev.Type, data)
+		return
+	}
+}
+
+// writeResetEvent is a function that writes the event that tells the client to read the vehicles again
+func writeResetEvent(w io.Writer) (err error) {
+	_, err = io.WriteString(w, "event: reset\ndata: {\"message\":\"some events are no longer available, read the vehicles again\"}\n\n")
+	return
+}
+
+// vehicleEventToJSON is a function that serializes an event to its JSON format
+func vehicleEventToJSON(ev internal.VehicleEvent) (data VehicleEventJSON) {
+	data = VehicleEventJSON{
+		ID:         ev.Id,
+		Type:       ev.Type,
+		VehicleID:  ev.VehicleId,
+		Count:      ev.Count,
+		OccurredAt: ev.OccurredAt,
+	}
+	if ev.Type != internal.VehicleEventReplaced {
+		vh := vehicleToJSON(ev.Vehicle)
+		data.Vehicle = &vh
+	}
+	if len(ev.Changes) > 0 {
+		data.Changes = make(map[string]FieldChangeJSON, len(ev.Changes))
+		for name, change := range ev.Changes {
+			data.Changes[name] = FieldChangeJSON{From: change.From, To: change.To}
+		}
+	}
+	return
+}
+
+// vehicleToJSON is a function that serializes a vehicle to its JSON format
+func vehicleToJSON(v internal.Vehicle) VehicleJSON {
+	return VehicleJSON{
+		ID:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        v.MaxSpeed,
+		FuelType:        v.FuelType,
+		Transmission:    v.Transmission,
+		Weight:          v.Weight,
+		Height:          v.Height,
+		Length:          v.Length,
+		Width:           v.Width,
+		Tags:            v.Tags,
+	}
+}
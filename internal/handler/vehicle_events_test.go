@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/event"
+	"app/internal/handler"
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// openStream is a function that opens a stream of the events of the tenant, with the query and the Last-Event-ID
+// (if not empty) of the request. The stream is closed at the end of the test.
+func openStream(t *testing.T, hd *handler.VehicleEventsDefault, tenant string, query string, lastEventID string) (res *http.Response, frames *bufio.Reader) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hd.Stream()(w, r.WithContext(internal.ContextWithTenant(r.Context(), tenant)))
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		srv.Close()
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?"+query, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	frames = bufio.NewReader(res.Body)
+	return
+}
+
+// readFrame is a function that returns the next frame of a stream, without its trailing blank line
+func readFrame(t *testing.T, frames *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := frames.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// fueled is a function that returns a created event of a vehicle of a tenant, with its brand and its fuel type
+func fueled(tenant string, id int, brand string, fuelType string) internal.VehicleEvent {
+	return internal.VehicleEvent{Type: internal.VehicleEventCreated, Tenant: tenant, VehicleId: id, Vehicle: internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: brand, FuelType: fuelType}}}
+}
+
+// Tests for VehicleEventsDefault
+func TestVehicleEventsDefault_Stream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: only the events of the tenant that match the brand and the fuel type are streamed", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		res, frames := openStream(t, handler.NewVehicleEventsDefault(bus, time.Minute), "acme", "brand=Ford&fuel_type=diesel", "")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		require.Equal(t, ": connected", readFrame(t, frames))
+
+		// act
+		bus.Publish(ctx,
+			fueled("acme", 1, "Fiat", "diesel"),
+			fueled("acme", 2, "Ford", "gasoline"),
+			fueled("globex", 3, "Ford", "diesel"),
+			fueled("acme", 4, "Ford", "diesel"),
+		)
+
+		// assert
+		frame := readFrame(t, frames)
+		require.True(t, strings.HasPrefix(frame, "id: 4\nevent: created\ndata: "), frame)
+		require.Contains(t, frame, `"vehicle_id":4`)
+	})
+
+	t.Run("case 2: an idle stream gets heartbeat comments", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		_, frames := openStream(t, handler.NewVehicleEventsDefault(bus, 10*time.Millisecond), internal.TenantDefault, "", "")
+
+		// act
+		connected := readFrame(t, frames)
+		heartbeat := readFrame(t, frames)
+
+		// assert
+		require.Equal(t, ": connected", connected)
+		require.Equal(t, ": heartbeat", heartbeat)
+	})
+
+	t.Run("case 3: a client that reconnects with the Last-Event-ID gets the events it missed", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		bus.Publish(ctx, vehicle(1, "Fiat", 150), vehicle(2, "Ford", 250), vehicle(3, "BMW", 220))
+
+		// act
+		_, frames := openStream(t, handler.NewVehicleEventsDefault(bus, time.Minute), internal.TenantDefault, "", "1")
+
+		// assert
+		require.True(t, strings.HasPrefix(readFrame(t, frames), "id: 2\n"))
+		require.True(t, strings.HasPrefix(readFrame(t, frames), "id: 3\n"))
+		require.Equal(t, ": connected", readFrame(t, frames))
+	})
+
+	t.Run("case 4: a client whose missed events are no longer buffered gets a reset event", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(2, 10)
+		bus.Publish(ctx, vehicle(1, "Fiat", 150), vehicle(2, "Ford", 250), vehicle(3, "BMW", 220), vehicle(4, "Audi", 240))
+
+		// act
+		_, frames := openStream(t, handler.NewVehicleEventsDefault(bus, time.Minute), internal.TenantDefault, "", "1")
+
+		// assert
+		require.True(t, strings.HasPrefix(readFrame(t, frames), "event: reset\n"))
+		require.Equal(t, ": connected", readFrame(t, frames))
+	})
+
+	t.Run("case 5: an invalid Last-Event-ID is rejected", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+
+		// act
+		res, _ := openStream(t, handler.NewVehicleEventsDefault(bus, time.Minute), internal.TenantDefault, "", "last")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
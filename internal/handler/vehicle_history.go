@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"app/internal"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// VehicleVersionJSON is a struct that represents a version of a vehicle in JSON format
+type VehicleVersionJSON struct {
+	Version   int         `json:"version"`
+	Deleted   bool        `json:"deleted"`
+	ValidFrom time.Time   `json:"valid_from"`
+	Vehicle   VehicleJSON `json:"vehicle"`
+}
+
+// GetById is a method that returns a handler for the route GET /vehicles/{id}?as_of={time}
+func (h *VehicleDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		asOf, err := parseAsOf(r)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: as_of must be an RFC 3339 time (e.g. 2024-01-02T15:04:05Z)")
+			return
+		}
+
+		// process
+		// - get the vehicle, now or as it was at as_of
+		var v internal.Vehicle
+		if asOf.IsZero() {
+			v, err = h.sv.FindById(r.Context(), id)
+		} else {
+			v, err = h.sv.FindByIdAsOf(r.Context(), id, asOf)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrVehicleNotFound):
+				response.JSON(w, http.StatusNotFound, "404 Not Found")
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    vehicleToJSON(v),
+		})
+	}
+}
+
+// GetVersions is a method that returns a handler for the route GET /vehicles/{id}/versions
+func (h *VehicleDefault) GetVersions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		// - the versions of the vehicle, oldest first
+		versions, err := h.sv.FindVersions(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrVehicleNotFound):
+				response.JSON(w, http.StatusNotFound, "404 Not Found")
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		data := make([]VehicleVersionJSON, len(versions))
+		for key, value := range versions {
+			data[key] = VehicleVersionJSON{
+				Version:   value.Version,
+				Deleted:   value.Deleted,
+				ValidFrom: value.ValidFrom,
+				Vehicle:   vehicleToJSON(value.Vehicle),
+			}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// Revert is a method that returns a handler for the route POST /vehicles/{id}/revert?version={version}
+func (h *VehicleDefault) Revert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		version, err := strconv.Atoi(r.URL.Query().Get("version"))
+		if err != nil || version < 1 {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: version must be a positive integer")
+			return
+		}
+
+		// process
+		// - restore the version as a new change
+		v, err := h.sv.RevertVehicle(r.Context(), id, version)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrVehicleNotFound), errors.Is(err, internal.ErrVehicleVersionNotFound):
+				response.JSON(w, http.StatusNotFound, "404 Not Found: "+err.Error())
+			case errors.Is(err, internal.ErrVehicleVersionDeleted), errors.Is(err, internal.ErrVehicleAlreadyExists):
+				response.JSON(w, http.StatusConflict, "409 Conflict: "+err.Error())
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    vehicleToJSON(v),
+		})
+	}
+}
+
+// parseAsOf is a function that returns the time of the as_of query parameter, zero if there is none
+func parseAsOf(r *http.Request) (at time.Time, err error) {
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		at, err = time.Parse(time.RFC3339, value)
+	}
+	return
+}
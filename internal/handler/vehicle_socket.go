@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/websocket"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSocketMessageSize is the maximum size of a message sent by a client
+	maxSocketMessageSize = 4 << 10
+	// maxSocketSubscriptions is the maximum number of subscriptions of a connection
+	maxSocketSubscriptions = 32
+	// maxSocketSubscriptionID is the maximum length of the id of a subscription
+	maxSocketSubscriptionID = 64
+)
+
+// SocketRequestJSON is a struct that represents a message sent by a client of the WebSocket in JSON format
+type SocketRequestJSON struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	VehicleIDs []int  `json:"vehicle_ids"`
+	Filter     string `json:"filter"`
+}
+
+// SocketResponseJSON is a struct that represents a message sent to a client of the WebSocket in JSON format
+type SocketResponseJSON struct {
+	Type          string            `json:"type"`
+	ID            string            `json:"id,omitempty"`
+	Subscriptions []string          `json:"subscriptions,omitempty"`
+	Event         *VehicleEventJSON `json:"event,omitempty"`
+	Message       string            `json:"message,omitempty"`
+}
+
+// socketSubscription is a struct that represents the vehicles a client subscribed to
+type socketSubscription struct {
+	// ids are the ids of the vehicles, empty for every vehicle
+	ids []int
+	// filter is the filter of the vehicles
+	filter internal.VehicleFilter
+}
+
+// match is a method that returns true if the event concerns the subscription
+func (s socketSubscription) match(ev internal.VehicleEvent) bool {
+	if ev.Type == internal.VehicleEventReplaced {
+		return true
+	}
+	return (len(s.ids) == 0 || slices.Contains(s.ids, ev.VehicleId)) && s.filter.Match(ev.Vehicle)
+}
+
+// NewVehicleSocketDefault is a function that returns a new instance of VehicleSocketDefault
+func NewVehicleSocketDefault(sb internal.VehicleEventSubscriber, queueSize int, writeTimeout time.Duration, pingInterval time.Duration) *VehicleSocketDefault {
+	// default values
+	if queueSize <= 0 {
+		queueSize = 64
+	}
+	if writeTimeout <= 0 {
+		writeTimeout = 10 * time.Second
+	}
+	if pingInterval <= 0 {
+		pingInterval = 30 * time.Second
+	}
+	return &VehicleSocketDefault{sb: sb, queueSize: queueSize, writeTimeout: writeTimeout, pingInterval: pingInterval}
+}
+
+// VehicleSocketDefault is a struct with methods that represent handlers for the WebSocket of the changes of the vehicles
+type VehicleSocketDefault struct {
+	// sb is the source of the changes of the vehicles
+	sb internal.VehicleEventSubscriber
+	// queueSize is the number of messages queued for a client before it is disconnected as a slow consumer
+	queueSize int
+	// writeTimeout is the maximum time to write a message to a client
+	writeTimeout time.Duration
+	// pingInterval is the time between two pings, a client that does not answer two of them is disconnected
+	pingInterval time.Duration
+}
+
+// Connect is a method that returns a handler for the route GET /ws.
+// The client sends subscribe messages, with vehicle ids and/or a filter expression, and unsubscribe messages;
+// the server pushes each change of the vehicles of the tenant of the request with the ids of the subscriptions it matches.
+// A client that does not read its messages fast enough is disconnected with the close code 1008.
+func (h *VehicleSocketDefault) Connect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		c, err := websocket.Upgrade(w, r, maxSocketMessageSize)
+		if err != nil {
+			return
+		}
+		c.SetReadTimeout(2 * h.pingInterval)
+
+		// process
+		tenant := internal.Tenant(r.Context())
+		sub, _, _ := h.sb.Subscribe(r.Context(), 0)
+		defer sub.Close()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		var closeOnce sync.Once
+		closeWith := func(code int, reason string) {
+			closeOnce.Do(func() {
+				_ = c.Close(code, reason)
+				cancel()
+			})
+		}
+		defer closeWith(websocket.CloseNormal, "")
+
+		// - the messages are queued, so a slow client does not hold the bus nor the reader
+		out := make(chan SocketResponseJSON, h.queueSize)
+		enqueue := func(msg SocketResponseJSON) {
+			select {
+			case out <- msg:
+			default:
+				slog.WarnContext(ctx, "websocket: slow consumer disconnected", "remote", c.RemoteAddr().String())
+				closeWith(websocket.ClosePolicyViolation, "slow consumer")
+			}
+		}
+
+		// - writer
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(h.pingInterval)
+			defer ticker.Stop()
+			for {
+				var err error
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					err = c.Ping(time.Now().Add(h.writeTimeout))
+				case msg := <-out:
+					var data []byte
+					if data, err = json.Marshal(msg); err == nil {
+						err = c.WriteMessage(websocket.TextMessage, data, time.Now().Add(h.writeTimeout))
+					}
+				}
+				if err != nil {
+					closeWith(websocket.ClosePolicyViolation, "slow consumer")
+					return
+				}
+			}
+		}()
+
+		// - reader
+		var mu sync.Mutex
+		subscriptions := make(map[string]socketSubscription)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			for {
+				_, data, err := c.ReadMessage()
+				if err != nil {
+					return
+				}
+				var req SocketRequestJSON
+				if err := json.Unmarshal(data, &req); err != nil {
+					enqueue(SocketResponseJSON{Type: "error", Message: "the message must be a JSON object"})
+					continue
+				}
+				mu.Lock()
+				res := handleSocketRequest(req, subscriptions)
+				mu.Unlock()
+				enqueue(res)
+			}
+		}()
+
+		// - events
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-sub.Events():
+				if !ok {
+					if sub.Overflowed() {
+						closeWith(websocket.ClosePolicyViolation, "slow consumer")
+					} else {
+						closeWith(websocket.CloseGoingAway, "server shutting down")
+					}
+					return
+				}
+				if ev.Tenant != tenant {
+					continue
+				}
+				mu.Lock()
+				var ids []string
+				for id, s := range subscriptions {
+					if s.match(ev) {
+						ids = append(ids, id)
+					}
+				}
+				mu.Unlock()
+				if len(ids) == 0 {
+					continue
+				}
+				sort.Strings(ids)
+				data := vehicleEventToJSON(ev)
+				enqueue(SocketResponseJSON{Type: "event", Subscriptions: ids, Event: &data})
+			}
+		}
+	}
+}
+
+// handleSocketRequest is a function that applies a message of a client to its subscriptions and returns the answer
+func handleSocketRequest(req SocketRequestJSON, subscriptions map[string]socketSubscription) (res SocketResponseJSON) {
+	res = SocketResponseJSON{Type: "error", ID: req.ID}
+	if req.ID == "" || len(req.ID) > maxSocketSubscriptionID {
+		res.Message = fmt.Sprintf("the id of the subscription is required, with at most %d characters", maxSocketSubscriptionID)
+		return
+	}
+
+	switch req.Type {
+	case "subscribe":
+		if _, ok := subscriptions[req.ID]; !ok && len(subscriptions) >= maxSocketSubscriptions {
+			res.Message = fmt.Sprintf("at most %d subscriptions per connection", maxSocketSubscriptions)
+			return
+		}
+		filter, err := internal.ParseVehicleFilter(req.Filter)
+		if err != nil {
+			res.Message = err.Error()
+			return
+		}
+		subscriptions[req.ID] = socketSubscription{ids: req.VehicleIDs, filter: filter}
+		res.Type = "subscribed"
+	case "unsubscribe":
+		if _, ok := subscriptions[req.ID]; !ok {
+			res.Message = "unknown subscription"
+			return
+		}
+		delete(subscriptions, req.ID)
+		res.Type = "unsubscribed"
+	default:
+		res.Message = fmt.Sprintf("unknown message type %q, expected subscribe or unsubscribe", req.Type)
+	}
+	return
+}
@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/event"
+	"app/internal/handler"
+	"app/platform/websocket"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// send is a function that sends a message of the protocol to the server
+func send(t *testing.T, c *websocket.Conn, req handler.SocketRequestJSON) {
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	require.NoError(t, c.WriteMessage(websocket.TextMessage, data, time.Now().Add(time.Second)))
+}
+
+// receive is a function that returns the next message of the server
+func receive(t *testing.T, c *websocket.Conn) (res handler.SocketResponseJSON) {
+	_, data, err := c.ReadMessage()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &res))
+	return
+}
+
+// vehicle is a function that returns a created event of a vehicle
+func vehicle(id int, brand string, maxSpeed float64) internal.VehicleEvent {
+	return internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: id, Vehicle: internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: brand, MaxSpeed: maxSpeed}}}
+}
+
+// Tests for VehicleSocketDefault
+func TestVehicleSocketDefault_Connect(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: the events are pushed to the subscriptions they match until unsubscribed", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		srv := httptest.NewServer(handler.NewVehicleSocketDefault(bus, 10, time.Second, time.Minute).Connect())
+		defer srv.Close()
+		c, _, err := websocket.Dial(ctx, srv.URL, nil)
+		require.NoError(t, err)
+		defer c.Close(websocket.CloseNormal, "")
+
+		// act & assert
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "fast", Filter: "max_speed >= 200"})
+		require.Equal(t, handler.SocketResponseJSON{Type: "subscribed", ID: "fast"}, receive(t, c))
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "mine", VehicleIDs: []int{2}})
+		require.Equal(t, handler.SocketResponseJSON{Type: "subscribed", ID: "mine"}, receive(t, c))
+
+		bus.Publish(ctx, vehicle(1, "Fiat", 150), vehicle(2, "Ford", 250), vehicle(3, "BMW", 220))
+		res := receive(t, c)
+		require.Equal(t, "event", res.Type)
+		require.Equal(t, []string{"fast", "mine"}, res.Subscriptions)
+		require.Equal(t, 2, res.Event.VehicleID)
+		require.Equal(t, "Ford", res.Event.Vehicle.Brand)
+		res = receive(t, c)
+		require.Equal(t, []string{"fast"}, res.Subscriptions)
+		require.Equal(t, 3, res.Event.VehicleID)
+
+		send(t, c, handler.SocketRequestJSON{Type: "unsubscribe", ID: "fast"})
+		require.Equal(t, handler.SocketResponseJSON{Type: "unsubscribed", ID: "fast"}, receive(t, c))
+		bus.Publish(ctx, vehicle(4, "BMW", 260), vehicle(2, "Ford", 250))
+		res = receive(t, c)
+		require.Equal(t, []string{"mine"}, res.Subscriptions)
+		require.Equal(t, 2, res.Event.VehicleID)
+	})
+
+	t.Run("case 2: invalid messages get an error without closing the connection", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		srv := httptest.NewServer(handler.NewVehicleSocketDefault(bus, 10, time.Second, time.Minute).Connect())
+		defer srv.Close()
+		c, _, err := websocket.Dial(ctx, srv.URL, nil)
+		require.NoError(t, err)
+		defer c.Close(websocket.CloseNormal, "")
+
+		// act & assert
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte("hello"), time.Now().Add(time.Second)))
+		require.Equal(t, "error", receive(t, c).Type)
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "s1", Filter: "colour = red"})
+		res := receive(t, c)
+		require.Equal(t, "error", res.Type)
+		require.Contains(t, res.Message, "unknown attribute")
+		send(t, c, handler.SocketRequestJSON{Type: "unsubscribe", ID: "s1"})
+		require.Equal(t, handler.SocketResponseJSON{Type: "error", ID: "s1", Message: "unknown subscription"}, receive(t, c))
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "s1"})
+		require.Equal(t, "subscribed", receive(t, c).Type)
+	})
+
+	t.Run("case 3: a client that does not read is disconnected as a slow consumer", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 1000)
+		srv := httptest.NewServer(handler.NewVehicleSocketDefault(bus, 2, 50*time.Millisecond, time.Minute).Connect())
+		defer srv.Close()
+		c, _, err := websocket.Dial(ctx, srv.URL, nil)
+		require.NoError(t, err)
+		defer c.Close(websocket.CloseNormal, "")
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "all"})
+		require.Equal(t, "subscribed", receive(t, c).Type)
+
+		// act
+		// - large events fill the buffers of the connection while the client does not read
+		big := vehicle(1, string(make([]byte, 64<<10)), 0)
+		for ix := 0; ix < 200 && bus.Subscribers() > 0; ix++ {
+			bus.Publish(ctx, big)
+			time.Sleep(time.Millisecond)
+		}
+
+		// assert
+		// - the close frame only arrives if the connection was not stuck in a write
+		var errRead error
+		for errRead == nil {
+			_, _, errRead = c.ReadMessage()
+		}
+		var ce *websocket.CloseError
+		if errors.As(errRead, &ce) {
+			require.Equal(t, websocket.ClosePolicyViolation, ce.Code)
+		}
+		require.Eventually(t, func() bool { return bus.Subscribers() == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("case 4: the connections are closed with 1001 when the bus is closed", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		srv := httptest.NewServer(handler.NewVehicleSocketDefault(bus, 10, time.Second, time.Minute).Connect())
+		defer srv.Close()
+		c, _, err := websocket.Dial(ctx, srv.URL, nil)
+		require.NoError(t, err)
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "all"})
+		require.Equal(t, "subscribed", receive(t, c).Type)
+
+		// act
+		bus.Close()
+		_, _, err = c.ReadMessage()
+
+		// assert
+		var ce *websocket.CloseError
+		require.ErrorAs(t, err, &ce)
+		require.Equal(t, websocket.CloseGoingAway, ce.Code)
+	})
+	t.Run("case 5: the events of another tenant are not pushed", func(t *testing.T) {
+		// arrange
+		bus := event.NewVehicleBus(10, 10)
+		hd := handler.NewVehicleSocketDefault(bus, 10, time.Second, time.Minute).Connect()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hd(w, r.WithContext(internal.ContextWithTenant(r.Context(), "acme")))
+		}))
+		defer srv.Close()
+		c, _, err := websocket.Dial(ctx, srv.URL, nil)
+		require.NoError(t, err)
+		defer c.Close(websocket.CloseNormal, "")
+		send(t, c, handler.SocketRequestJSON{Type: "subscribe", ID: "all"})
+		require.Equal(t, handler.SocketResponseJSON{Type: "subscribed", ID: "all"}, receive(t, c))
+
+		// act
+		bus.Publish(internal.ContextWithTenant(ctx, "globex"), vehicle(1, "Fiat", 150))
+		bus.Publish(internal.ContextWithTenant(ctx, "acme"), vehicle(2, "Ford", 250))
+
+		// assert
+		res := receive(t, c)
+		require.Equal(t, 2, res.Event.VehicleID)
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// BodyRequestTagsJSON is a struct that represents the body of a request to add tags to a vehicle
+type BodyRequestTagsJSON struct {
+	Tags []string `json:"tags"`
+}
+
+// AddTags is a method that returns a handler for the route POST /vehicles/{id}/tags.
+// The tags the vehicle already has are kept.
+func (h *VehicleDefault) AddTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		var body BodyRequestTagsJSON
+		if err := request.JSON(r, &body); err != nil || len(body.Tags) == 0 {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: the body must have a non-empty list of tags")
+			return
+		}
+
+		// process
+		v, err := h.sv.AddTags(r.Context(), id, body.Tags)
+		if err != nil {
+			h.failTags(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    vehicleToJSON(v),
+		})
+	}
+}
+
+// RemoveTag is a method that returns a handler for the route DELETE /vehicles/{id}/tags/{tag}.
+// Removing a tag the vehicle does not have is not an error.
+func (h *VehicleDefault) RemoveTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+		tag := chi.URLParam(r, "tag")
+
+		// process
+		v, err := h.sv.RemoveTags(r.Context(), id, []string{tag})
+		if err != nil {
+			h.failTags(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    vehicleToJSON(v),
+		})
+	}
+}
+
+// GetTags is a method that returns a handler for the route GET /tags, the number of vehicles of each tag
+func (h *VehicleDefault) GetTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		counts, err := h.sv.FindTags(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    counts,
+		})
+	}
+}
+
+// failTags is a method that writes the response of an error of the service when the tags of a vehicle are changed
+func (h *VehicleDefault) failTags(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, internal.ErrVehicleNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found: vehicle not found")
+	case errors.Is(err, internal.ErrInvalidTag):
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: "+err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "error", err)
+		response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
+
+// tagQuery is a function that parses the tag and tag_match parameters of a request,
+// writing a 400 response if they are invalid
+func tagQuery(w http.ResponseWriter, r *http.Request) (tags []string, match string, ok bool) {
+	tags, err := internal.NormalizeTags(r.URL.Query()["tag"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: "+err.Error())
+		return
+	}
+	match = internal.TagMatchAll
+	switch value := r.URL.Query().Get("tag_match"); value {
+	case "", internal.TagMatchAll:
+	case internal.TagMatchAny:
+		match = value
+	default:
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: tag_match must be all or any")
+		return
+	}
+	ok = true
+	return
+}
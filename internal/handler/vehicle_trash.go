@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"app/internal"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// TrashedVehicleJSON is a struct that represents a deleted vehicle in JSON format
+type TrashedVehicleJSON struct {
+	Vehicle   VehicleJSON `json:"vehicle"`
+	DeletedAt time.Time   `json:"deleted_at"`
+	DeletedBy string      `json:"deleted_by"`
+}
+
+// GetTrash is a method that returns a handler for the route GET /vehicles/trash
+func (h *VehicleDefault) GetTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		// - the deleted vehicles, the last deleted first
+		tv, err := h.sv.FindTrash(r.Context())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			return
+		}
+
+		// response
+		data := make([]TrashedVehicleJSON, len(tv))
+		for key, value := range tv {
+			data[key] = TrashedVehicleJSON{
+				Vehicle:   vehicleToJSON(value.Vehicle),
+				DeletedAt: value.DeletedAt,
+				DeletedBy: value.DeletedBy,
+			}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// Restore is a method that returns a handler for the route POST /vehicles/{id}/restore
+func (h *VehicleDefault) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		// - restore the vehicle from the trash
+		v, err := h.sv.RestoreVehicle(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, internal.ErrVehicleNotInTrash):
+				response.JSON(w, http.StatusNotFound, "404 Not Found: the vehicle is not in the trash")
+			case errors.Is(err, internal.ErrVehicleAlreadyExists):
+				response.JSON(w, http.StatusConflict, "409 Conflict: "+err.Error())
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    vehicleToJSON(v),
+		})
+	}
+}
+
+// Purge is a method that returns a handler for the route DELETE /vehicles/trash/{id}
+func (h *VehicleDefault) Purge() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid id parameter")
+			return
+		}
+
+		// process
+		// - remove the vehicle from the trash for good
+		if err := h.sv.PurgeVehicle(r.Context(), id); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrVehicleNotInTrash):
+				response.JSON(w, http.StatusNotFound, "404 Not Found: the vehicle is not in the trash")
+			default:
+				slog.ErrorContext(r.Context(), "request failed", "error", err)
+				response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			}
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// PurgeTrash is a method that returns a handler for the route POST /vehicles/trash/purge?older_than={duration}.
+// The duration is required, 0s purges every deleted vehicle.
+func (h *VehicleDefault) PurgeTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		olderThan, err := time.ParseDuration(r.URL.Query().Get("older_than"))
+		if err != nil || olderThan < 0 {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: older_than must be a duration (e.g. 720h, 0s for every deleted vehicle)")
+			return
+		}
+
+		// process
+		// - remove the vehicles deleted before the retention for good
+		ids, err := h.sv.PurgeTrash(r.Context(), time.Now().Add(-olderThan))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err)
+			response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+			return
+		}
+
+		// response
+		if ids == nil {
+			ids = []int{}
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data": map[string]any{
+				"purged": len(ids),
+				"ids":    ids,
+			},
+		})
+	}
+}
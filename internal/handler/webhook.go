@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// WebhookSubscriptionJSON is a struct that represents a webhook subscription in JSON format.
+// The secret is only shown when the subscription is created.
+type WebhookSubscriptionJSON struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BodyRequestWebhookJSON is a struct that represents the body of a request to create or update a webhook subscription
+type BodyRequestWebhookJSON struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// WebhookAttemptJSON is a struct that represents an attempt of a webhook delivery in JSON format
+type WebhookAttemptJSON struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// WebhookDeliveryJSON is a struct that represents a webhook delivery in JSON format
+type WebhookDeliveryJSON struct {
+	ID             string               `json:"id"`
+	SubscriptionID string               `json:"subscription_id"`
+	EventID        string               `json:"event_id"`
+	EventType      string               `json:"event_type"`
+	Status         string               `json:"status"`
+	Attempts       []WebhookAttemptJSON `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
+// NewWebhookDefault is a function that returns a new instance of WebhookDefault
+func NewWebhookDefault(sv internal.WebhookService) *WebhookDefault {
+	return &WebhookDefault{sv: sv}
+}
+
+// WebhookDefault is a struct with methods that represent handlers for the webhooks
+type WebhookDefault struct {
+	// sv is the service of the webhooks
+	sv internal.WebhookService
+}
+
+// Create is a method that returns a handler for the route POST /webhooks
+func (h *WebhookDefault) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var body BodyRequestWebhookJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
+			return
+		}
+
+		// process
+		// - validate and register the subscription
+		s, err := h.sv.CreateSubscription(r.Context(), internal.WebhookSubscription{URL: body.URL, EventTypes: body.EventTypes, Secret: body.Secret})
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		// - the secret is only shown once
+		data := webhookSubscriptionToJSON(s)
+		data.Secret = s.Secret
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetAll is a method that returns a handler for the route GET /webhooks
+func (h *WebhookDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		s, err := h.sv.FindSubscriptions(r.Context())
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		data := make([]WebhookSubscriptionJSON, len(s))
+		for key, value := range s {
+			data[key] = webhookSubscriptionToJSON(value)
+		}
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// GetById is a method that returns a handler for the route GET /webhooks/{id}
+func (h *WebhookDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := chi.URLParam(r, "id")
+
+		// process
+		s, err := h.sv.FindSubscriptionById(r.Context(), id)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    webhookSubscriptionToJSON(s),
+		})
+	}
+}
+
+// Update is a method that returns a handler for the route PUT /webhooks/{id}.
+// The secret is kept unless a new one is sent.
+func (h *WebhookDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := chi.URLParam(r, "id")
+		var body BodyRequestWebhookJSON
+		if err := request.JSON(r, &body); err != nil {
+			response.JSON(w, http.StatusBadRequest, "400 Bad Request: Invalid request body")
+			return
+		}
+
+		// process
+		s, err := h.sv.UpdateSubscription(r.Context(), internal.WebhookSubscription{Id: id, URL: body.URL, EventTypes: body.EventTypes, Secret: body.Secret})
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    webhookSubscriptionToJSON(s),
+		})
+	}
+}
+
+// Delete is a method that returns a handler for the route DELETE /webhooks/{id}
+func (h *WebhookDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := chi.URLParam(r, "id")
+
+		// process
+		if err := h.sv.DeleteSubscription(r.Context(), id); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetDeliveries is a method that returns a handler for the route GET /webhooks/{id}/deliveries
+func (h *WebhookDefault) GetDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := chi.URLParam(r, "id")
+
+		// process
+		d, err := h.sv.FindDeliveries(r.Context(), id)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    webhookDeliveriesToJSON(d),
+		})
+	}
+}
+
+// GetDeadLetters is a method that returns a handler for the route GET /webhooks/dead-letters
+func (h *WebhookDefault) GetDeadLetters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// ...
+
+		// process
+		d, err := h.sv.FindDeadLetters(r.Context())
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, map[string]any{
+			"message": "success",
+			"data":    webhookDeliveriesToJSON(d),
+		})
+	}
+}
+
+// Redeliver is a method that returns a handler for the route POST /webhooks/deliveries/{id}/retry
+func (h *WebhookDefault) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id := chi.URLParam(r, "id")
+
+		// process
+		// - queue the dead letter again as a new delivery
+		d, err := h.sv.Redeliver(r.Context(), id)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+
+		// response
+		response.JSON(w, http.StatusAccepted, map[string]any{
+			"message": "success",
+			"data":    webhookDeliveryToJSON(d),
+		})
+	}
+}
+
+// fail is a method that writes the response of an error of the service
+func (h *WebhookDefault) fail(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, internal.ErrWebhookNotFound):
+		response.JSON(w, http.StatusNotFound, "404 Not Found")
+	case errors.Is(err, internal.ErrInvalidWebhook):
+		response.JSON(w, http.StatusBadRequest, "400 Bad Request: "+err.Error())
+	case errors.Is(err, internal.ErrWebhookNotDead):
+		response.JSON(w, http.StatusConflict, "409 Conflict: the delivery is not a dead letter")
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "error", err)
+		response.JSON(w, http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
+
+// webhookSubscriptionToJSON is a function that serializes a subscription to its JSON format, without the secret
+func webhookSubscriptionToJSON(s internal.WebhookSubscription) WebhookSubscriptionJSON {
+	return WebhookSubscriptionJSON{
+		ID:         s.Id,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// webhookDeliveryToJSON is a function that serializes a delivery to its JSON format
+func webhookDeliveryToJSON(d internal.WebhookDelivery) (data WebhookDeliveryJSON) {
+	data = WebhookDeliveryJSON{
+		ID:             d.Id,
+		SubscriptionID: d.SubscriptionId,
+		EventID:        d.EventId,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       make([]WebhookAttemptJSON, len(d.Attempts)),
+		CreatedAt:      d.CreatedAt,
+	}
+	for key, value := range d.Attempts {
+		data.Attempts[key] = WebhookAttemptJSON{
+			At:         value.At,
+			StatusCode: value.StatusCode,
+			Error:      value.Error,
+			DurationMs: value.Duration.Milliseconds(),
+		}
+	}
+	if !d.NextAttemptAt.IsZero() {
+		next := d.NextAttemptAt
+		data.NextAttemptAt = &next
+	}
+	return
+}
+
+// webhookDeliveriesToJSON is a function that serializes deliveries to their JSON format
+func webhookDeliveriesToJSON(d []internal.WebhookDelivery) (data []WebhookDeliveryJSON) {
+	data = make([]WebhookDeliveryJSON, len(d))
+	for key, value := range d {
+		data[key] = webhookDeliveryToJSON(value)
+	}
+	return
+}
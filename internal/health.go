@@ -0,0 +1,52 @@
+package internal
+
+import "time"
+
+const (
+	// HealthUp is the status of a component that works as expected
+	HealthUp = "up"
+	// HealthDown is the status of a component that is not able to serve
+	HealthDown = "down"
+)
+
+// ComponentHealth is a struct that represents the status of a component of the application
+type ComponentHealth struct {
+	// Status is the status of the component (up, down)
+	Status string
+	// Message describes the status when the component is down or degraded
+	Message string
+	// Details are additional values of the component (e.g. number of vehicles)
+	Details map[string]any
+}
+
+// HealthChecker is an interface that represents a component that reports its status
+type HealthChecker interface {
+	// Health is a method that returns the current status of the component
+	Health() (h ComponentHealth)
+}
+
+// DatasetVersion is a struct that identifies the dataset in use
+type DatasetVersion struct {
+	// Generation is incremented on each dataset applied, starting at 1 on the first load
+	Generation int
+	// SchemaVersion is the schema version of the records of the dataset
+	SchemaVersion int
+	// Trigger is what loaded the dataset (e.g. startup, watcher, manual)
+	Trigger string
+	// LoadedAt is the time when the dataset was applied
+	LoadedAt time.Time
+}
+
+// DatasetVersioner is an interface that represents a component that knows the dataset in use
+type DatasetVersioner interface {
+	// DatasetVersion is a method that returns the version of the dataset in use
+	DatasetVersion() (v DatasetVersion)
+}
+
+// HealthCheckerFunc is a function that implements the HealthChecker interface
+type HealthCheckerFunc func() (h ComponentHealth)
+
+// Health is a method that calls the function
+func (f HealthCheckerFunc) Health() (h ComponentHealth) {
+	return f()
+}
@@ -0,0 +1,109 @@
+package loader
+
+import (
+	"app/internal"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NewVehicleComposite is a function that returns a new instance of VehicleComposite
+func NewVehicleComposite(patterns []string, mode string, policy string) *VehicleComposite {
+	// default values
+	if mode == "" {
+		mode = internal.LoadModeLenient
+	}
+	if policy == "" {
+		policy = internal.ConflictFirstWins
+	}
+	return &VehicleComposite{
+		patterns: patterns,
+		mode:     mode,
+		policy:   policy,
+	}
+}
+
+// VehicleComposite is a struct that implements the VehicleLoader interface.
+// It loads the vehicles of several files, which can mix JSON, CSV and NDJSON, and merges them
+// in the order of the patterns with a conflict policy for duplicated ids or registrations.
+type VehicleComposite struct {
+	// patterns are the paths or globs of the files that contain the vehicles
+	patterns []string
+	// mode is the load mode (strict, lenient)
+	mode string
+	// policy is the conflict policy (first-wins, last-wins, fail)
+	policy string
+	// mu protects the report
+	mu sync.RWMutex
+	// report is the data-quality report of the last load
+	report internal.LoadReport
+}
+
+// Files is a method that returns the files matched by the patterns, in load order and without repetitions
+func (l *VehicleComposite) Files() (files []string, err error) {
+	seen := make(map[string]bool)
+	for _, pattern := range l.patterns {
+		var matches []string
+		matches, err = filepath.Glob(pattern)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", pattern, err)
+			return
+		}
+		// - a path without glob characters is kept, so a missing file fails the load
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			matches = []string{pattern}
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+			files = append(files, match)
+		}
+	}
+	return
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleComposite) Load() (v map[int]internal.Vehicle, err error) {
+	// read files
+	files, err := l.Files()
+	if err != nil {
+		return
+	}
+	if len(files) == 0 {
+		err = fmt.Errorf("%w: no files match %s", internal.ErrDatasetEmpty, strings.Join(l.patterns, ", "))
+		return
+	}
+	var records []internal.Vehicle
+	for _, file := range files {
+		var format string
+		format, err = FormatOf(file)
+		if err != nil {
+			return
+		}
+		var fileRecords []internal.Vehicle
+		fileRecords, err = readVehicleFile(file, format)
+		if err != nil {
+			return
+		}
+		records = append(records, fileRecords...)
+	}
+
+	// merge and validate vehicles
+	v, report, err := Inspect(strings.Join(files, ","), l.mode, l.policy, records)
+	l.mu.Lock()
+	l.report = report
+	l.mu.Unlock()
+	return
+}
+
+// Report is a method that returns the data-quality report of the last load
+func (l *VehicleComposite) Report() (r internal.LoadReport) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.report
+}
@@ -0,0 +1,94 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeSources writes a dataset in each format into a temporary directory and returns it
+func writeSources(t *testing.T) (dir string) {
+	dir = t.TempDir()
+	files := map[string]string{
+		"a_north.json": `[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]`,
+		"b_south.csv": "id,brand,model,registration,year,color,max_speed,fuel_type,transmission,passengers,height,width,length,weight\n" +
+			"1,Kia,Sorento,CD456,2006,Violet,160,gas,automatic,3,129.4,215.45,470,1800\n" +
+			"2,Kia,Spectra,EF789,2001,Fuscia,172,gas,manual,5,268.98,47,430,1200\n",
+		"c_east.ndjson": `{"id":3,"brand":"Audi","model":"4000s","registration":"AB123","year":1986,"color":"Aquamarine","max_speed":122,"fuel_type":"gas","transmission":"manual","passengers":6,"height":120,"width":170,"length":420,"weight":1100}` + "\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return
+}
+
+// Tests for VehicleComposite
+func TestVehicleComposite_Load(t *testing.T) {
+	t.Run("case 1: first-wins keeps the vehicles of the first sources", func(t *testing.T) {
+		// arrange
+		dir := writeSources(t)
+		ld := loader.NewVehicleComposite([]string{filepath.Join(dir, "*")}, internal.LoadModeLenient, internal.ConflictFirstWins)
+
+		// act
+		v, err := ld.Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 2)
+		require.Equal(t, "Ford", v[1].Brand)
+		require.Equal(t, filepath.Join(dir, "a_north.json"), v[1].Provenance.Source)
+		require.Equal(t, loader.FormatJSON, v[1].Provenance.Format)
+		require.Equal(t, filepath.Join(dir, "b_south.csv"), v[2].Provenance.Source)
+		require.Equal(t, loader.FormatCSV, v[2].Provenance.Format)
+		require.Equal(t, 1, v[2].Provenance.Record)
+		require.Len(t, ld.Report().Duplicates, 2)
+	})
+
+	t.Run("case 2: last-wins replaces the vehicles of the previous sources", func(t *testing.T) {
+		// arrange
+		dir := writeSources(t)
+		ld := loader.NewVehicleComposite([]string{filepath.Join(dir, "*")}, internal.LoadModeLenient, internal.ConflictLastWins)
+
+		// act
+		v, err := ld.Load()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 3)
+		require.Equal(t, "Kia", v[1].Brand)
+		require.Equal(t, loader.FormatCSV, v[1].Provenance.Format)
+		require.Equal(t, "Audi", v[3].Brand)
+		require.Equal(t, loader.FormatNDJSON, v[3].Provenance.Format)
+		require.Equal(t, 1, ld.Report().Skipped)
+	})
+
+	t.Run("case 3: fail rejects the datasets with conflicts", func(t *testing.T) {
+		// arrange
+		dir := writeSources(t)
+		ld := loader.NewVehicleComposite([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.csv")}, internal.LoadModeLenient, internal.ConflictFail)
+
+		// act
+		v, err := ld.Load()
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrDatasetConflict)
+		require.Nil(t, v)
+	})
+
+	t.Run("case 4: unknown formats fail the load", func(t *testing.T) {
+		// arrange
+		dir := writeSources(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "d_west.xml"), []byte("<vehicles/>"), 0o644))
+		ld := loader.NewVehicleComposite([]string{filepath.Join(dir, "*")}, internal.LoadModeLenient, internal.ConflictFirstWins)
+
+		// act
+		_, err := ld.Load()
+
+		// assert
+		require.ErrorIs(t, err, loader.ErrUnknownFormat)
+	})
+}
@@ -0,0 +1,241 @@
+package loader
+
+import (
+	"app/internal"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FormatJSON is the format of a file with a JSON document of vehicles (a bare array or a versioned envelope)
+	FormatJSON = "json"
+	// FormatCSV is the format of a file with a header row and one vehicle per row
+	FormatCSV = "csv"
+	// FormatNDJSON is the format of a file with one JSON vehicle per line
+	FormatNDJSON = "ndjson"
+)
+
+var (
+	// ErrUnknownFormat is returned when the format of a file can not be deduced from its extension
+	ErrUnknownFormat = errors.New("unknown dataset format")
+)
+
+// FormatOf is a function that returns the format of a file according to its extension
+func FormatOf(path string) (format string, err error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".csv":
+		format = FormatCSV
+	case ".ndjson", ".jsonl":
+		format = FormatNDJSON
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+	return
+}
+
+// toVehicle is a method that serializes a vehicle in JSON format to a vehicle
+func (vh VehicleJSON) toVehicle() internal.Vehicle {
+	return internal.Vehicle{
+		Id: vh.Id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           vh.Brand,
+			Model:           vh.Model,
+			Registration:    vh.Registration,
+			Color:           vh.Color,
+			FabricationYear: vh.FabricationYear,
+			Capacity:        vh.Capacity,
+			MaxSpeed:        vh.MaxSpeed,
+			FuelType:        vh.FuelType,
+			Transmission:    vh.Transmission,
+			Weight:          vh.Weight,
+			Dimensions: internal.Dimensions{
+				Height: vh.Height,
+				Length: vh.Length,
+				Width:  vh.Width,
+			},
+			Tags: internal.CleanTags(vh.Tags),
+		},
+	}
+}
+
+// vehicleToJSON is a function that serializes a vehicle to its JSON format
+func vehicleToJSON(vh internal.Vehicle) VehicleJSON {
+	return VehicleJSON{
+		Id:              vh.Id,
+		Brand:           vh.Brand,
+		Model:           vh.Model,
+		Registration:    vh.Registration,
+		Color:           vh.Color,
+		FabricationYear: vh.FabricationYear,
+		Capacity:        vh.Capacity,
+		MaxSpeed:        vh.MaxSpeed,
+		FuelType:        vh.FuelType,
+		Transmission:    vh.Transmission,
+		Weight:          vh.Weight,
+		Height:          vh.Height,
+		Length:          vh.Length,
+		Width:           vh.Width,
+		Tags:            vh.Tags,
+	}
+}
+
+// readVehicleFile is a function that reads the vehicles of a file in the given format.
+// Each vehicle keeps the provenance of the record it came from.
+func readVehicleFile(path string, format string) (records []internal.Vehicle, err error) {
+	// open file
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	var vehiclesJSON []VehicleJSON
+	switch format {
+	case FormatJSON:
+		vehiclesJSON, _, err = decodeVehiclesDocument(file)
+	case FormatCSV:
+		vehiclesJSON, err = decodeVehiclesCSV(file)
+	case FormatNDJSON:
+		vehiclesJSON, err = decodeVehiclesNDJSON(file)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+		return
+	}
+
+	// serialize vehicles
+	records = make([]internal.Vehicle, len(vehiclesJSON))
+	for ix, vh := range vehiclesJSON {
+		records[ix] = vh.toVehicle()
+		records[ix].Provenance = internal.Provenance{
+			Source: path,
+			Format: format,
+			Record: ix,
+		}
+	}
+	return
+}
+
+// decodeVehiclesNDJSON is a function that decodes one vehicle per line, skipping the blank lines.
+// Each line is read as schema version 1 unless it has its own schema_version field.
+func decodeVehiclesNDJSON(r io.Reader) (vehiclesJSON []VehicleJSON, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record map[string]any
+		if err = json.Unmarshal([]byte(text), &record); err != nil {
+			err = fmt.Errorf("line %d: %w", line, err)
+			return
+		}
+		version := 1
+		if v, ok := record["schema_version"].(float64); ok {
+			version = int(v)
+			delete(record, "schema_version")
+		}
+		var vh []VehicleJSON
+		if vh, err = upgradeRecords[VehicleJSON]([]map[string]any{record}, version); err != nil {
+			err = fmt.Errorf("line %d: %w", line, err)
+			return
+		}
+		vehiclesJSON = append(vehiclesJSON, vh...)
+	}
+	err = scanner.Err()
+	return
+}
+
+// decodeVehiclesCSV is a function that decodes a header row with the names of the JSON format and one vehicle per row.
+// Unknown columns are ignored and empty cells are left with their zero value. The names of any schema version are accepted.
+func decodeVehiclesCSV(r io.Reader) (vehiclesJSON []VehicleJSON, err error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	// header
+	header, err := reader.Read()
+	if err != nil {
+		return
+	}
+	for ix := range header {
+		header[ix] = strings.ToLower(strings.TrimSpace(header[ix]))
+	}
+
+	// rows
+	for line := 2; ; line++ {
+		var row []string
+		row, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		var vh VehicleJSON
+		for ix, cell := range row {
+			if ix >= len(header) || cell == "" {
+				continue
+			}
+			if err = setVehicleJSONField(&vh, header[ix], cell); err != nil {
+				err = fmt.Errorf("line %d: column %s: %w", line, header[ix], err)
+				return
+			}
+		}
+		vehiclesJSON = append(vehiclesJSON, vh)
+	}
+}
+
+// setVehicleJSONField is a function that sets the field of a vehicle in JSON format by the name of its column
+func setVehicleJSONField(vh *VehicleJSON, column string, value string) (err error) {
+	switch column {
+	case "id":
+		vh.Id, err = strconv.Atoi(value)
+	case "brand":
+		vh.Brand = value
+	case "model":
+		vh.Model = value
+	case "registration":
+		vh.Registration = value
+	case "color":
+		vh.Color = value
+	case "year":
+		vh.FabricationYear, err = strconv.Atoi(value)
+	case "capacity", "passengers":
+		vh.Capacity, err = strconv.Atoi(value)
+	case "max_speed":
+		vh.MaxSpeed, err = strconv.ParseFloat(value, 64)
+	case "fuel_type":
+		vh.FuelType = value
+	case "transmission":
+		vh.Transmission = value
+	case "weight":
+		vh.Weight, err = strconv.ParseFloat(value, 64)
+	case "height":
+		vh.Height, err = strconv.ParseFloat(value, 64)
+	case "length":
+		vh.Length, err = strconv.ParseFloat(value, 64)
+	case "width":
+		vh.Width, err = strconv.ParseFloat(value, 64)
+	case "tags":
+		vh.Tags = strings.Split(value, ";")
+	}
+	return
+}
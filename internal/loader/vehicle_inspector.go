@@ -0,0 +1,153 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Inspect is a function that runs each record through the vehicle validation and builds the data-quality report.
+// Duplicated ids or registrations are resolved with the conflict policy (first-wins, last-wins, fail).
+// In lenient mode the invalid and duplicated records are skipped, in strict mode any of them rejects the whole dataset.
+func Inspect(source string, mode string, policy string, records []internal.Vehicle) (v map[int]internal.Vehicle, report internal.LoadReport, err error) {
+	if mode != internal.LoadModeStrict {
+		mode = internal.LoadModeLenient
+	}
+	if policy != internal.ConflictLastWins && policy != internal.ConflictFail {
+		policy = internal.ConflictFirstWins
+	}
+	report = internal.LoadReport{
+		Source:         source,
+		Mode:           mode,
+		ConflictPolicy: policy,
+		Total:          len(records),
+	}
+
+	// issues and state of each record
+	issues := make([][]internal.VehicleFieldIssue, len(records))
+	skipped := make([]bool, len(records))
+	// - index of the loaded record of each id and registration
+	ids := make(map[int]int)
+	registrations := make(map[string]int)
+	// - drop is a function that unloads a record replaced by another one
+	drop := func(ix int) {
+		skipped[ix] = true
+		delete(ids, records[ix].Id)
+		if jx, ok := registrations[records[ix].Registration]; ok && jx == ix {
+			delete(registrations, records[ix].Registration)
+		}
+	}
+
+	var conflict error
+	for ix, vh := range records {
+		// validation
+		var ve *internal.VehicleValidationError
+		if e := internal.ValidateVehicle(vh); errors.As(e, &ve) {
+			skipped[ix] = true
+			issues[ix] = append(issues[ix], ve.Issues...)
+		}
+		// - optional fields are reported but do not skip the record
+		if vh.Length == 0 {
+			issues[ix] = append(issues[ix], internal.VehicleFieldIssue{
+				Field:   "length",
+				Kind:    internal.IssueMissingField,
+				Value:   vh.Length,
+				Message: "the length is missing",
+			})
+		}
+		if skipped[ix] {
+			continue
+		}
+
+		// duplicates
+		var duplicates []internal.VehicleFieldIssue
+		var holders []int
+		if jx, ok := ids[vh.Id]; ok {
+			holders = append(holders, jx)
+			duplicates = append(duplicates, internal.VehicleFieldIssue{
+				Field:   "id",
+				Kind:    internal.IssueDuplicateId,
+				Value:   vh.Id,
+				Message: fmt.Sprintf("the id %d is used by the records %d and %d", vh.Id, jx, ix),
+			})
+		}
+		if jx, ok := registrations[vh.Registration]; ok {
+			holders = append(holders, jx)
+			duplicates = append(duplicates, internal.VehicleFieldIssue{
+				Field:   "registration",
+				Kind:    internal.IssueDuplicateRegistration,
+				Value:   vh.Registration,
+				Message: fmt.Sprintf("the registration %q is used by the records %d and %d", vh.Registration, jx, ix),
+			})
+		}
+		if len(duplicates) > 0 {
+			switch policy {
+			case internal.ConflictLastWins:
+				// - the loaded records are replaced by the current one
+				for dx, jx := range holders {
+					issues[jx] = append(issues[jx], duplicates[dx])
+					drop(jx)
+				}
+			case internal.ConflictFail:
+				issues[ix] = append(issues[ix], duplicates...)
+				skipped[ix] = true
+				if conflict == nil {
+					conflict = fmt.Errorf("%w: %s", internal.ErrDatasetConflict, duplicates[0].Message)
+				}
+				continue
+			default:
+				issues[ix] = append(issues[ix], duplicates...)
+				skipped[ix] = true
+				continue
+			}
+		}
+
+		// load
+		ids[vh.Id] = ix
+		registrations[vh.Registration] = ix
+	}
+
+	// report
+	v = make(map[int]internal.Vehicle)
+	for ix, vh := range records {
+		for _, issue := range issues[ix] {
+			li := internal.LoadIssue{Index: ix, Id: vh.Id, Source: sourceOf(vh, source), VehicleFieldIssue: issue, Skipped: skipped[ix]}
+			switch issue.Kind {
+			case internal.IssueDuplicateId, internal.IssueDuplicateRegistration:
+				report.Duplicates = append(report.Duplicates, li)
+			case internal.IssueMissingField:
+				report.MissingFields = append(report.MissingFields, li)
+			default:
+				report.OutOfRange = append(report.OutOfRange, li)
+			}
+		}
+		if skipped[ix] {
+			report.Skipped++
+			continue
+		}
+		v[vh.Id] = vh
+	}
+	report.Loaded = len(v)
+	report.LoadedAt = time.Now()
+
+	switch {
+	case conflict != nil:
+		err = conflict
+	case mode == internal.LoadModeStrict && report.Skipped > 0:
+		err = fmt.Errorf("%w: %d of %d records in %s are invalid", internal.ErrDatasetRejected, report.Skipped, report.Total, source)
+	}
+	if err != nil {
+		v = nil
+		report.Loaded = 0
+	}
+	return
+}
+
+// sourceOf is a function that returns the source of a record, or the default one when the record has no provenance
+func sourceOf(vh internal.Vehicle, source string) string {
+	if vh.Provenance.Source != "" {
+		return vh.Provenance.Source
+	}
+	return source
+}
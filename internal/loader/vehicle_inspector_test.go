@@ -0,0 +1,108 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newVehicle returns a valid vehicle with the given id and registration
+func newVehicle(id int, registration string) internal.Vehicle {
+	return internal.Vehicle{
+		Id: id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           "Ford",
+			Model:           "Escape",
+			Registration:    registration,
+			Color:           "Purple",
+			FabricationYear: 2008,
+			Capacity:        5,
+			MaxSpeed:        180,
+			FuelType:        "gasoline",
+			Transmission:    "manual",
+			Weight:          1500,
+			Dimensions: internal.Dimensions{
+				Height: 170,
+				Length: 440,
+				Width:  180,
+			},
+		},
+	}
+}
+
+// Tests for Inspect
+func TestInspect(t *testing.T) {
+	t.Run("case 1: valid records are loaded without issues", func(t *testing.T) {
+		// arrange
+		records := []internal.Vehicle{newVehicle(1, "AB123"), newVehicle(2, "CD456")}
+
+		// act
+		v, report, err := loader.Inspect("test", internal.LoadModeStrict, internal.ConflictFirstWins, records)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 2)
+		require.Equal(t, 2, report.Total)
+		require.Equal(t, 2, report.Loaded)
+		require.Equal(t, 0, report.Skipped)
+		require.Empty(t, report.Duplicates)
+		require.Empty(t, report.MissingFields)
+		require.Empty(t, report.OutOfRange)
+	})
+
+	t.Run("case 2: lenient mode skips duplicated and invalid records", func(t *testing.T) {
+		// arrange
+		placeholder := newVehicle(3, "000")
+		implausible := newVehicle(4, "EF789")
+		implausible.Height = 9.03
+		noLength := newVehicle(5, "GH012")
+		noLength.Length = 0
+		records := []internal.Vehicle{
+			newVehicle(1, "AB123"),
+			newVehicle(1, "XY999"),
+			newVehicle(2, "AB123"),
+			placeholder,
+			implausible,
+			noLength,
+		}
+
+		// act
+		v, report, err := loader.Inspect("test", internal.LoadModeLenient, internal.ConflictFirstWins, records)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 5}, []int{v[1].Id, v[5].Id})
+		require.Equal(t, "AB123", v[1].Registration)
+		require.Equal(t, 6, report.Total)
+		require.Equal(t, 2, report.Loaded)
+		require.Equal(t, 4, report.Skipped)
+		require.Len(t, report.Duplicates, 2)
+		require.Equal(t, internal.IssueDuplicateId, report.Duplicates[0].Kind)
+		require.Equal(t, internal.IssueDuplicateRegistration, report.Duplicates[1].Kind)
+		require.Len(t, report.OutOfRange, 2)
+		require.Equal(t, internal.IssuePlaceholder, report.OutOfRange[0].Kind)
+		require.Equal(t, "height", report.OutOfRange[1].Field)
+		require.Len(t, report.MissingFields, 1)
+		require.Equal(t, "length", report.MissingFields[0].Field)
+		require.False(t, report.MissingFields[0].Skipped)
+	})
+
+	t.Run("case 3: strict mode rejects the dataset", func(t *testing.T) {
+		// arrange
+		invalid := newVehicle(2, "CD456")
+		invalid.Brand = ""
+		records := []internal.Vehicle{newVehicle(1, "AB123"), invalid}
+
+		// act
+		v, report, err := loader.Inspect("test", internal.LoadModeStrict, internal.ConflictFirstWins, records)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrDatasetRejected)
+		require.Nil(t, v)
+		require.Equal(t, 1, report.Skipped)
+		require.Len(t, report.MissingFields, 1)
+		require.Equal(t, "brand", report.MissingFields[0].Field)
+	})
+}
@@ -0,0 +1,145 @@
+package loader
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewVehicleReloader is a function that returns a new instance of VehicleReloader
+func NewVehicleReloader(ld internal.VehicleLoader, rp internal.VehicleRepository) *VehicleReloader {
+	return &VehicleReloader{ld: ld, rp: rp}
+}
+
+// VehicleReloader is a struct that implements the VehicleReloader interface.
+// It loads the dataset again and atomically swaps the vehicles of the repository,
+// keeping the previous dataset when the new one fails validation.
+type VehicleReloader struct {
+	// ld is the loader of the dataset
+	ld internal.VehicleLoader
+	// rp is the repository whose vehicles are replaced
+	rp internal.VehicleRepository
+	// mu serializes the reloads
+	mu sync.Mutex
+	// reloading is true while a reload is in progress
+	reloading atomic.Bool
+	// muState protects the state of the dataset in use, so it can be read during a reload
+	muState sync.RWMutex
+	// loaded is the number of vehicles of the dataset in use
+	loaded int
+	// version is the version of the dataset in use
+	version internal.DatasetVersion
+	// last is the result of the last reload
+	last internal.ReloadResult
+	// observers are called with the result of each reload
+	observers []func(res internal.ReloadResult)
+}
+
+// OnReload is a method that registers a function called with the result of each reload (e.g. to count the outcomes).
+// It must be called before the first reload.
+func (r *VehicleReloader) OnReload(fn func(res internal.ReloadResult)) {
+	r.observers = append(r.observers, fn)
+}
+
+// Reload is a method that loads the dataset again and replaces the vehicles of the repository
+func (r *VehicleReloader) Reload(trigger string) (res internal.ReloadResult, err error) {
+	return r.ReloadWith(r.ld, trigger)
+}
+
+// ReloadWith is a method that replaces the vehicles of the repository with the dataset of another loader
+// (e.g. the vehicles persisted by a previous run), tracking it as the dataset in use
+func (r *VehicleReloader) ReloadWith(ld internal.VehicleLoader, trigger string) (res internal.ReloadResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloading.Store(true)
+	defer r.reloading.Store(false)
+
+	res = internal.ReloadResult{
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+	}
+
+	// load and validate the new dataset
+	v, err := ld.Load()
+	if err == nil && len(v) == 0 {
+		err = internal.ErrDatasetEmpty
+	}
+	// - swap the vehicles of the repository
+	if err == nil {
+		err = r.rp.ReplaceAll(context.Background(), v)
+	}
+
+	defer func() {
+		for _, fn := range r.observers {
+			fn(res)
+		}
+	}()
+	r.muState.Lock()
+	defer r.muState.Unlock()
+	if err != nil {
+		// rollback: the repository keeps the previous dataset
+		res.Outcome = internal.ReloadRolledBack
+		res.Loaded = r.loaded
+		res.Error = err.Error()
+		res.FinishedAt = time.Now()
+		r.last = res
+		return
+	}
+
+	r.loaded = len(v)
+	res.Outcome = internal.ReloadApplied
+	res.Loaded = r.loaded
+	res.FinishedAt = time.Now()
+	r.last = res
+	r.version = internal.DatasetVersion{
+		Generation:    r.version.Generation + 1,
+		SchemaVersion: CurrentSchemaVersion,
+		Trigger:       trigger,
+		LoadedAt:      res.FinishedAt,
+	}
+	return
+}
+
+// DatasetVersion is a method that returns the version of the dataset in use
+func (r *VehicleReloader) DatasetVersion() (v internal.DatasetVersion) {
+	r.muState.RLock()
+	defer r.muState.RUnlock()
+	v = r.version
+	return
+}
+
+// Health is a method that returns the status of the loader.
+// It is down until the first dataset is applied and while a reload is in progress.
+// A rolled back reload does not take it down, since the previous dataset is still served.
+func (r *VehicleReloader) Health() (h internal.ComponentHealth) {
+	r.muState.RLock()
+	defer r.muState.RUnlock()
+
+	h = internal.ComponentHealth{
+		Status: internal.HealthUp,
+		Details: map[string]any{
+			"loaded":     r.loaded,
+			"generation": r.version.Generation,
+		},
+	}
+	if r.last.Outcome != "" {
+		h.Details["last_outcome"] = r.last.Outcome
+		h.Details["last_trigger"] = r.last.Trigger
+	}
+	switch {
+	case r.reloading.Load():
+		h.Status = internal.HealthDown
+		h.Message = "reloading the dataset"
+	case r.version.Generation == 0:
+		h.Status = internal.HealthDown
+		h.Message = "the dataset is not loaded yet"
+		if r.last.Error != "" {
+			h.Message += ": " + r.last.Error
+		}
+	case r.last.Outcome == internal.ReloadRolledBack:
+		h.Message = "the last reload was rolled back: " + r.last.Error
+	}
+	return
+}
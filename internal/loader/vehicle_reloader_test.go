@@ -0,0 +1,148 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/repository"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleReloader
+func TestVehicleReloader_Reload(t *testing.T) {
+	valid := `[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]`
+	invalid := `[{"id":2,"brand":"","model":"Escape","registration":"CD456","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]`
+
+	t.Run("case 1: the new dataset replaces the vehicles of the repository", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		require.NoError(t, os.WriteFile(path, []byte(valid), 0o644))
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{9: {Id: 9}})
+		rl := loader.NewVehicleReloader(loader.NewVehicleJSONFile(path, internal.LoadModeStrict), rp)
+
+		// act
+		res, err := rl.Reload("manual")
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.ReloadApplied, res.Outcome)
+		require.Equal(t, 1, res.Loaded)
+		v, err := rp.FindAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		require.Equal(t, "AB123", v[1].Registration)
+	})
+
+	t.Run("case 2: the previous dataset is kept when the new one fails validation", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		require.NoError(t, os.WriteFile(path, []byte(valid), 0o644))
+		rp := repository.NewVehicleMap(nil)
+		rl := loader.NewVehicleReloader(loader.NewVehicleJSONFile(path, internal.LoadModeStrict), rp)
+		_, err := rl.Reload("startup")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(invalid), 0o644))
+
+		// act
+		res, err := rl.Reload("watcher")
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrDatasetRejected)
+		require.Equal(t, internal.ReloadRolledBack, res.Outcome)
+		require.Equal(t, 1, res.Loaded)
+		v, err := rp.FindAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		require.Equal(t, "AB123", v[1].Registration)
+	})
+}
+
+// Tests for VehicleReloader.Health
+func TestVehicleReloader_Health(t *testing.T) {
+	valid := `[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]`
+
+	t.Run("case 1: the loader is down until the first dataset is applied", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		rl := loader.NewVehicleReloader(loader.NewVehicleJSONFile(path, internal.LoadModeStrict), repository.NewVehicleMap(nil))
+
+		// act
+		hNotLoaded := rl.Health()
+		_, errMissing := rl.Reload("startup")
+		hFailed := rl.Health()
+		require.NoError(t, os.WriteFile(path, []byte(valid), 0o644))
+		_, errLoaded := rl.Reload("manual")
+		hLoaded := rl.Health()
+
+		// assert
+		require.Equal(t, internal.HealthDown, hNotLoaded.Status)
+		require.Error(t, errMissing)
+		require.Equal(t, internal.HealthDown, hFailed.Status)
+		require.Contains(t, hFailed.Message, "not loaded yet")
+		require.NoError(t, errLoaded)
+		require.Equal(t, internal.HealthUp, hLoaded.Status)
+		require.Equal(t, 1, rl.DatasetVersion().Generation)
+		require.Equal(t, "manual", rl.DatasetVersion().Trigger)
+	})
+
+	t.Run("case 2: a rolled back reload keeps the loader up and the dataset version", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		require.NoError(t, os.WriteFile(path, []byte(valid), 0o644))
+		rl := loader.NewVehicleReloader(loader.NewVehicleJSONFile(path, internal.LoadModeStrict), repository.NewVehicleMap(nil))
+		_, err := rl.Reload("startup")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o644))
+
+		// act
+		_, err = rl.Reload("watcher")
+		h := rl.Health()
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrDatasetEmpty)
+		require.Equal(t, internal.HealthUp, h.Status)
+		require.Contains(t, h.Message, "rolled back")
+		require.Equal(t, 1, rl.DatasetVersion().Generation)
+	})
+
+	t.Run("case 3: the loader is down while a reload is in progress", func(t *testing.T) {
+		// arrange
+		ld := &blockingLoader{started: make(chan struct{}), release: make(chan struct{})}
+		rl := loader.NewVehicleReloader(ld, repository.NewVehicleMap(nil))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = rl.Reload("manual")
+		}()
+		<-ld.started
+
+		// act
+		hReloading := rl.Health()
+		close(ld.release)
+		<-done
+		hDone := rl.Health()
+
+		// assert
+		require.Equal(t, internal.HealthDown, hReloading.Status)
+		require.Equal(t, "reloading the dataset", hReloading.Message)
+		require.Equal(t, internal.HealthUp, hDone.Status)
+	})
+}
+
+// blockingLoader is a loader that blocks until it is released
+type blockingLoader struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+// Load is a method that returns a single vehicle once released
+func (l *blockingLoader) Load() (v map[int]internal.Vehicle, err error) {
+	close(l.started)
+	<-l.release
+	v = map[int]internal.Vehicle{1: newVehicle(1, "AB123")}
+	return
+}
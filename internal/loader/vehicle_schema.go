@@ -0,0 +1,192 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// CurrentSchemaVersion is the version of the JSON format of the vehicles written and read by the service
+const CurrentSchemaVersion = 2
+
+var (
+	// ErrSchemaVersionUnsupported is returned when a document has a schema version that can not be upgraded
+	ErrSchemaVersionUnsupported = errors.New("unsupported schema version")
+	// ErrVehiclesMissing is returned when a versioned document has no vehicles array
+	ErrVehiclesMissing = errors.New("document without vehicles array")
+)
+
+// Migration is a struct that represents a step that upgrades a record of a schema version to the next one
+type Migration struct {
+	// From is the version of the records the step upgrades, they end in version From+1
+	From int
+	// Description is a short description of the step
+	Description string
+	// Up is the function that upgrades the record in place
+	Up func(record map[string]any) (err error)
+}
+
+// migrations is the registry of the migrations by the version they upgrade
+var migrations = make(map[int]Migration)
+
+// RegisterMigration is a function that adds a migration to the registry.
+// It panics if there is already a migration for the same version.
+func RegisterMigration(m Migration) {
+	if _, ok := migrations[m.From]; ok {
+		panic(fmt.Sprintf("loader: migration from version %d already registered", m.From))
+	}
+	migrations[m.From] = m
+}
+
+// Migrations is a function that returns the registered migrations sorted by version
+func Migrations() (ms []Migration) {
+	for _, m := range migrations {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].From < ms[j].From })
+	return
+}
+
+// checkSchemaVersion is a function that returns an error if a schema version can not be upgraded to the current one
+func checkSchemaVersion(version int) (err error) {
+	if version < 1 || version > CurrentSchemaVersion {
+		err = fmt.Errorf("%w: %d", ErrSchemaVersionUnsupported, version)
+	}
+	return
+}
+
+// Migrate is a function that upgrades a record from a schema version to the current one
+func Migrate(record map[string]any, from int) (err error) {
+	if err = checkSchemaVersion(from); err != nil {
+		return
+	}
+	for version := from; version < CurrentSchemaVersion; version++ {
+		m, ok := migrations[version]
+		if !ok {
+			err = fmt.Errorf("%w: no migration from version %d", ErrSchemaVersionUnsupported, version)
+			return
+		}
+		if err = m.Up(record); err != nil {
+			err = fmt.Errorf("migration from version %d (%s): %w", version, m.Description, err)
+			return
+		}
+	}
+	return
+}
+
+// RenameField is a function that returns a migration step that renames a field of the record
+func RenameField(from, to string) func(record map[string]any) (err error) {
+	return func(record map[string]any) (err error) {
+		value, ok := record[from]
+		if !ok {
+			return
+		}
+		if _, ok := record[to]; ok {
+			err = fmt.Errorf("the fields %s and %s are both set", from, to)
+			return
+		}
+		delete(record, from)
+		record[to] = value
+		return
+	}
+}
+
+// ScaleField is a function that returns a migration step that converts the unit of a numeric field of the record
+func ScaleField(field string, factor float64) func(record map[string]any) (err error) {
+	return func(record map[string]any) (err error) {
+		value, ok := record[field]
+		if !ok || value == nil {
+			return
+		}
+		number, ok := value.(float64)
+		if !ok {
+			err = fmt.Errorf("the field %s is not a number", field)
+			return
+		}
+		record[field] = number * factor
+		return
+	}
+}
+
+func init() {
+	// version 1 -> 2: the number of passengers is the capacity of the vehicle
+	RegisterMigration(Migration{
+		From:        1,
+		Description: "rename passengers to capacity",
+		Up:          RenameField("passengers", "capacity"),
+	})
+}
+
+// decodeVehiclesDocument is a function that decodes a document of any supported schema version
+// and upgrades its vehicles to the current one. The document is either the versioned envelope
+// {"schema_version":N,"vehicles":[...]} or, for version 1, a bare array of vehicles.
+func decodeVehiclesDocument(r io.Reader) (vehiclesJSON []VehicleJSON, version int, err error) {
+	records, version, err := decodeRecords(r)
+	if err != nil {
+		return
+	}
+
+	// upgrade and decode the vehicles
+	vehiclesJSON, err = upgradeRecords[VehicleJSON](records, version)
+	return
+}
+
+// decodeRecords is a function that decodes the records of a document of any supported schema version, as they are.
+// The schema version is checked once for the whole document, an envelope without it is refused.
+func decodeRecords(r io.Reader) (records []map[string]any, version int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	// envelope
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		version = 1
+		err = json.Unmarshal(data, &records)
+		return
+	}
+	var document struct {
+		SchemaVersion *int             `json:"schema_version"`
+		Vehicles      []map[string]any `json:"vehicles"`
+	}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return
+	}
+	if document.SchemaVersion == nil {
+		err = fmt.Errorf("%w: the document has no schema_version", ErrSchemaVersionUnsupported)
+		return
+	}
+	if err = checkSchemaVersion(*document.SchemaVersion); err != nil {
+		return
+	}
+	if document.Vehicles == nil {
+		err = ErrVehiclesMissing
+		return
+	}
+	version, records = *document.SchemaVersion, document.Vehicles
+	return
+}
+
+// upgradeRecords is a function that upgrades the records from a schema version and decodes them
+func upgradeRecords[T any](records []map[string]any, version int) (items []T, err error) {
+	items = make([]T, len(records))
+	for ix, record := range records {
+		if err = Migrate(record, version); err != nil {
+			err = fmt.Errorf("record %d: %w", ix, err)
+			return
+		}
+		var data []byte
+		if data, err = json.Marshal(record); err != nil {
+			return
+		}
+		if err = json.Unmarshal(data, &items[ix]); err != nil {
+			err = fmt.Errorf("record %d: %w", ix, err)
+			return
+		}
+	}
+	return
+}
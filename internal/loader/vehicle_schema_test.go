@@ -0,0 +1,155 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for each registered migration step
+func TestMigrations(t *testing.T) {
+	t.Run("case 1: every version below the current one has a migration", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		ms := loader.Migrations()
+
+		// assert
+		require.Len(t, ms, loader.CurrentSchemaVersion-1)
+		for ix, m := range ms {
+			require.Equal(t, ix+1, m.From)
+		}
+	})
+
+	t.Run("case 2: version 1 -> 2 renames passengers to capacity", func(t *testing.T) {
+		// arrange
+		m := loader.Migrations()[0]
+		record := map[string]any{"id": 1.0, "passengers": 4.0}
+
+		// act
+		err := m.Up(record)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"id": 1.0, "capacity": 4.0}, record)
+	})
+
+	t.Run("case 3: version 1 -> 2 fails when both fields are set", func(t *testing.T) {
+		// arrange
+		m := loader.Migrations()[0]
+		record := map[string]any{"passengers": 4.0, "capacity": 5.0}
+
+		// act
+		err := m.Up(record)
+
+		// assert
+		require.EqualError(t, err, "the fields passengers and capacity are both set")
+	})
+}
+
+// Tests for the migration helpers
+func TestScaleField(t *testing.T) {
+	t.Run("case 1: converts the unit of the field", func(t *testing.T) {
+		// arrange
+		up := loader.ScaleField("height", 100)
+		record := map[string]any{"height": 1.5}
+
+		// act
+		err := up(record)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"height": 150.0}, record)
+	})
+
+	t.Run("case 2: fails when the field is not a number", func(t *testing.T) {
+		// arrange
+		up := loader.ScaleField("height", 100)
+		record := map[string]any{"height": "tall"}
+
+		// act
+		err := up(record)
+
+		// assert
+		require.EqualError(t, err, "the field height is not a number")
+	})
+}
+
+// Tests for Migrate
+func TestMigrate(t *testing.T) {
+	t.Run("case 1: the current version is not changed", func(t *testing.T) {
+		// arrange
+		record := map[string]any{"capacity": 4.0}
+
+		// act
+		err := loader.Migrate(record, loader.CurrentSchemaVersion)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"capacity": 4.0}, record)
+	})
+
+	t.Run("case 2: unsupported versions fail", func(t *testing.T) {
+		// arrange
+		record := map[string]any{}
+
+		// act
+		errOld := loader.Migrate(record, 0)
+		errNew := loader.Migrate(record, loader.CurrentSchemaVersion+1)
+
+		// assert
+		require.ErrorIs(t, errOld, loader.ErrSchemaVersionUnsupported)
+		require.ErrorIs(t, errNew, loader.ErrSchemaVersionUnsupported)
+	})
+}
+
+// Tests for the versions of the JSON format read by VehicleJSONFile
+func TestVehicleJSONFile_SchemaVersions(t *testing.T) {
+	documents := map[string]string{
+		"bare array (v1)": `[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]`,
+		"envelope v1":     `{"schema_version":1,"vehicles":[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","passengers":5,"height":170,"width":180,"length":440,"weight":1500}]}`,
+		"envelope v2":     `{"schema_version":2,"vehicles":[{"id":1,"brand":"Ford","model":"Escape","registration":"AB123","year":2008,"color":"Purple","max_speed":180,"fuel_type":"gasoline","transmission":"manual","capacity":5,"height":170,"width":180,"length":440,"weight":1500}]}`,
+	}
+	for name, document := range documents {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			path := filepath.Join(t.TempDir(), "vehicles.json")
+			require.NoError(t, os.WriteFile(path, []byte(document), 0o644))
+			ld := loader.NewVehicleJSONFile(path, internal.LoadModeStrict)
+
+			// act
+			v, err := ld.Load()
+
+			// assert
+			require.NoError(t, err)
+			require.Len(t, v, 1)
+			require.Equal(t, 5, v[1].Capacity)
+		})
+	}
+
+	unsupported := map[string]string{
+		"unsupported version":             `{"schema_version":99,"vehicles":[{"id":1}]}`,
+		"unsupported version, no records": `{"schema_version":99,"vehicles":[]}`,
+		"version 0":                       `{"schema_version":0,"vehicles":[]}`,
+		"envelope without version":        `{"vehicles":[]}`,
+	}
+	for name, document := range unsupported {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			path := filepath.Join(t.TempDir(), "vehicles.json")
+			require.NoError(t, os.WriteFile(path, []byte(document), 0o644))
+			ld := loader.NewVehicleJSONFile(path, internal.LoadModeStrict)
+
+			// act
+			_, err := ld.Load()
+
+			// assert
+			require.ErrorIs(t, err, loader.ErrSchemaVersionUnsupported)
+		})
+	}
+}
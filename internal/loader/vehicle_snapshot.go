@@ -0,0 +1,105 @@
+package loader
+
+import (
+	"app/internal"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// NewVehicleSnapshot is a function that returns a new instance of VehicleSnapshot
+func NewVehicleSnapshot(rp internal.VehicleRepository) *VehicleSnapshot {
+	return &VehicleSnapshot{rp: rp}
+}
+
+// VehicleSnapshot is a struct that implements the VehicleSnapshotter interface.
+// Snapshots are written in the JSON format of the current schema version, so they can also be used as a dataset.
+type VehicleSnapshot struct {
+	// rp is the repository of the vehicles
+	rp internal.VehicleRepository
+}
+
+// Snapshot is a method that writes a point-in-time copy of the vehicles.
+// The copy is taken at once by the repository, so writes done while streaming are not part of it.
+func (s *VehicleSnapshot) Snapshot(ctx context.Context, w io.Writer) (n int, err error) {
+	v, err := s.rp.FindAll(ctx)
+	if err != nil {
+		return
+	}
+	err = WriteVehiclesDocument(w, v)
+	n = len(v)
+	return
+}
+
+// Restore is a method that replaces the vehicles with the ones of a snapshot.
+// The snapshot is validated in strict mode, so the vehicles are only replaced if every record is valid,
+// and a snapshot without vehicles is refused unless allowEmpty is set.
+func (s *VehicleSnapshot) Restore(ctx context.Context, r io.Reader, allowEmpty bool) (report internal.LoadReport, err error) {
+	// decode snapshot
+	records, version, err := decodeRecords(r)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrDatasetMalformed, err)
+		return
+	}
+	vehiclesJSON, err := upgradeRecords[VehicleJSON](records, version)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrDatasetRejected, err)
+		return
+	}
+	if len(vehiclesJSON) == 0 && !allowEmpty {
+		err = fmt.Errorf("%w: the snapshot has no vehicles", internal.ErrDatasetEmpty)
+		return
+	}
+	vehicles := make([]internal.Vehicle, len(vehiclesJSON))
+	for ix, vh := range vehiclesJSON {
+		vehicles[ix] = vh.toVehicle()
+		vehicles[ix].Provenance = internal.Provenance{Source: "snapshot", Format: FormatJSON, Record: ix}
+	}
+
+	// validate vehicles
+	v, report, err := Inspect("snapshot", internal.LoadModeStrict, internal.ConflictFail, vehicles)
+	if err != nil {
+		return
+	}
+
+	// replace vehicles
+	err = s.rp.ReplaceAll(ctx, v)
+	return
+}
+
+// WriteVehiclesDocument is a function that streams the vehicles sorted by id in the JSON format of the current schema version
+func WriteVehiclesDocument(w io.Writer, v map[int]internal.Vehicle) (err error) {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	bw := bufio.NewWriter(w)
+	if _, err = fmt.Fprintf(bw, `{"schema_version":%d,"vehicles":[`, CurrentSchemaVersion); err != nil {
+		return
+	}
+	for ix, id := range ids {
+		if ix > 0 {
+			if err = bw.WriteByte(','); err != nil {
+				return
+			}
+		}
+		var data []byte
+		data, err = json.Marshal(vehicleToJSON(v[id]))
+		if err != nil {
+			return
+		}
+		if _, err = bw.Write(data); err != nil {
+			return
+		}
+	}
+	if _, err = bw.WriteString("]}\n"); err != nil {
+		return
+	}
+	err = bw.Flush()
+	return
+}
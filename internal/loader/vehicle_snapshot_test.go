@@ -0,0 +1,144 @@
+package loader_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleSnapshot
+func TestVehicleSnapshot(t *testing.T) {
+	t.Run("case 1: a snapshot restores the same vehicles", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: newVehicle(1, "AB123"), 2: newVehicle(2, "CD456")})
+		sn := loader.NewVehicleSnapshot(rp)
+		var buf bytes.Buffer
+		n, err := sn.Snapshot(context.Background(), &buf)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.True(t, strings.HasPrefix(buf.String(), `{"schema_version":2,"vehicles":[{"id":1,`))
+		rpRestored := repository.NewVehicleMap(nil)
+
+		// act
+		report, err := loader.NewVehicleSnapshot(rpRestored).Restore(context.Background(), &buf, false)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Loaded)
+		expected, _ := rp.FindAll(context.Background())
+		v, _ := rpRestored.FindAll(context.Background())
+		for id := range v {
+			vh := v[id]
+			vh.Provenance = internal.Provenance{}
+			v[id] = vh
+		}
+		require.Equal(t, expected, v)
+	})
+
+	t.Run("case 2: an invalid snapshot does not replace the vehicles", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: newVehicle(1, "AB123")})
+		sn := loader.NewVehicleSnapshot(rp)
+		snapshot := `{"schema_version":2,"vehicles":[{"id":7,"brand":"","registration":"ZZ999"}]}`
+
+		// act
+		report, err := sn.Restore(context.Background(), strings.NewReader(snapshot), false)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrDatasetRejected)
+		require.Equal(t, 1, report.Skipped)
+		v, _ := rp.FindAll(context.Background())
+		require.Len(t, v, 1)
+		require.Equal(t, "AB123", v[1].Registration)
+	})
+
+	t.Run("case 3: a snapshot is consistent while vehicles are written", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(nil)
+		sn := loader.NewVehicleSnapshot(rp)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for id := 1; id <= 500; id++ {
+				_ = rp.CreateVehicle(context.Background(), newVehicle(id, fmt.Sprintf("R%d", id)))
+			}
+		}()
+
+		// act
+		var buf bytes.Buffer
+		n, err := sn.Snapshot(context.Background(), &buf)
+		<-done
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, n, strings.Count(buf.String(), `"id":`))
+	})
+	t.Run("case 4: a document without vehicles is malformed and does not replace the vehicles", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: newVehicle(1, "AB123")})
+		sn := loader.NewVehicleSnapshot(rp)
+
+		// act
+		_, errEmpty := sn.Restore(context.Background(), strings.NewReader(`{}`), true)
+		_, errUnknown := sn.Restore(context.Background(), strings.NewReader(`{"foo":1}`), true)
+
+		// assert
+		require.ErrorIs(t, errEmpty, internal.ErrDatasetMalformed)
+		require.ErrorIs(t, errUnknown, internal.ErrDatasetMalformed)
+		v, _ := rp.FindAll(context.Background())
+		require.Len(t, v, 1)
+	})
+
+	t.Run("case 5: an empty snapshot is only restored when it is allowed", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: newVehicle(1, "AB123")})
+		sn := loader.NewVehicleSnapshot(rp)
+		snapshot := `{"schema_version":2,"vehicles":[]}`
+
+		// act
+		_, errRefused := sn.Restore(context.Background(), strings.NewReader(snapshot), false)
+		vRefused, _ := rp.FindAll(context.Background())
+		_, errAllowed := sn.Restore(context.Background(), strings.NewReader(snapshot), true)
+		vAllowed, _ := rp.FindAll(context.Background())
+
+		// assert
+		require.ErrorIs(t, errRefused, internal.ErrDatasetEmpty)
+		require.Len(t, vRefused, 1)
+		require.NoError(t, errAllowed)
+		require.Empty(t, vAllowed)
+	})
+	t.Run("case 6: a snapshot of updated vehicles is restored, out of range updates are refused", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: newVehicle(1, "AB123")})
+		sv := service.NewVehicleDefault(rp, nil, repository.NewVehicleTrashMap())
+		errRange := sv.UpdateSpeed(ctx, 1, 9999)
+		errEmpty := sv.UpdateFuel(ctx, 1, "")
+		require.NoError(t, sv.UpdateSpeed(ctx, 1, 250))
+		require.NoError(t, sv.UpdateFuel(ctx, 1, "diesel"))
+		var buf bytes.Buffer
+		_, err := loader.NewVehicleSnapshot(rp).Snapshot(ctx, &buf)
+		require.NoError(t, err)
+		rpRestored := repository.NewVehicleMap(nil)
+
+		// act
+		report, err := loader.NewVehicleSnapshot(rpRestored).Restore(ctx, &buf, false)
+
+		// assert
+		require.ErrorIs(t, errRange, internal.ErrInvalidVehicle)
+		require.ErrorIs(t, errEmpty, internal.ErrInvalidVehicle)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Loaded)
+		v, _ := rpRestored.FindById(ctx, 1)
+		require.Equal(t, 250.0, v.MaxSpeed)
+		require.Equal(t, "diesel", v.FuelType)
+	})
+}
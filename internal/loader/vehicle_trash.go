@@ -0,0 +1,63 @@
+package loader
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// TrashedVehicleJSON is a struct that represents a deleted vehicle in JSON format (current schema version)
+type TrashedVehicleJSON struct {
+	VehicleJSON
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+}
+
+// WriteTrashDocument is a function that writes the deleted vehicles in the JSON format of the current schema version,
+// in the same envelope as the vehicles so the trash goes through the same migrations
+func WriteTrashDocument(w io.Writer, tv []internal.TrashedVehicle) (err error) {
+	var document struct {
+		SchemaVersion int                  `json:"schema_version"`
+		Vehicles      []TrashedVehicleJSON `json:"vehicles"`
+	}
+	document.SchemaVersion = CurrentSchemaVersion
+	document.Vehicles = make([]TrashedVehicleJSON, len(tv))
+	for ix, value := range tv {
+		document.Vehicles[ix] = TrashedVehicleJSON{
+			VehicleJSON: vehicleToJSON(value.Vehicle),
+			DeletedAt:   value.DeletedAt,
+			DeletedBy:   value.DeletedBy,
+		}
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return
+	}
+	_, err = w.Write(data)
+	return
+}
+
+// ReadTrashDocument is a function that reads the deleted vehicles of a document of any supported schema version,
+// upgrading them to the current one. A bare array of deleted vehicles is read as version 1.
+func ReadTrashDocument(r io.Reader) (tv []internal.TrashedVehicle, err error) {
+	records, version, err := decodeRecords(r)
+	if err != nil {
+		return
+	}
+	items, err := upgradeRecords[TrashedVehicleJSON](records, version)
+	if err != nil {
+		err = fmt.Errorf("trash: %w", err)
+		return
+	}
+	tv = make([]internal.TrashedVehicle, len(items))
+	for ix, item := range items {
+		tv[ix] = internal.TrashedVehicle{
+			Vehicle:   item.toVehicle(),
+			DeletedAt: item.DeletedAt,
+			DeletedBy: item.DeletedBy,
+		}
+	}
+	return
+}
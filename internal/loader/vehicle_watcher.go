@@ -0,0 +1,96 @@
+package loader
+
+import (
+	"app/internal"
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// NewVehicleFileWatcher is a function that returns a new instance of VehicleFileWatcher
+func NewVehicleFileWatcher(path string, interval time.Duration, rl internal.VehicleReloader) *VehicleFileWatcher {
+	// default interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &VehicleFileWatcher{
+		path:     path,
+		interval: interval,
+		rl:       rl,
+	}
+}
+
+// VehicleFileWatcher is a struct that watches the file of the dataset and reloads it when it changes.
+// It polls the modification time and the size of the file, so it does not depend on inotify.
+type VehicleFileWatcher struct {
+	// path is the path to the file that contains the vehicles
+	path string
+	// interval is the time between two polls
+	interval time.Duration
+	// rl is the reloader called when the file changes
+	rl internal.VehicleReloader
+}
+
+// fileState is a struct that represents the state of a file used to detect changes
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// equal is a method that reports whether two states of a file are the same
+func (s fileState) equal(o fileState) bool {
+	return s.modTime.Equal(o.modTime) && s.size == o.size
+}
+
+// stat is a method that returns the current state of the file
+func (w *VehicleFileWatcher) stat() (s fileState, err error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return
+	}
+	s = fileState{modTime: info.ModTime(), size: info.Size()}
+	return
+}
+
+// Run is a method that polls the file until the context is done.
+// A change is reloaded once the file keeps the same state for two polls, so a file still being written is not read.
+func (w *VehicleFileWatcher) Run(ctx context.Context) {
+	current, _ := w.stat()
+	var pending *fileState
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s, err := w.stat()
+		if err != nil {
+			// the file may be being replaced, try again on the next poll
+			pending = nil
+			continue
+		}
+		if s.equal(current) {
+			pending = nil
+			continue
+		}
+		if pending == nil || !pending.equal(s) {
+			pending = &s
+			continue
+		}
+
+		// the file changed and is stable
+		current = s
+		pending = nil
+		res, err := w.rl.Reload("watcher")
+		if err != nil || res.Outcome != internal.ReloadApplied {
+			slog.WarnContext(ctx, "watcher: reload rolled back", "path", w.path, "error", res.Error)
+			continue
+		}
+		slog.InfoContext(ctx, "watcher: reload applied", "path", w.path, "loaded", res.Loaded)
+	}
+}
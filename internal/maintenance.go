@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMaintenanceRecordNotFound is returned when a maintenance record does not exist for a vehicle
+	ErrMaintenanceRecordNotFound = errors.New("maintenance record not found")
+	// ErrInvalidMaintenanceRecord is returned when a maintenance record has invalid values
+	ErrInvalidMaintenanceRecord = errors.New("invalid maintenance record")
+	// ErrVehicleHasMaintenance is returned when a vehicle with maintenance records is deleted under the restrict policy
+	ErrVehicleHasMaintenance = errors.New("vehicle has maintenance records")
+)
+
+const (
+	// MaintenanceTypeService is a scheduled service of a vehicle (e.g. an oil change)
+	MaintenanceTypeService = "service"
+	// MaintenanceTypeRepair is a repair of a vehicle
+	MaintenanceTypeRepair = "repair"
+	// MaintenanceTypePartReplacement is a replacement of parts of a vehicle
+	MaintenanceTypePartReplacement = "part_replacement"
+)
+
+const (
+	// MaintenanceCascadeKeep keeps the records of a deleted vehicle while it is in the trash, so they come back
+	// with it when it is restored, and removes them when it is purged
+	MaintenanceCascadeKeep = "keep"
+	// MaintenanceCascadeDelete removes the records of a vehicle when it is deleted
+	MaintenanceCascadeDelete = "delete"
+	// MaintenanceCascadeRestrict rejects the deletion of a vehicle with records
+	MaintenanceCascadeRestrict = "restrict"
+)
+
+// MaintenancePart is a struct that represents a part used in a maintenance of a vehicle
+type MaintenancePart struct {
+	// Name is the name of the part (e.g. oil filter)
+	Name string
+	// Number is the reference of the part, if any
+	Number string
+	// Quantity is the number of units used
+	Quantity int
+}
+
+// MaintenanceRecord is a struct that represents a service, a repair or a part replacement of a vehicle
+type MaintenanceRecord struct {
+	// Id is the unique identifier of the record
+	Id int
+	// VehicleId is the id of the vehicle maintained
+	VehicleId int
+	// Date is the day of the maintenance
+	Date time.Time
+	// Odometer is the distance covered by the vehicle at the maintenance, in km
+	Odometer float64
+	// Type is the type of the maintenance (service, repair, part_replacement)
+	Type string
+	// Cost is the cost of the maintenance
+	Cost float64
+	// Notes are the free-form notes of the maintenance
+	Notes string
+	// Parts are the parts used in the maintenance
+	Parts []MaintenancePart
+}
+
+// MaintenanceRepository is an interface that represents the storage of the maintenance records of the vehicles
+type MaintenanceRepository interface {
+	// CreateRecord is a method that registers a maintenance record, assigning its id
+	CreateRecord(ctx context.Context, rc MaintenanceRecord) (created MaintenanceRecord, err error)
+	// FindRecords is a method that returns the maintenance records of a vehicle, by date and id
+	FindRecords(ctx context.Context, vehicleId int) (rc []MaintenanceRecord, err error)
+	// FindRecordById is a method that returns a maintenance record of a vehicle by id
+	FindRecordById(ctx context.Context, vehicleId int, id int) (rc MaintenanceRecord, err error)
+	// UpdateRecord is a method that replaces a maintenance record of a vehicle
+	UpdateRecord(ctx context.Context, rc MaintenanceRecord) (err error)
+	// DeleteRecord is a method that deletes a maintenance record of a vehicle
+	DeleteRecord(ctx context.Context, vehicleId int, id int) (err error)
+	// DeleteRecords is a method that deletes all the maintenance records of a vehicle, returning how many there were
+	DeleteRecords(ctx context.Context, vehicleId int) (n int, err error)
+}
+
+// MaintenanceService is an interface that represents the service of the maintenance records of the vehicles
+type MaintenanceService interface {
+	// CreateRecord is a method that validates and registers a maintenance record of an existing vehicle
+	CreateRecord(ctx context.Context, rc MaintenanceRecord) (created MaintenanceRecord, err error)
+	// FindRecords is a method that returns the maintenance records of an existing vehicle, by date and id
+	FindRecords(ctx context.Context, vehicleId int) (rc []MaintenanceRecord, err error)
+	// FindRecordById is a method that returns a maintenance record of an existing vehicle by id
+	FindRecordById(ctx context.Context, vehicleId int, id int) (rc MaintenanceRecord, err error)
+	// UpdateRecord is a method that validates and replaces a maintenance record of an existing vehicle
+	UpdateRecord(ctx context.Context, rc MaintenanceRecord) (updated MaintenanceRecord, err error)
+	// DeleteRecord is a method that deletes a maintenance record of an existing vehicle
+	DeleteRecord(ctx context.Context, vehicleId int, id int) (err error)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"app/internal"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// auditEntryJSON is a struct that represents an entry of the audit log in a file, one per line
+type auditEntryJSON struct {
+	Id        uint64                          `json:"id"`
+	At        time.Time                       `json:"at"`
+	Actor     string                          `json:"actor"`
+	Tenant    string                          `json:"tenant,omitempty"`
+	RequestId string                          `json:"request_id,omitempty"`
+	Operation string                          `json:"operation"`
+	VehicleId int                             `json:"vehicle_id"`
+	Changes   map[string]auditFieldChangeJSON `json:"changes"`
+}
+
+// auditFieldChangeJSON is a struct that represents the change of an attribute in a file
+type auditFieldChangeJSON struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// NewAuditLog is a function that returns a new instance of AuditLog kept in memory only
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// OpenAuditLog is a function that returns a new instance of AuditLog that appends the entries to a file,
+// one JSON document per line, after reading the entries already in it.
+// A last line without its newline that is not a valid entry is the trace of a crash in the middle of a write:
+// it is dropped from the file with a warning. Any other invalid line fails the opening.
+func OpenAuditLog(path string) (l *AuditLog, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	l = &AuditLog{file: file}
+
+	rd := bufio.NewReader(file)
+	var offset int64
+	for line := 1; ; line++ {
+		b, errRead := rd.ReadBytes('\n')
+		if errRead != nil && !errors.Is(errRead, io.EOF) {
+			file.Close()
+			l, err = nil, errRead
+			return
+		}
+		terminated := errRead == nil
+		if len(bytes.TrimSpace(b)) > 0 {
+			var e auditEntryJSON
+			if errEntry := json.Unmarshal(b, &e); errEntry != nil {
+				if terminated {
+					file.Close()
+					l, err = nil, fmt.Errorf("audit log %s: line %d: %w", path, line, errEntry)
+					return
+				}
+				slog.Warn("audit: partial last entry dropped", "path", path, "line", line, "bytes", len(b), "error", errEntry)
+				if err = file.Truncate(offset); err != nil {
+					file.Close()
+					l = nil
+				}
+				return
+			}
+			l.entries = append(l.entries, auditEntryFromJSON(e))
+			l.lastId = max(l.lastId, e.Id)
+			if !terminated {
+				// the next entry starts on its own line
+				if _, err = file.Write([]byte{'\n'}); err != nil {
+					file.Close()
+					l = nil
+				}
+				return
+			}
+		}
+		offset += int64(len(b))
+		if !terminated {
+			return
+		}
+	}
+}
+
+// AuditLog is a struct that implements an append-only audit log, in memory and optionally in a file
+type AuditLog struct {
+	// mu protects the entries and the file
+	mu sync.RWMutex
+	// entries are the entries of the log, oldest first
+	entries []internal.AuditEntry
+	// lastId is the id of the last entry appended
+	lastId uint64
+	// file is the file the entries are appended to, nil to keep them in memory only
+	file *os.File
+}
+
+// Append is a method that appends entries to the log, assigning their ids.
+// With a file, the entries are written before they are visible, so a failed write records nothing.
+func (l *AuditLog) Append(ctx context.Context, entries ...internal.AuditEntry) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	appended := make([]internal.AuditEntry, len(entries))
+	for ix, e := range entries {
+		e.Id = l.lastId + uint64(ix) + 1
+		appended[ix] = e
+	}
+	if l.file != nil {
+		var b []byte
+		for _, e := range appended {
+			var line []byte
+			if line, err = json.Marshal(auditEntryToJSON(e)); err != nil {
+				return
+			}
+			b = append(append(b, line...), '\n')
+		}
+		if _, err = l.file.Write(b); err != nil {
+			return
+		}
+	}
+	l.entries = append(l.entries, appended...)
+	l.lastId += uint64(len(appended))
+	return
+}
+
+// Find is a method that returns the entries matching the query, oldest first
+func (l *AuditLog) Find(ctx context.Context, q internal.AuditQuery) (entries []internal.AuditEntry, err error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries = make([]internal.AuditEntry, 0)
+	for _, e := range l.entries {
+		if q.Tenant != "" && e.Tenant != q.Tenant {
+			continue
+		}
+		if q.VehicleId != 0 && e.VehicleId != q.VehicleId {
+			continue
+		}
+		if q.Actor != "" && e.Actor != q.Actor {
+			continue
+		}
+		if !q.Since.IsZero() && e.At.Before(q.Since) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return
+}
+
+// Close is a method that closes the file of the log, if any
+func (l *AuditLog) Close() (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		err = l.file.Close()
+		l.file = nil
+	}
+	return
+}
+
+// auditEntryToJSON is a function that serializes an entry of the audit log to its JSON format
+func auditEntryToJSON(e internal.AuditEntry) (data auditEntryJSON) {
+	data = auditEntryJSON{
+		Id:        e.Id,
+		At:        e.At,
+		Actor:     e.Actor,
+		Tenant:    e.Tenant,
+		RequestId: e.RequestId,
+		Operation: e.Operation,
+		VehicleId: e.VehicleId,
+		Changes:   make(map[string]auditFieldChangeJSON, len(e.Changes)),
+	}
+	for name, change := range e.Changes {
+		data.Changes[name] = auditFieldChangeJSON{From: change.From, To: change.To}
+	}
+	return
+}
+
+// auditEntryFromJSON is a function that deserializes an entry of the audit log from its JSON format
+func auditEntryFromJSON(data auditEntryJSON) (e internal.AuditEntry) {
+	e = internal.AuditEntry{
+		Id:        data.Id,
+		At:        data.At,
+		Actor:     data.Actor,
+		Tenant:    data.Tenant,
+		RequestId: data.RequestId,
+		Operation: data.Operation,
+		VehicleId: data.VehicleId,
+		Changes:   make(map[string]internal.FieldChange, len(data.Changes)),
+	}
+	// the entries written before the tenants were introduced belong to the default one
+	if e.Tenant == "" {
+		e.Tenant = internal.TenantDefault
+	}
+	for name, change := range data.Changes {
+		e.Changes[name] = internal.FieldChange{From: change.From, To: change.To}
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AuditLog
+func TestAuditLog(t *testing.T) {
+	t.Run("case 1: the entries are filtered by vehicle, actor and time, oldest first", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		l := repository.NewAuditLog()
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, l.Append(ctx,
+			internal.AuditEntry{At: start, Actor: "alice", Operation: internal.AuditCreate, VehicleId: 1},
+			internal.AuditEntry{At: start.Add(time.Hour), Actor: "bob", Operation: internal.AuditUpdateFuel, VehicleId: 1},
+			internal.AuditEntry{At: start.Add(2 * time.Hour), Actor: "alice", Operation: internal.AuditDelete, VehicleId: 2},
+		))
+
+		// act
+		byVehicle, _ := l.Find(ctx, internal.AuditQuery{VehicleId: 1})
+		byActor, _ := l.Find(ctx, internal.AuditQuery{Actor: "alice", Since: start.Add(time.Minute)})
+
+		// assert
+		require.Len(t, byVehicle, 2)
+		require.Equal(t, []uint64{1, 2}, []uint64{byVehicle[0].Id, byVehicle[1].Id})
+		require.Len(t, byActor, 1)
+		require.Equal(t, internal.AuditDelete, byActor[0].Operation)
+	})
+
+	t.Run("case 2: the entries appended to a file are read when it is opened again", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		l, err := repository.OpenAuditLog(path)
+		require.NoError(t, err)
+		require.NoError(t, l.Append(ctx, internal.AuditEntry{
+			Actor: "alice", Operation: internal.AuditUpdateSpeed, VehicleId: 1,
+			Changes: map[string]internal.FieldChange{"max_speed": {From: 100.0, To: 120.0}},
+		}))
+		require.NoError(t, l.Close())
+
+		// act
+		l, err = repository.OpenAuditLog(path)
+		require.NoError(t, err)
+		defer l.Close()
+		require.NoError(t, l.Append(ctx, internal.AuditEntry{Actor: "bob", Operation: internal.AuditDelete, VehicleId: 1}))
+		entries, _ := l.Find(ctx, internal.AuditQuery{})
+
+		// assert
+		require.Len(t, entries, 2)
+		require.Equal(t, uint64(1), entries[0].Id)
+		require.Equal(t, internal.FieldChange{From: 100.0, To: 120.0}, entries[0].Changes["max_speed"])
+		require.Equal(t, uint64(2), entries[1].Id)
+	})
+
+	t.Run("case 3: a corrupted file is not opened", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("{\"id\":1}\nnot json\n"), 0o644))
+
+		// act
+		_, err := repository.OpenAuditLog(path)
+
+		// assert
+		require.ErrorContains(t, err, "line 2")
+	})
+	t.Run("case 4: the entries of a tenant are not found by another one", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		l := repository.NewAuditLog()
+		require.NoError(t, l.Append(ctx,
+			internal.AuditEntry{Actor: "alice", Tenant: "acme", Operation: internal.AuditCreate, VehicleId: 1},
+			internal.AuditEntry{Actor: "alice", Tenant: "globex", Operation: internal.AuditCreate, VehicleId: 1},
+		))
+
+		// act
+		entries, _ := l.Find(ctx, internal.AuditQuery{Tenant: "globex", VehicleId: 1})
+
+		// assert
+		require.Len(t, entries, 1)
+		require.Equal(t, uint64(2), entries[0].Id)
+	})
+
+	t.Run("case 5: a partial last entry left by a crash is dropped, the next entries follow the complete ones", func(t *testing.T) {
+		for _, tail := range []string{`{"id":2,"actor":"bo`, `{"id":2,"actor":"bob","operation":"delete","vehicle_id":1}`} {
+			// arrange
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			require.NoError(t, os.WriteFile(path, []byte(`{"id":1,"actor":"alice","operation":"delete","vehicle_id":1}`+"\n"+tail), 0o644))
+
+			// act
+			l, err := repository.OpenAuditLog(path)
+			require.NoError(t, err)
+			require.NoError(t, l.Append(ctx, internal.AuditEntry{Actor: "carol", Operation: internal.AuditDelete, VehicleId: 2}))
+			require.NoError(t, l.Close())
+			l, err = repository.OpenAuditLog(path)
+
+			// assert
+			require.NoError(t, err)
+			entries, _ := l.Find(ctx, internal.AuditQuery{})
+			require.NoError(t, l.Close())
+			actors := make([]string, len(entries))
+			for ix, e := range entries {
+				actors[ix] = e.Actor
+			}
+			if strings.HasSuffix(tail, "}") {
+				require.Equal(t, []string{"alice", "bob", "carol"}, actors)
+			} else {
+				require.Equal(t, []string{"alice", "carol"}, actors)
+				require.Equal(t, uint64(2), entries[1].Id)
+			}
+		}
+	})
+}
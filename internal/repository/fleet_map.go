@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fleetsJSON is a struct that represents the fleets and the fleet of each vehicle in the file of the fleets
+type fleetsJSON struct {
+	LastId  int               `json:"last_id"`
+	Fleets  []fleetJSON       `json:"fleets"`
+	Members []fleetMemberJSON `json:"members"`
+}
+
+// fleetJSON is a struct that represents a fleet in the file of the fleets
+type fleetJSON struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Depot     string    `json:"depot"`
+	ParentId  int       `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// fleetMemberJSON is a struct that represents the fleet of a vehicle in the file of the fleets
+type fleetMemberJSON struct {
+	VehicleId int `json:"vehicle_id"`
+	FleetId   int `json:"fleet_id"`
+}
+
+// NewFleetMap is a function that returns a new instance of FleetMap kept in memory only
+func NewFleetMap() *FleetMap {
+	return &FleetMap{
+		fleets:  make(map[int]internal.Fleet),
+		members: make(map[int]int),
+	}
+}
+
+// OpenFleetMap is a function that returns a new instance of FleetMap saved in a file,
+// after reading the fleets already in it
+func OpenFleetMap(path string) (r *FleetMap, err error) {
+	r = NewFleetMap()
+	r.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		r = nil
+		return
+	}
+	var document fleetsJSON
+	if err = json.Unmarshal(data, &document); err != nil {
+		r = nil
+		return
+	}
+	r.lastId = document.LastId
+	for _, f := range document.Fleets {
+		r.fleets[f.Id] = internal.Fleet{Id: f.Id, Name: f.Name, Depot: f.Depot, ParentId: f.ParentId, CreatedAt: f.CreatedAt}
+	}
+	for _, m := range document.Members {
+		r.members[m.VehicleId] = m.FleetId
+	}
+	return
+}
+
+// FleetMap is a struct that keeps the fleets and the fleet of each vehicle in a map.
+// With a file, the whole fleets are written again on each change, so they survive a restart.
+type FleetMap struct {
+	// mu protects the maps and the last id
+	mu sync.RWMutex
+	// fleets are the fleets by id
+	fleets map[int]internal.Fleet
+	// members are the ids of the fleets by id of vehicle
+	members map[int]int
+	// lastId is the id of the last fleet registered
+	lastId int
+	// path is the file the fleets are saved to, empty to keep them in memory only
+	path string
+}
+
+// CreateFleet is a method that registers a fleet, assigning its id
+func (r *FleetMap) CreateFleet(ctx context.Context, f internal.Fleet) (created internal.Fleet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.named(f.Name, 0) {
+		err = internal.ErrFleetAlreadyExists
+		return
+	}
+	if f.ParentId != 0 {
+		if _, ok := r.fleets[f.ParentId]; !ok {
+			err = internal.ErrFleetNotFound
+			return
+		}
+	}
+	undo := r.checkpoint()
+	r.lastId++
+	created = f
+	created.Id = r.lastId
+	r.fleets[created.Id] = created
+	err = r.save(undo)
+	return
+}
+
+// FindFleets is a method that returns all the fleets, by id
+func (r *FleetMap) FindFleets(ctx context.Context) (f []internal.Fleet, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f = make([]internal.Fleet, 0, len(r.fleets))
+	for _, value := range r.fleets {
+		f = append(f, value)
+	}
+	sort.Slice(f, func(i, j int) bool { return f[i].Id < f[j].Id })
+	return
+}
+
+// FindFleetById is a method that returns a fleet by id
+func (r *FleetMap) FindFleetById(ctx context.Context, id int) (f internal.Fleet, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.fleets[id]
+	if !ok {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	return
+}
+
+// UpdateFleet is a method that replaces the name, the depot and the parent of a fleet
+func (r *FleetMap) UpdateFleet(ctx context.Context, f internal.Fleet) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.fleets[f.Id]
+	if !ok {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	if r.named(f.Name, f.Id) {
+		err = internal.ErrFleetAlreadyExists
+		return
+	}
+	if f.ParentId != 0 {
+		if _, ok := r.fleets[f.ParentId]; !ok {
+			err = internal.ErrFleetNotFound
+			return
+		}
+	}
+	undo := r.checkpoint()
+	current.Name = f.Name
+	current.Depot = f.Depot
+	current.ParentId = f.ParentId
+	r.fleets[f.Id] = current
+	err = r.save(undo)
+	return
+}
+
+// DeleteFleet is a method that deletes a fleet, moving its vehicles and its sub-fleets to the fleet moveTo
+// (without fleet and to the top level if 0)
+func (r *FleetMap) DeleteFleet(ctx context.Context, id int, moveTo int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.fleets[id]; !ok {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	if _, ok := r.fleets[moveTo]; moveTo != 0 && (!ok || moveTo == id) {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	undo := r.checkpoint()
+	delete(r.fleets, id)
+
+	// - orphans
+	for vehicleId, fleetId := range r.members {
+		if fleetId != id {
+			continue
+		}
+		if moveTo == 0 {
+			delete(r.members, vehicleId)
+			continue
+		}
+		r.members[vehicleId] = moveTo
+	}
+	for key, value := range r.fleets {
+		if value.ParentId == id {
+			value.ParentId = moveTo
+			r.fleets[key] = value
+		}
+	}
+	err = r.save(undo)
+	return
+}
+
+// AssignVehicle is a method that makes a vehicle a member of a fleet, leaving its previous fleet if any
+func (r *FleetMap) AssignVehicle(ctx context.Context, vehicleId int, fleetId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.fleets[fleetId]; !ok {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	undo := r.checkpoint()
+	r.members[vehicleId] = fleetId
+	err = r.save(undo)
+	return
+}
+
+// UnassignVehicle is a method that leaves a vehicle without fleet
+func (r *FleetMap) UnassignVehicle(ctx context.Context, vehicleId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[vehicleId]; !ok {
+		return
+	}
+	undo := r.checkpoint()
+	delete(r.members, vehicleId)
+	err = r.save(undo)
+	return
+}
+
+// FindFleetOf is a method that returns the id of the fleet of a vehicle, 0 if it has none
+func (r *FleetMap) FindFleetOf(ctx context.Context, vehicleId int) (fleetId int, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fleetId = r.members[vehicleId]
+	return
+}
+
+// FindMembers is a method that returns the ids of the vehicles of a fleet, in order
+func (r *FleetMap) FindMembers(ctx context.Context, fleetId int) (vehicleIds []int, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.fleets[fleetId]; !ok {
+		err = internal.ErrFleetNotFound
+		return
+	}
+	vehicleIds = make([]int, 0)
+	for vehicleId, value := range r.members {
+		if value == fleetId {
+			vehicleIds = append(vehicleIds, vehicleId)
+		}
+	}
+	slices.Sort(vehicleIds)
+	return
+}
+
+// named is a method that returns true if a fleet other than self is named name. It must be called with the lock held.
+func (r *FleetMap) named(name string, self int) bool {
+	for key, value := range r.fleets {
+		if key != self && value.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpoint is a method that returns a function that puts the fleets back as they are, to undo a change
+// that can not be saved. It must be called with the lock held.
+func (r *FleetMap) checkpoint() (undo func()) {
+	if r.path == "" {
+		return func() {}
+	}
+	fleets, members, lastId := maps.Clone(r.fleets), maps.Clone(r.members), r.lastId
+	return func() {
+		r.fleets, r.members, r.lastId = fleets, members, lastId
+	}
+}
+
+// save is a method that writes the whole fleets to their file, if any, through a temporary file renamed over it,
+// calling undo if they can not be written. It must be called with the lock held.
+func (r *FleetMap) save(undo func()) (err error) {
+	if r.path == "" {
+		return
+	}
+	defer func() {
+		if err != nil {
+			undo()
+		}
+	}()
+	document := fleetsJSON{
+		LastId:  r.lastId,
+		Fleets:  make([]fleetJSON, 0, len(r.fleets)),
+		Members: make([]fleetMemberJSON, 0, len(r.members)),
+	}
+	for _, f := range r.fleets {
+		document.Fleets = append(document.Fleets, fleetJSON{Id: f.Id, Name: f.Name, Depot: f.Depot, ParentId: f.ParentId, CreatedAt: f.CreatedAt})
+	}
+	sort.Slice(document.Fleets, func(i, j int) bool { return document.Fleets[i].Id < document.Fleets[j].Id })
+	for vehicleId, fleetId := range r.members {
+		document.Members = append(document.Members, fleetMemberJSON{VehicleId: vehicleId, FleetId: fleetId})
+	}
+	sort.Slice(document.Members, func(i, j int) bool { return document.Members[i].VehicleId < document.Members[j].VehicleId })
+	data, err := json.Marshal(document)
+	if err != nil {
+		return
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	err = os.Rename(file.Name(), r.path)
+	return
+}
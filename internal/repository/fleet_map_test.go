@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for FleetMap
+func TestFleetMap(t *testing.T) {
+	t.Run("case 1: the fleets get increasing ids and unique names", func(t *testing.T) {
+		// arrange
+		rp := repository.NewFleetMap()
+
+		// act
+		north, errNorth := rp.CreateFleet(context.Background(), internal.Fleet{Name: "North", Depot: "Leeds"})
+		vans, errVans := rp.CreateFleet(context.Background(), internal.Fleet{Name: "Vans", ParentId: north.Id})
+		_, errName := rp.CreateFleet(context.Background(), internal.Fleet{Name: "North"})
+		_, errParent := rp.CreateFleet(context.Background(), internal.Fleet{Name: "Trucks", ParentId: 42})
+		errRename := rp.UpdateFleet(context.Background(), internal.Fleet{Id: vans.Id, Name: "North"})
+
+		// assert
+		require.NoError(t, errNorth)
+		require.NoError(t, errVans)
+		require.Equal(t, 1, north.Id)
+		require.Equal(t, 2, vans.Id)
+		require.ErrorIs(t, errName, internal.ErrFleetAlreadyExists)
+		require.ErrorIs(t, errParent, internal.ErrFleetNotFound)
+		require.ErrorIs(t, errRename, internal.ErrFleetAlreadyExists)
+		f, _ := rp.FindFleets(context.Background())
+		require.Equal(t, []internal.Fleet{north, vans}, f)
+	})
+
+	t.Run("case 2: a vehicle is a member of one fleet at most", func(t *testing.T) {
+		// arrange
+		rp := repository.NewFleetMap()
+		north, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "North"})
+		south, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "South"})
+
+		// act
+		require.NoError(t, rp.AssignVehicle(context.Background(), 7, north.Id))
+		require.NoError(t, rp.AssignVehicle(context.Background(), 7, south.Id))
+		require.NoError(t, rp.AssignVehicle(context.Background(), 3, south.Id))
+		errUnknown := rp.AssignVehicle(context.Background(), 8, 42)
+
+		// assert
+		require.ErrorIs(t, errUnknown, internal.ErrFleetNotFound)
+		mNorth, _ := rp.FindMembers(context.Background(), north.Id)
+		require.Empty(t, mNorth)
+		mSouth, _ := rp.FindMembers(context.Background(), south.Id)
+		require.Equal(t, []int{3, 7}, mSouth)
+		fleetId, _ := rp.FindFleetOf(context.Background(), 8)
+		require.Zero(t, fleetId)
+	})
+
+	t.Run("case 3: the vehicles and the sub-fleets of a deleted fleet are moved or left without fleet", func(t *testing.T) {
+		// arrange
+		rp := repository.NewFleetMap()
+		north, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "North"})
+		vans, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "Vans", ParentId: north.Id})
+		cargo, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "Cargo", ParentId: vans.Id})
+		require.NoError(t, rp.AssignVehicle(context.Background(), 1, vans.Id))
+		require.NoError(t, rp.AssignVehicle(context.Background(), 2, north.Id))
+
+		// act
+		errVans := rp.DeleteFleet(context.Background(), vans.Id, north.Id)
+		errNorth := rp.DeleteFleet(context.Background(), north.Id, 0)
+
+		// assert
+		require.NoError(t, errVans)
+		require.NoError(t, errNorth)
+		f, _ := rp.FindFleets(context.Background())
+		cargo.ParentId = 0
+		require.Equal(t, []internal.Fleet{cargo}, f)
+		for _, vehicleId := range []int{1, 2} {
+			fleetId, _ := rp.FindFleetOf(context.Background(), vehicleId)
+			require.Zero(t, fleetId)
+		}
+	})
+
+	t.Run("case 4: the fleets saved in a file are read when it is opened again", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "vehicles.json.fleets")
+		rp, err := repository.OpenFleetMap(path)
+		require.NoError(t, err)
+		north, _ := rp.CreateFleet(ctx, internal.Fleet{Name: "North", Depot: "Oslo"})
+		south, _ := rp.CreateFleet(ctx, internal.Fleet{Name: "South", ParentId: north.Id})
+		require.NoError(t, rp.AssignVehicle(ctx, 1, south.Id))
+		require.NoError(t, rp.AssignVehicle(ctx, 2, south.Id))
+		require.NoError(t, rp.UnassignVehicle(ctx, 2))
+
+		// act
+		rp, err = repository.OpenFleetMap(path)
+		require.NoError(t, err)
+		f, _ := rp.FindFleets(ctx)
+		members, _ := rp.FindMembers(ctx, south.Id)
+		created, _ := rp.CreateFleet(ctx, internal.Fleet{Name: "East"})
+
+		// assert
+		require.Equal(t, []internal.Fleet{north, south}, f)
+		require.Equal(t, []int{1}, members)
+		require.Equal(t, 3, created.Id)
+	})
+}
+
+// Tests for FleetTenants
+func TestFleetTenants(t *testing.T) {
+	t.Run("case 1: a tenant does not see the fleets of another one", func(t *testing.T) {
+		// arrange
+		acme := internal.ContextWithTenant(context.Background(), "acme")
+		globex := internal.ContextWithTenant(context.Background(), "globex")
+		rp := repository.NewFleetTenants(map[string]internal.FleetRepository{
+			"acme":   repository.NewFleetMap(),
+			"globex": repository.NewFleetMap(),
+		})
+		north, err := rp.CreateFleet(acme, internal.Fleet{Name: "North"})
+		require.NoError(t, err)
+
+		// act
+		f, errAll := rp.FindFleets(globex)
+		_, errById := rp.FindFleetById(globex, north.Id)
+		errAssign := rp.AssignVehicle(globex, 1, north.Id)
+		_, errTenant := rp.FindFleets(context.Background())
+
+		// assert
+		require.NoError(t, errAll)
+		require.Empty(t, f)
+		require.ErrorIs(t, errById, internal.ErrFleetNotFound)
+		require.ErrorIs(t, errAssign, internal.ErrFleetNotFound)
+		require.ErrorIs(t, errTenant, internal.ErrTenantNotFound)
+	})
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+)
+
+// NewFleetTenants is a function that returns a new instance of FleetTenants
+func NewFleetTenants(tenants map[string]internal.FleetRepository) *FleetTenants {
+	return &FleetTenants{tenants: tenants}
+}
+
+// FleetTenants is a struct that implements the FleetRepository interface over the fleets of several tenants:
+// each operation is applied to the fleets of the tenant of its context only
+type FleetTenants struct {
+	// tenants are the fleets of the tenants, by name
+	tenants map[string]internal.FleetRepository
+}
+
+// CreateFleet is a method that registers a fleet, assigning its id
+func (r *FleetTenants) CreateFleet(ctx context.Context, f internal.Fleet) (created internal.Fleet, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.CreateFleet(ctx, f)
+}
+
+// FindFleets is a method that returns all the fleets, by id
+func (r *FleetTenants) FindFleets(ctx context.Context) (f []internal.Fleet, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindFleets(ctx)
+}
+
+// FindFleetById is a method that returns a fleet by id
+func (r *FleetTenants) FindFleetById(ctx context.Context, id int) (f internal.Fleet, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindFleetById(ctx, id)
+}
+
+// UpdateFleet is a method that replaces the name, the depot and the parent of a fleet
+func (r *FleetTenants) UpdateFleet(ctx context.Context, f internal.Fleet) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UpdateFleet(ctx, f)
+}
+
+// DeleteFleet is a method that deletes a fleet, moving its vehicles and its sub-fleets to the fleet moveTo
+func (r *FleetTenants) DeleteFleet(ctx context.Context, id int, moveTo int) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.DeleteFleet(ctx, id, moveTo)
+}
+
+// AssignVehicle is a method that makes a vehicle a member of a fleet, leaving its previous fleet if any
+func (r *FleetTenants) AssignVehicle(ctx context.Context, vehicleId int, fleetId int) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.AssignVehicle(ctx, vehicleId, fleetId)
+}
+
+// UnassignVehicle is a method that leaves a vehicle without fleet
+func (r *FleetTenants) UnassignVehicle(ctx context.Context, vehicleId int) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UnassignVehicle(ctx, vehicleId)
+}
+
+// FindFleetOf is a method that returns the id of the fleet of a vehicle, 0 if it has none
+func (r *FleetTenants) FindFleetOf(ctx context.Context, vehicleId int) (fleetId int, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindFleetOf(ctx, vehicleId)
+}
+
+// FindMembers is a method that returns the ids of the vehicles of a fleet, in order
+func (r *FleetTenants) FindMembers(ctx context.Context, fleetId int) (vehicleIds []int, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindMembers(ctx, fleetId)
+}
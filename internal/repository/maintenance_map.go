@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NewMaintenanceMap is a function that returns a new instance of MaintenanceMap
+func NewMaintenanceMap() *MaintenanceMap {
+	return &MaintenanceMap{records: make(map[int]internal.MaintenanceRecord)}
+}
+
+// MaintenanceMap is a struct that keeps the maintenance records of the vehicles in memory
+type MaintenanceMap struct {
+	// mu protects the records and the last id
+	mu sync.RWMutex
+	// records are the maintenance records by id
+	records map[int]internal.MaintenanceRecord
+	// lastId is the id of the last record registered
+	lastId int
+}
+
+// CreateRecord is a method that registers a maintenance record, assigning its id
+func (r *MaintenanceMap) CreateRecord(ctx context.Context, rc internal.MaintenanceRecord) (created internal.MaintenanceRecord, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	created = rc
+	created.Id = r.lastId
+	created.Parts = slices.Clone(rc.Parts)
+	r.records[created.Id] = created
+	created.Parts = slices.Clone(created.Parts)
+	return
+}
+
+// FindRecords is a method that returns the maintenance records of a vehicle, by date and id
+func (r *MaintenanceMap) FindRecords(ctx context.Context, vehicleId int) (rc []internal.MaintenanceRecord, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rc = make([]internal.MaintenanceRecord, 0)
+	for _, value := range r.records {
+		if value.VehicleId == vehicleId {
+			value.Parts = slices.Clone(value.Parts)
+			rc = append(rc, value)
+		}
+	}
+	sort.Slice(rc, func(i, j int) bool {
+		if !rc[i].Date.Equal(rc[j].Date) {
+			return rc[i].Date.Before(rc[j].Date)
+		}
+		return rc[i].Id < rc[j].Id
+	})
+	return
+}
+
+// FindRecordById is a method that returns a maintenance record of a vehicle by id
+func (r *MaintenanceMap) FindRecordById(ctx context.Context, vehicleId int, id int) (rc internal.MaintenanceRecord, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rc, ok := r.records[id]
+	if !ok || rc.VehicleId != vehicleId {
+		rc = internal.MaintenanceRecord{}
+		err = internal.ErrMaintenanceRecordNotFound
+		return
+	}
+	rc.Parts = slices.Clone(rc.Parts)
+	return
+}
+
+// UpdateRecord is a method that replaces a maintenance record of a vehicle
+func (r *MaintenanceMap) UpdateRecord(ctx context.Context, rc internal.MaintenanceRecord) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.records[rc.Id]; !ok || current.VehicleId != rc.VehicleId {
+		err = internal.ErrMaintenanceRecordNotFound
+		return
+	}
+	rc.Parts = slices.Clone(rc.Parts)
+	r.records[rc.Id] = rc
+	return
+}
+
+// DeleteRecord is a method that deletes a maintenance record of a vehicle
+func (r *MaintenanceMap) DeleteRecord(ctx context.Context, vehicleId int, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.records[id]; !ok || current.VehicleId != vehicleId {
+		err = internal.ErrMaintenanceRecordNotFound
+		return
+	}
+	delete(r.records, id)
+	return
+}
+
+// DeleteRecords is a method that deletes all the maintenance records of a vehicle, returning how many there were
+func (r *MaintenanceMap) DeleteRecords(ctx context.Context, vehicleId int) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, value := range r.records {
+		if value.VehicleId == vehicleId {
+			delete(r.records, id)
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// maintenanceOn is a function that returns a maintenance record of a vehicle on a day
+func maintenanceOn(vehicleId int, day string) internal.MaintenanceRecord {
+	date, _ := time.Parse(time.DateOnly, day)
+	return internal.MaintenanceRecord{VehicleId: vehicleId, Date: date, Type: internal.MaintenanceTypeService}
+}
+
+// Tests for MaintenanceMap
+func TestMaintenanceMap(t *testing.T) {
+	t.Run("case 1: the records of a vehicle are returned by date, each record belongs to its vehicle only", func(t *testing.T) {
+		// arrange
+		rp := repository.NewMaintenanceMap()
+		late, _ := rp.CreateRecord(context.Background(), maintenanceOn(1, "2024-03-01"))
+		early, _ := rp.CreateRecord(context.Background(), maintenanceOn(1, "2024-01-01"))
+		other, _ := rp.CreateRecord(context.Background(), maintenanceOn(2, "2024-02-01"))
+
+		// act
+		rc, err := rp.FindRecords(context.Background(), 1)
+		_, errOther := rp.FindRecordById(context.Background(), 1, other.Id)
+		errUpdate := rp.UpdateRecord(context.Background(), internal.MaintenanceRecord{Id: other.Id, VehicleId: 1})
+		errDelete := rp.DeleteRecord(context.Background(), 1, other.Id)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []internal.MaintenanceRecord{early, late}, rc)
+		require.Equal(t, 1, late.Id)
+		require.ErrorIs(t, errOther, internal.ErrMaintenanceRecordNotFound)
+		require.ErrorIs(t, errUpdate, internal.ErrMaintenanceRecordNotFound)
+		require.ErrorIs(t, errDelete, internal.ErrMaintenanceRecordNotFound)
+	})
+
+	t.Run("case 2: the parts are copied and the records of a vehicle are deleted together", func(t *testing.T) {
+		// arrange
+		rp := repository.NewMaintenanceMap()
+		rc := maintenanceOn(1, "2024-01-01")
+		rc.Parts = []internal.MaintenancePart{{Name: "oil filter", Quantity: 1}}
+		created, _ := rp.CreateRecord(context.Background(), rc)
+		_, _ = rp.CreateRecord(context.Background(), maintenanceOn(1, "2024-02-01"))
+		_, _ = rp.CreateRecord(context.Background(), maintenanceOn(2, "2024-02-01"))
+		rc.Parts[0].Name = "air filter"
+
+		// act
+		found, errFind := rp.FindRecordById(context.Background(), 1, created.Id)
+		n, errDelete := rp.DeleteRecords(context.Background(), 1)
+
+		// assert
+		require.NoError(t, errFind)
+		require.Equal(t, "oil filter", found.Parts[0].Name)
+		require.NoError(t, errDelete)
+		require.Equal(t, 2, n)
+		left, _ := rp.FindRecords(context.Background(), 1)
+		require.Empty(t, left)
+		other, _ := rp.FindRecords(context.Background(), 2)
+		require.Len(t, other, 1)
+	})
+}
+
+// Tests for MaintenanceTenants
+func TestMaintenanceTenants(t *testing.T) {
+	t.Run("case 1: a tenant does not see the records of another one", func(t *testing.T) {
+		// arrange
+		acme := internal.ContextWithTenant(context.Background(), "acme")
+		globex := internal.ContextWithTenant(context.Background(), "globex")
+		rp := repository.NewMaintenanceTenants(map[string]internal.MaintenanceRepository{
+			"acme":   repository.NewMaintenanceMap(),
+			"globex": repository.NewMaintenanceMap(),
+		})
+		created, err := rp.CreateRecord(acme, maintenanceOn(1, "2024-01-01"))
+		require.NoError(t, err)
+
+		// act
+		rc, errAll := rp.FindRecords(globex, 1)
+		_, errById := rp.FindRecordById(globex, 1, created.Id)
+		_, errTenant := rp.FindRecords(context.Background(), 1)
+
+		// assert
+		require.NoError(t, errAll)
+		require.Empty(t, rc)
+		require.ErrorIs(t, errById, internal.ErrMaintenanceRecordNotFound)
+		require.ErrorIs(t, errTenant, internal.ErrTenantNotFound)
+	})
+}
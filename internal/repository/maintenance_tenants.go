@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+)
+
+// NewMaintenanceTenants is a function that returns a new instance of MaintenanceTenants
+func NewMaintenanceTenants(tenants map[string]internal.MaintenanceRepository) *MaintenanceTenants {
+	return &MaintenanceTenants{tenants: tenants}
+}
+
+// MaintenanceTenants is a struct that implements the MaintenanceRepository interface over the records of several
+// tenants: each operation is applied to the records of the tenant of its context only
+type MaintenanceTenants struct {
+	// tenants are the maintenance records of the tenants, by name
+	tenants map[string]internal.MaintenanceRepository
+}
+
+// CreateRecord is a method that registers a maintenance record, assigning its id
+func (r *MaintenanceTenants) CreateRecord(ctx context.Context, rc internal.MaintenanceRecord) (created internal.MaintenanceRecord, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.CreateRecord(ctx, rc)
+}
+
+// FindRecords is a method that returns the maintenance records of a vehicle, by date and id
+func (r *MaintenanceTenants) FindRecords(ctx context.Context, vehicleId int) (rc []internal.MaintenanceRecord, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindRecords(ctx, vehicleId)
+}
+
+// FindRecordById is a method that returns a maintenance record of a vehicle by id
+func (r *MaintenanceTenants) FindRecordById(ctx context.Context, vehicleId int, id int) (rc internal.MaintenanceRecord, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindRecordById(ctx, vehicleId, id)
+}
+
+// UpdateRecord is a method that replaces a maintenance record of a vehicle
+func (r *MaintenanceTenants) UpdateRecord(ctx context.Context, rc internal.MaintenanceRecord) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UpdateRecord(ctx, rc)
+}
+
+// DeleteRecord is a method that deletes a maintenance record of a vehicle
+func (r *MaintenanceTenants) DeleteRecord(ctx context.Context, vehicleId int, id int) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.DeleteRecord(ctx, vehicleId, id)
+}
+
+// DeleteRecords is a method that deletes all the maintenance records of a vehicle, returning how many there were
+func (r *MaintenanceTenants) DeleteRecords(ctx context.Context, vehicleId int) (n int, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.DeleteRecords(ctx, vehicleId)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"time"
+)
+
+// NewVehicleInstrumented is a function that returns a new instance of VehicleInstrumented
+func NewVehicleInstrumented(rp internal.VehicleRepository, observe func(method string, elapsed time.Duration)) *VehicleInstrumented {
+	return &VehicleInstrumented{rp: rp, observe: observe}
+}
+
+// VehicleInstrumented is a struct that decorates a vehicle repository to measure the latency of each method.
+// Every method is wrapped explicitly, so a new method of the interface can not skip the measurement.
+type VehicleInstrumented struct {
+	// rp is the decorated repository
+	rp internal.VehicleRepository
+	// observe is called with the name of the method and its latency once it returns
+	observe func(method string, elapsed time.Duration)
+}
+
+// measure is a method that observes the latency of a method started at start
+func (r *VehicleInstrumented) measure(method string, start time.Time) {
+	r.observe(method, time.Since(start))
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (r *VehicleInstrumented) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	defer r.measure("FindAll", time.Now())
+	return r.rp.FindAll(ctx)
+}
+
+// FindById is a method that returns a vehicle by id
+func (r *VehicleInstrumented) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	defer r.measure("FindById", time.Now())
+	return r.rp.FindById(ctx, id)
+}
+
+// FindLastId is a method that returns the id of the last vehicle registered
+func (r *VehicleInstrumented) FindLastId(ctx context.Context) (id int, err error) {
+	defer r.measure("FindLastId", time.Now())
+	return r.rp.FindLastId(ctx)
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (r *VehicleInstrumented) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	defer r.measure("CreateVehicle", time.Now())
+	return r.rp.CreateVehicle(ctx, v)
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles by color and year
+func (r *VehicleInstrumented) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	defer r.measure("FindByColorAndYear", time.Now())
+	return r.rp.FindByColorAndYear(ctx, color, year)
+}
+
+// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
+func (r *VehicleInstrumented) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	defer r.measure("FindAverageSpeedByBrand", time.Now())
+	return r.rp.FindAverageSpeedByBrand(ctx, brand)
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (r *VehicleInstrumented) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	defer r.measure("CreateVehicles", time.Now())
+	return r.rp.CreateVehicles(ctx, v)
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (r *VehicleInstrumented) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	defer r.measure("UpdateSpeed", time.Now())
+	return r.rp.UpdateSpeed(ctx, id, speed)
+}
+
+// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
+func (r *VehicleInstrumented) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	defer r.measure("FindByFuelType", time.Now())
+	return r.rp.FindByFuelType(ctx, fuelType)
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (r *VehicleInstrumented) DeleteVehicle(ctx context.Context, id int) (err error) {
+	defer r.measure("DeleteVehicle", time.Now())
+	return r.rp.DeleteVehicle(ctx, id)
+}
+
+// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type
+func (r *VehicleInstrumented) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	defer r.measure("FindByTransmissionType", time.Now())
+	return r.rp.FindByTransmissionType(ctx, transmissionType)
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (r *VehicleInstrumented) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	defer r.measure("UpdateFuel", time.Now())
+	return r.rp.UpdateFuel(ctx, id, fuelType)
+}
+
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehicleInstrumented) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	defer r.measure("UpdateVehicle", time.Now())
+	return r.rp.UpdateVehicle(ctx, v)
+}
+
+// FindByDimensions is a method that returns a list of vehicles according to their dimensions
+func (r *VehicleInstrumented) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	defer r.measure("FindByDimensions", time.Now())
+	return r.rp.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (r *VehicleInstrumented) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	defer r.measure("FindByTags", time.Now())
+	return r.rp.FindByTags(ctx, tags, match)
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (r *VehicleInstrumented) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	defer r.measure("FindTags", time.Now())
+	return r.rp.FindTags(ctx)
+}
+
+// FindByWeight is a method that returns a list of vehicles according to their weight
+func (r *VehicleInstrumented) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	defer r.measure("FindByWeight", time.Now())
+	return r.rp.FindByWeight(ctx, minWeight, maxWeight)
+}
+
+// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range
+func (r *VehicleInstrumented) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	defer r.measure("FindByBrandAndYearRange", time.Now())
+	return r.rp.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
+}
+
+// ReplaceAll is a method that atomically replaces all the vehicles
+func (r *VehicleInstrumented) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	defer r.measure("ReplaceAll", time.Now())
+	return r.rp.ReplaceAll(ctx, v)
+}
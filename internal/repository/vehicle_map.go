@@ -1,8 +1,10 @@
 package repository
 
-import (	
+import (
 	"app/internal"
-	"errors"
+	"context"
+	"fmt"
+	"sync"
 )
 
 // NewVehicleMap is a function that returns a new instance of VehicleMap
@@ -12,17 +14,28 @@ func NewVehicleMap(db map[int]internal.Vehicle) *VehicleMap {
 	if db != nil {
 		defaultDb = db
 	}
-	return &VehicleMap{db: defaultDb}
+	r := &VehicleMap{db: defaultDb}
+	r.reindex()
+	return r
 }
 
 // VehicleMap is a struct that represents a vehicle repository
 type VehicleMap struct {
+	// mu protects the db, so it can be read and replaced concurrently
+	mu sync.RWMutex
 	// db is a map of vehicles
 	db map[int]internal.Vehicle
+	// tags is the index of the ids of the vehicles by tag
+	tags map[string]map[int]struct{}
+	// lastId is the highest id ever registered, it does not go down when a vehicle is deleted
+	lastId int
 }
 
 // FindAll is a method that returns a map of all vehicles
-func (r *VehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
+func (r *VehicleMap) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	for key, value := range r.db {
@@ -33,30 +46,43 @@ func (r *VehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
 }
 
 // FindById is a method that returns a vehicle by id
-func (r *VehicleMap) FindById(id int) (v internal.Vehicle, err error) {
-v, ok := r.db[id]
+func (r *VehicleMap) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.db[id]
 	if !ok {
-		err = errors.New("vehicle not found")
+		err = internal.ErrVehicleNotFound
 	}
 	return
 }
 
-// FindLastId is a method that returns the last vehicle registered
-func (r *VehicleMap) FindLastId() (id int, err error) {
-	
-	for key := range r.db {
-		id = key
-	}
+// FindLastId is a method that returns the highest id ever registered, including the ones of the deleted vehicles,
+// so the next id allocated is not one of a vehicle in the trash
+func (r *VehicleMap) FindLastId(ctx context.Context) (id int, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id = r.lastId
 	return
 }
-// CreateVehicle is a method that registers a vehicle
-func (r *VehicleMap) CreateVehicle(v internal.Vehicle) (err error) {
-	r.db[v.Id] = v
+
+// CreateVehicle is a method that registers a vehicle, unless its id or its registration is already used
+func (r *VehicleMap) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.conflict(v, nil); err != nil {
+		return
+	}
+	r.put(v)
 	return
 }
 
 // FindByColorAndYear is a method that returns a map of vehicles by color and year
-func (r *VehicleMap) FindByColorAndYear(color string, year int) (v map[int]internal.Vehicle, err error) {
+func (r *VehicleMap) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	v = make(map[int]internal.Vehicle)
 
@@ -70,7 +96,10 @@ func (r *VehicleMap) FindByColorAndYear(color string, year int) (v map[int]inter
 }
 
 // FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
-func (r *VehicleMap) FindAverageSpeedByBrand(brand string) (averageSpeed float64, err error) {
+func (r *VehicleMap) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var sum float64
 	var count int
 
@@ -82,35 +111,57 @@ func (r *VehicleMap) FindAverageSpeedByBrand(brand string) (averageSpeed float64
 		}
 	}
 
+	if count == 0 {
+		err = internal.ErrNoVehiclesWithBrand
+		return
+	}
+
 	// calculate average speed
 	averageSpeed = sum / float64(count)
 	return
 }
 
-// CreateVehicles is a method that registers several vehicles at the same time
-func (r *VehicleMap) CreateVehicles(v []internal.Vehicle) (err error) {
+// CreateVehicles is a method that registers several vehicles at the same time.
+// None is registered if an id or a registration is already used, by a vehicle or by another of the batch.
+func (r *VehicleMap) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch := make(map[int]internal.Vehicle, len(v))
+	for _, value := range v {
+		if err = r.conflict(value, batch); err != nil {
+			return
+		}
+		batch[value.Id] = value
+	}
 	for _, value := range v {
-		r.db[value.Id] = value
+		r.put(value)
 	}
 	return
 }
 
 // UpdateSpeed is a method that updates the maximum speed of a specific vehicle
-func (r *VehicleMap) UpdateSpeed(id int, speed float64) (err error) {
+func (r *VehicleMap) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Check if the vehicle with the given ID exists
-    vehicle, exists := r.db[id]
+	vehicle, exists := r.db[id]
 	if !exists {
-        return internal.ErrVehicleNotFound
-    }
+		return internal.ErrVehicleNotFound
+	}
 
-    // Update the maximum speed
-    vehicle.MaxSpeed = speed
+	// Update the maximum speed
+	vehicle.MaxSpeed = speed
 	r.db[id] = vehicle
-    return
+	return
 }
 
 // FindByFuelType is a method that returns a list of vehicles according to the type of fuel
-func (r *VehicleMap) FindByFuelType(fuelType string) (v []internal.Vehicle, err error) {
+func (r *VehicleMap) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// copy db
 	for _, value := range r.db {
 		if value.FuelType == fuelType {
@@ -121,13 +172,19 @@ func (r *VehicleMap) FindByFuelType(fuelType string) (v []internal.Vehicle, err
 }
 
 // DeleteVehicle is a method that deletes a vehicle
-func (r *VehicleMap) DeleteVehicle(id int) (err error) {
-	delete(r.db, id)
+func (r *VehicleMap) DeleteVehicle(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(id)
 	return
 }
 
 // FindByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.)
-func (r *VehicleMap) FindByTransmissionType(transmissionType string) (v []internal.Vehicle, err error) {
+func (r *VehicleMap) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// copy db
 	for _, value := range r.db {
 		if value.Transmission == transmissionType {
@@ -138,7 +195,10 @@ func (r *VehicleMap) FindByTransmissionType(transmissionType string) (v []intern
 }
 
 // UpdateFuel is a method that updates the fuel type of a specific vehicle
-func (r *VehicleMap) UpdateFuel(id int, fuelType string) (err error) {
+func (r *VehicleMap) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Check if the vehicle with the given ID exists
 	vehicle, exists := r.db[id]
 	if !exists {
@@ -151,8 +211,26 @@ func (r *VehicleMap) UpdateFuel(id int, fuelType string) (err error) {
 	return nil
 }
 
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehicleMap) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.db[v.Id]; !exists {
+		return internal.ErrVehicleNotFound
+	}
+	if id, used := r.registered(v.Registration, v.Id, nil); used {
+		return fmt.Errorf("%w: the registration %q is used by the vehicle %d", internal.ErrVehicleAlreadyExists, v.Registration, id)
+	}
+	r.put(v)
+	return nil
+}
+
 // FindByDimensions is a method that returns a list of vehicles according to their dimensions (length, width)
-func (r *VehicleMap) FindByDimensions(minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+func (r *VehicleMap) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// copy db
 	for _, value := range r.db {
 		if value.Length >= minLength && value.Length <= maxLength && value.Width >= minWidth && value.Width <= maxWidth {
@@ -163,7 +241,10 @@ func (r *VehicleMap) FindByDimensions(minLength, maxLength, minWidth, maxWidth f
 }
 
 // FindByWeight is a method that returns a list of vehicles according to their weight (minWeight, maxWeight)
-func (r *VehicleMap) FindByWeight(minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+func (r *VehicleMap) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// for each vehicle in the db, check if the weight is between minWeight and maxWeight
 	for _, value := range r.db {
 		// if the weight is between minWeight and maxWeight, append the vehicle to the list of vehicles
@@ -176,7 +257,9 @@ func (r *VehicleMap) FindByWeight(minWeight, maxWeight float64) (v []internal.Ve
 }
 
 // FindByBrandAndYearRange is a method that returns a list of vehicles of a specific brand manufactured in a range of years
-func (r *VehicleMap) FindByBrandAndYearRange(brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+func (r *VehicleMap) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	for _, value := range r.db {
 		if value.Brand == brand && value.FabricationYear >= startYear && value.FabricationYear <= endYear {
@@ -185,3 +268,136 @@ func (r *VehicleMap) FindByBrandAndYearRange(brand string, startYear, endYear in
 	}
 	return
 }
+
+// ReplaceAll is a method that atomically replaces all the vehicles
+func (r *VehicleMap) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	db := make(map[int]internal.Vehicle, len(v))
+	for key, value := range v {
+		db[key] = value
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.db = db
+	r.reindex()
+	return
+}
+
+// conflict is a method that returns ErrVehicleAlreadyExists if the id or the registration of a new vehicle
+// is already used by a vehicle or by a vehicle of the batch
+func (r *VehicleMap) conflict(v internal.Vehicle, batch map[int]internal.Vehicle) (err error) {
+	_, exists := r.db[v.Id]
+	if _, inBatch := batch[v.Id]; exists || inBatch {
+		err = fmt.Errorf("%w: the id %d is used", internal.ErrVehicleAlreadyExists, v.Id)
+		return
+	}
+	if id, used := r.registered(v.Registration, v.Id, batch); used {
+		err = fmt.Errorf("%w: the registration %q is used by the vehicle %d", internal.ErrVehicleAlreadyExists, v.Registration, id)
+	}
+	return
+}
+
+// registered is a method that returns the id of the vehicle, other than self, using the registration.
+// The vehicles without registration do not conflict.
+func (r *VehicleMap) registered(registration string, self int, batch map[int]internal.Vehicle) (id int, used bool) {
+	if registration == "" {
+		return
+	}
+	for _, db := range []map[int]internal.Vehicle{r.db, batch} {
+		for key, value := range db {
+			if key != self && value.Registration == registration {
+				return key, true
+			}
+		}
+	}
+	return
+}
+
+// Health is a method that returns the status of the repository with its number of vehicles.
+// An empty repository is up: no vehicles is a valid state (e.g. after the last one is deleted).
+func (r *VehicleMap) Health() (h internal.ComponentHealth) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h = internal.ComponentHealth{
+		Status:  internal.HealthUp,
+		Details: map[string]any{"vehicles": len(r.db)},
+	}
+	return
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any),
+// from the tag index
+func (r *VehicleMap) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+	tags = internal.CleanTags(tags)
+
+	// count the tags of each vehicle among the tags queried
+	found := make(map[int]int)
+	for _, tag := range tags {
+		for id := range r.tags[tag] {
+			found[id]++
+		}
+	}
+	for id, count := range found {
+		if match == internal.TagMatchAny || count == len(tags) {
+			v[id] = r.db[id]
+		}
+	}
+	return
+}
+
+// FindTags is a method that returns the number of vehicles of each tag, from the tag index
+func (r *VehicleMap) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts = make(map[string]int, len(r.tags))
+	for tag, ids := range r.tags {
+		counts[tag] = len(ids)
+	}
+	return
+}
+
+// put is a method that stores a vehicle and indexes its tags. It must be called with the lock held.
+func (r *VehicleMap) put(v internal.Vehicle) {
+	r.remove(v.Id)
+	r.db[v.Id] = v
+	r.index(v)
+	r.lastId = max(r.lastId, v.Id)
+}
+
+// remove is a method that deletes a vehicle and its tags from the index. It must be called with the lock held.
+func (r *VehicleMap) remove(id int) {
+	for _, tag := range r.db[id].Tags {
+		delete(r.tags[tag], id)
+		if len(r.tags[tag]) == 0 {
+			delete(r.tags, tag)
+		}
+	}
+	delete(r.db, id)
+}
+
+// reindex is a method that builds the tag index of all the vehicles and raises the last id to theirs.
+// It must be called with the lock held.
+func (r *VehicleMap) reindex() {
+	r.tags = make(map[string]map[int]struct{})
+	for _, v := range r.db {
+		r.index(v)
+		r.lastId = max(r.lastId, v.Id)
+	}
+}
+
+// index is a method that adds the tags of a vehicle to the index. It must be called with the lock held.
+func (r *VehicleMap) index(v internal.Vehicle) {
+	for _, tag := range v.Tags {
+		if r.tags[tag] == nil {
+			r.tags[tag] = make(map[int]struct{})
+		}
+		r.tags[tag][v.Id] = struct{}{}
+	}
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleMap
+func TestVehicleMap_Health(t *testing.T) {
+	t.Run("case 1: an empty repository is up and reports its number of vehicles", func(t *testing.T) {
+		// arrange
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}})
+		require.NoError(t, rp.DeleteVehicle(context.Background(), 1))
+
+		// act
+		h := rp.Health()
+
+		// assert
+		require.Equal(t, internal.HealthUp, h.Status)
+		require.Empty(t, h.Message)
+		require.Equal(t, 0, h.Details["vehicles"])
+	})
+}
+
+// Tests for VehicleMap.FindLastId
+func TestVehicleMap_FindLastId(t *testing.T) {
+	t.Run("case 1: the last id does not go down when the vehicle with the highest id is deleted or replaced", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}, 7: {Id: 7}})
+		require.NoError(t, rp.CreateVehicle(ctx, internal.Vehicle{Id: 9}))
+
+		// act
+		require.NoError(t, rp.DeleteVehicle(ctx, 9))
+		afterDelete, errDelete := rp.FindLastId(ctx)
+		require.NoError(t, rp.ReplaceAll(ctx, map[int]internal.Vehicle{2: {Id: 2}}))
+		afterReplace, errReplace := rp.FindLastId(ctx)
+
+		// assert
+		require.NoError(t, errDelete)
+		require.Equal(t, 9, afterDelete)
+		require.NoError(t, errReplace)
+		require.Equal(t, 9, afterReplace)
+	})
+}
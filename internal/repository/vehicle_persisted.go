@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NewVehiclePersisted is a function that returns a new instance of VehiclePersisted
+func NewVehiclePersisted(rp internal.VehicleRepository, path string, write func(w io.Writer, v map[int]internal.Vehicle) (err error)) *VehiclePersisted {
+	return &VehiclePersisted{
+		VehicleRepository: rp,
+		path:              path,
+		write:             write,
+	}
+}
+
+// VehiclePersisted is a struct that decorates a vehicle repository to persist its vehicles in a file.
+// The mutations mark the repository as dirty and the changes are written on each Flush.
+type VehiclePersisted struct {
+	// VehicleRepository is the decorated repository, its queries are used as is
+	internal.VehicleRepository
+	// path is the file where the vehicles are persisted
+	path string
+	// write is the function that serializes the vehicles
+	write func(w io.Writer, v map[int]internal.Vehicle) (err error)
+	// dirty is true when there are changes not flushed yet
+	dirty atomic.Bool
+	// mu serializes the flushes
+	mu sync.Mutex
+	// muState protects the result of the last flush
+	muState sync.RWMutex
+	// flushedAt is the time of the last successful flush
+	flushedAt time.Time
+	// errFlush is the error of the last flush, nil if it succeeded
+	errFlush error
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (r *VehiclePersisted) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	err = r.VehicleRepository.CreateVehicle(ctx, v)
+	r.touch(err)
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (r *VehiclePersisted) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	err = r.VehicleRepository.CreateVehicles(ctx, v)
+	r.touch(err)
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (r *VehiclePersisted) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	err = r.VehicleRepository.UpdateSpeed(ctx, id, speed)
+	r.touch(err)
+	return
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (r *VehiclePersisted) DeleteVehicle(ctx context.Context, id int) (err error) {
+	err = r.VehicleRepository.DeleteVehicle(ctx, id)
+	r.touch(err)
+	return
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (r *VehiclePersisted) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	err = r.VehicleRepository.UpdateFuel(ctx, id, fuelType)
+	r.touch(err)
+	return
+}
+
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehiclePersisted) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	err = r.VehicleRepository.UpdateVehicle(ctx, v)
+	r.touch(err)
+	return
+}
+
+// ReplaceAll is a method that atomically replaces all the vehicles
+func (r *VehiclePersisted) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	err = r.VehicleRepository.ReplaceAll(ctx, v)
+	r.touch(err)
+	return
+}
+
+// touch is a method that marks the repository as dirty after a successful mutation
+func (r *VehiclePersisted) touch(err error) {
+	if err == nil {
+		r.dirty.Store(true)
+	}
+}
+
+// Dirty is a method that returns true when there are changes not flushed yet
+func (r *VehiclePersisted) Dirty() bool {
+	return r.dirty.Load()
+}
+
+// Flush is a method that writes the vehicles to the file if there are changes.
+// The file is replaced atomically, so a failed flush keeps the previous one.
+func (r *VehiclePersisted) Flush() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dirty.Swap(false) {
+		return
+	}
+	defer func() {
+		// the changes are still pending
+		if err != nil {
+			r.dirty.Store(true)
+		}
+		r.muState.Lock()
+		r.errFlush = err
+		if err == nil {
+			r.flushedAt = time.Now()
+		}
+		r.muState.Unlock()
+	}()
+
+	v, err := r.VehicleRepository.FindAll(context.Background())
+	if err != nil {
+		return
+	}
+
+	// write a temporary file next to the target and rename it
+	file, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	if err = r.write(file, v); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	err = os.Rename(file.Name(), r.path)
+	return
+}
+
+// Health is a method that returns the status of the persistence, which is down after a failed flush
+// until a flush succeeds again
+func (r *VehiclePersisted) Health() (h internal.ComponentHealth) {
+	r.muState.RLock()
+	defer r.muState.RUnlock()
+
+	h = internal.ComponentHealth{
+		Status: internal.HealthUp,
+		Details: map[string]any{
+			"mode":  internal.StorageModeFile,
+			"path":  r.path,
+			"dirty": r.Dirty(),
+		},
+	}
+	if !r.flushedAt.IsZero() {
+		h.Details["flushed_at"] = r.flushedAt
+	}
+	if r.errFlush != nil {
+		h.Status = internal.HealthDown
+		h.Message = "the last flush failed: " + r.errFlush.Error()
+	}
+	return
+}
+
+// Run is a method that flushes the changes every interval until the context is done
+func (r *VehiclePersisted) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				slog.ErrorContext(ctx, "storage: flush failed", "path", r.path, "error", err)
+			}
+		}
+	}
+}
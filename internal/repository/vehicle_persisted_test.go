@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeIds is a function that serializes the ids of the vehicles
+func writeIds(w io.Writer, v map[int]internal.Vehicle) (err error) {
+	ids := make(map[int]string, len(v))
+	for id, vh := range v {
+		ids[id] = vh.Registration
+	}
+	err = json.NewEncoder(w).Encode(ids)
+	return
+}
+
+// Tests for VehiclePersisted
+func TestVehiclePersisted_Flush(t *testing.T) {
+	t.Run("case 1: the changes are written only when the repository is dirty", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "vehicles.json")
+		rp := repository.NewVehiclePersisted(repository.NewVehicleMap(nil), path, writeIds)
+
+		// act
+		errClean := rp.Flush()
+		_, errStat := os.Stat(path)
+		errCreate := rp.CreateVehicle(context.Background(), internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Registration: "AB123"}})
+		dirty := rp.Dirty()
+		errFlush := rp.Flush()
+
+		// assert
+		require.NoError(t, errClean)
+		require.ErrorIs(t, errStat, os.ErrNotExist)
+		require.NoError(t, errCreate)
+		require.True(t, dirty)
+		require.NoError(t, errFlush)
+		require.False(t, rp.Dirty())
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"1":"AB123"}`, string(data))
+	})
+
+	t.Run("case 2: a failed flush keeps the changes pending", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "missing", "vehicles.json")
+		rp := repository.NewVehiclePersisted(repository.NewVehicleMap(nil), path, writeIds)
+		require.NoError(t, rp.CreateVehicle(context.Background(), internal.Vehicle{Id: 1}))
+
+		// act
+		err := rp.Flush()
+
+		// assert
+		require.Error(t, err)
+		require.True(t, rp.Dirty())
+		require.Equal(t, internal.HealthDown, rp.Health().Status)
+	})
+}
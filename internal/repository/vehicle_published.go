@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"sync"
+)
+
+// NewVehiclePublished is a function that returns a new instance of VehiclePublished
+func NewVehiclePublished(rp internal.VehicleRepository, pb internal.VehicleEventPublisher) *VehiclePublished {
+	return &VehiclePublished{rp: rp, pb: pb}
+}
+
+// VehiclePublished is a struct that decorates a vehicle repository to publish an event for each change that succeeds.
+// The changes are serialized, so the events are published in the order they are applied
+// and the diff of an update is not mixed with another change of the same vehicle.
+type VehiclePublished struct {
+	// rp is the decorated repository
+	rp internal.VehicleRepository
+	// pb is the destination of the events
+	pb internal.VehicleEventPublisher
+	// mu serializes the changes
+	mu sync.Mutex
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (r *VehiclePublished) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	return r.rp.FindAll(ctx)
+}
+
+// FindById is a method that returns a vehicle by id
+func (r *VehiclePublished) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	return r.rp.FindById(ctx, id)
+}
+
+// FindLastId is a method that returns the id of the last vehicle registered
+func (r *VehiclePublished) FindLastId(ctx context.Context) (id int, err error) {
+	return r.rp.FindLastId(ctx)
+}
+
+// CreateVehicle is a method that registers a vehicle and publishes a created event
+func (r *VehiclePublished) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.rp.CreateVehicle(ctx, v); err != nil {
+		return
+	}
+	r.pb.Publish(ctx, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: v.Id, Vehicle: v})
+	return
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles by color and year
+func (r *VehiclePublished) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	return r.rp.FindByColorAndYear(ctx, color, year)
+}
+
+// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
+func (r *VehiclePublished) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	return r.rp.FindAverageSpeedByBrand(ctx, brand)
+}
+
+// CreateVehicles is a method that registers several vehicles and publishes a created event for each one
+func (r *VehiclePublished) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.rp.CreateVehicles(ctx, v); err != nil {
+		return
+	}
+	events := make([]internal.VehicleEvent, len(v))
+	for ix, vh := range v {
+		events[ix] = internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: vh.Id, Vehicle: vh}
+	}
+	r.pb.Publish(ctx, events...)
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a vehicle and publishes an updated event
+func (r *VehiclePublished) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	return r.update(ctx, id, func() error { return r.rp.UpdateSpeed(ctx, id, speed) })
+}
+
+// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
+func (r *VehiclePublished) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	return r.rp.FindByFuelType(ctx, fuelType)
+}
+
+// DeleteVehicle is a method that deletes a vehicle and publishes a deleted event with its last state
+func (r *VehiclePublished) DeleteVehicle(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.rp.FindById(ctx, id)
+	found := err == nil
+	if err != nil && !errors.Is(err, internal.ErrVehicleNotFound) {
+		return
+	}
+	if err = r.rp.DeleteVehicle(ctx, id); err != nil || !found {
+		return
+	}
+	r.pb.Publish(ctx, internal.VehicleEvent{Type: internal.VehicleEventDeleted, VehicleId: id, Vehicle: before})
+	return
+}
+
+// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type
+func (r *VehiclePublished) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	return r.rp.FindByTransmissionType(ctx, transmissionType)
+}
+
+// UpdateFuel is a method that updates the fuel type of a vehicle and publishes an updated event
+func (r *VehiclePublished) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	return r.update(ctx, id, func() error { return r.rp.UpdateFuel(ctx, id, fuelType) })
+}
+
+// UpdateVehicle is a method that replaces the attributes of a vehicle and publishes an updated event
+func (r *VehiclePublished) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	return r.update(ctx, v.Id, func() error { return r.rp.UpdateVehicle(ctx, v) })
+}
+
+// FindByDimensions is a method that returns a list of vehicles according to their dimensions
+func (r *VehiclePublished) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	return r.rp.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (r *VehiclePublished) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	return r.rp.FindByTags(ctx, tags, match)
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (r *VehiclePublished) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	return r.rp.FindTags(ctx)
+}
+
+// FindByWeight is a method that returns a list of vehicles according to their weight
+func (r *VehiclePublished) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	return r.rp.FindByWeight(ctx, minWeight, maxWeight)
+}
+
+// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range
+func (r *VehiclePublished) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	return r.rp.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
+}
+
+// ReplaceAll is a method that replaces all the vehicles and publishes a single replaced event,
+// as the subscribers are expected to read the vehicles again rather than receive one event per vehicle
+func (r *VehiclePublished) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.rp.ReplaceAll(ctx, v); err != nil {
+		return
+	}
+	r.pb.Publish(ctx, internal.VehicleEvent{Type: internal.VehicleEventReplaced, Count: len(v)})
+	return
+}
+
+// update is a method that applies an update to a vehicle and publishes the attributes it changed.
+// An update that changes nothing publishes no event.
+func (r *VehiclePublished) update(ctx context.Context, id int, apply func() error) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.rp.FindById(ctx, id)
+	if err != nil {
+		// the decorated repository reports the missing vehicle the same way
+		return apply()
+	}
+	if err = apply(); err != nil {
+		return
+	}
+	after, e := r.rp.FindById(ctx, id)
+	if e != nil {
+		// the update is applied, only its event is lost
+		return
+	}
+	changes := internal.VehicleChanges(before.VehicleAttributes, after.VehicleAttributes)
+	if len(changes) == 0 {
+		return
+	}
+	r.pb.Publish(ctx, internal.VehicleEvent{Type: internal.VehicleEventUpdated, VehicleId: id, Vehicle: after, Changes: changes})
+	return
+}
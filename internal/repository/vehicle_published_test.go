@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// publisherStub is a struct that keeps the events published
+type publisherStub struct {
+	events []internal.VehicleEvent
+}
+
+// Publish is a method that keeps the events
+func (p *publisherStub) Publish(ctx context.Context, events ...internal.VehicleEvent) {
+	p.events = append(p.events, events...)
+}
+
+// Tests for VehiclePublished
+func TestVehiclePublished(t *testing.T) {
+	ford := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", MaxSpeed: 180, FuelType: "gasoline"}}
+
+	t.Run("case 1: the changes publish created, updated with the diff and deleted events", func(t *testing.T) {
+		// arrange
+		pb := &publisherStub{}
+		rp := repository.NewVehiclePublished(repository.NewVehicleMap(nil), pb)
+		ctx := context.Background()
+
+		// act
+		require.NoError(t, rp.CreateVehicle(ctx, ford))
+		require.NoError(t, rp.UpdateSpeed(ctx, 1, 200))
+		require.NoError(t, rp.UpdateFuel(ctx, 1, "diesel"))
+		require.NoError(t, rp.DeleteVehicle(ctx, 1))
+
+		// assert
+		updated := ford
+		updated.MaxSpeed, updated.FuelType = 200, "diesel"
+		require.Equal(t, []internal.VehicleEvent{
+			{Type: internal.VehicleEventCreated, VehicleId: 1, Vehicle: ford},
+			{Type: internal.VehicleEventUpdated, VehicleId: 1, Vehicle: internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", MaxSpeed: 200, FuelType: "gasoline"}},
+				Changes: map[string]internal.FieldChange{"max_speed": {From: 180.0, To: 200.0}}},
+			{Type: internal.VehicleEventUpdated, VehicleId: 1, Vehicle: updated,
+				Changes: map[string]internal.FieldChange{"fuel_type": {From: "gasoline", To: "diesel"}}},
+			{Type: internal.VehicleEventDeleted, VehicleId: 1, Vehicle: updated},
+		}, pb.events)
+	})
+
+	t.Run("case 2: failed or empty changes publish nothing", func(t *testing.T) {
+		// arrange
+		pb := &publisherStub{}
+		rp := repository.NewVehiclePublished(repository.NewVehicleMap(map[int]internal.Vehicle{1: ford}), pb)
+		ctx := context.Background()
+
+		// act
+		errUpdate := rp.UpdateSpeed(ctx, 2, 200)
+		errSame := rp.UpdateSpeed(ctx, 1, 180)
+		errDelete := rp.DeleteVehicle(ctx, 2)
+
+		// assert
+		require.ErrorIs(t, errUpdate, internal.ErrVehicleNotFound)
+		require.NoError(t, errSame)
+		require.NoError(t, errDelete)
+		require.Empty(t, pb.events)
+	})
+
+	t.Run("case 3: a batch publishes one event per vehicle and a replacement a single event", func(t *testing.T) {
+		// arrange
+		pb := &publisherStub{}
+		rp := repository.NewVehiclePublished(repository.NewVehicleMap(nil), pb)
+		ctx := context.Background()
+		fiat := internal.Vehicle{Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat"}}
+
+		// act
+		require.NoError(t, rp.CreateVehicles(ctx, []internal.Vehicle{ford, fiat}))
+		require.NoError(t, rp.ReplaceAll(ctx, map[int]internal.Vehicle{1: ford}))
+
+		// assert
+		require.Equal(t, []internal.VehicleEvent{
+			{Type: internal.VehicleEventCreated, VehicleId: 1, Vehicle: ford},
+			{Type: internal.VehicleEventCreated, VehicleId: 2, Vehicle: fiat},
+			{Type: internal.VehicleEventReplaced, Count: 1},
+		}, pb.events)
+	})
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewVehicleTemporal is a function that returns a new instance of VehicleTemporal
+func NewVehicleTemporal(rp internal.VehicleRepository) *VehicleTemporal {
+	return &VehicleTemporal{
+		VehicleRepository: rp,
+		versions:          make(map[int][]internal.VehicleVersion),
+		now:               time.Now,
+	}
+}
+
+// VehicleTemporal is a struct that decorates a vehicle repository to keep every version of each vehicle,
+// so the vehicles can be read as they were at a time. A version is written for each change that succeeds,
+// including the deletions and the vehicles changed by a replacement of all of them (e.g. a reload).
+// The versions are kept in memory, so the history starts with the first load of the process.
+type VehicleTemporal struct {
+	// VehicleRepository is the decorated repository
+	internal.VehicleRepository
+	// mu serializes the changes and protects the versions
+	mu sync.RWMutex
+	// versions are the versions of each vehicle, oldest first
+	versions map[int][]internal.VehicleVersion
+	// now is the clock of the versions
+	now func() time.Time
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (r *VehicleTemporal) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.VehicleRepository.CreateVehicle(ctx, v); err != nil {
+		return
+	}
+	r.write(r.now(), v, false)
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (r *VehicleTemporal) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.VehicleRepository.CreateVehicles(ctx, v); err != nil {
+		return
+	}
+	at := r.now()
+	for _, vh := range v {
+		r.write(at, vh, false)
+	}
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (r *VehicleTemporal) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	return r.update(ctx, id, func() error { return r.VehicleRepository.UpdateSpeed(ctx, id, speed) })
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (r *VehicleTemporal) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	return r.update(ctx, id, func() error { return r.VehicleRepository.UpdateFuel(ctx, id, fuelType) })
+}
+
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehicleTemporal) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	return r.update(ctx, v.Id, func() error { return r.VehicleRepository.UpdateVehicle(ctx, v) })
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (r *VehicleTemporal) DeleteVehicle(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, errFind := r.VehicleRepository.FindById(ctx, id)
+	if err = r.VehicleRepository.DeleteVehicle(ctx, id); err != nil || errFind != nil {
+		return
+	}
+	r.write(r.now(), before, true)
+	return
+}
+
+// ReplaceAll is a method that replaces all the vehicles, writing a version of each vehicle that changed,
+// appeared or disappeared
+func (r *VehicleTemporal) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.VehicleRepository.FindAll(ctx)
+	if err != nil {
+		return
+	}
+	if err = r.VehicleRepository.ReplaceAll(ctx, v); err != nil {
+		return
+	}
+	at := r.now()
+	for id, vh := range v {
+		if old, ok := before[id]; !ok || !old.Equal(vh) {
+			r.write(at, vh, false)
+		}
+	}
+	for id, old := range before {
+		if _, ok := v[id]; !ok {
+			r.write(at, old, true)
+		}
+	}
+	return
+}
+
+// FindAllAsOf is a method that returns the vehicles as they were at a time
+func (r *VehicleTemporal) FindAllAsOf(ctx context.Context, at time.Time) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+	for id, versions := range r.versions {
+		if vr, ok := versionAt(versions, at); ok && !vr.Deleted {
+			v[id] = vr.Vehicle
+		}
+	}
+	return
+}
+
+// FindByIdAsOf is a method that returns a vehicle as it was at a time
+func (r *VehicleTemporal) FindByIdAsOf(ctx context.Context, id int, at time.Time) (v internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vr, ok := versionAt(r.versions[id], at)
+	if !ok || vr.Deleted {
+		err = internal.ErrVehicleNotFound
+		return
+	}
+	v = vr.Vehicle
+	return
+}
+
+// FindVersions is a method that returns the versions of a vehicle, oldest first
+func (r *VehicleTemporal) FindVersions(ctx context.Context, id int) (versions []internal.VehicleVersion, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.versions[id]) == 0 {
+		err = internal.ErrVehicleNotFound
+		return
+	}
+	versions = slices.Clone(r.versions[id])
+	return
+}
+
+// update is a method that runs an update of a vehicle and writes the new version, if anything changed
+func (r *VehicleTemporal) update(ctx context.Context, id int, fn func() error) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = fn(); err != nil {
+		return
+	}
+	after, e := r.VehicleRepository.FindById(ctx, id)
+	if e != nil {
+		return
+	}
+	if versions := r.versions[id]; len(versions) > 0 && !versions[len(versions)-1].Deleted && versions[len(versions)-1].Vehicle.Equal(after) {
+		return
+	}
+	r.write(r.now(), after, false)
+	return
+}
+
+// write is a method that appends a version of a vehicle
+func (r *VehicleTemporal) write(at time.Time, v internal.Vehicle, deleted bool) {
+	r.versions[v.Id] = append(r.versions[v.Id], internal.VehicleVersion{
+		Version:   len(r.versions[v.Id]) + 1,
+		Vehicle:   v,
+		Deleted:   deleted,
+		ValidFrom: at,
+	})
+}
+
+// versionAt is a function that returns the version valid at a time, false if there was none yet
+func versionAt(versions []internal.VehicleVersion, at time.Time) (vr internal.VehicleVersion, ok bool) {
+	// the versions are sorted by time, the last one written at or before the time is valid
+	ix, _ := slices.BinarySearchFunc(versions, at, func(vr internal.VehicleVersion, at time.Time) int {
+		if vr.ValidFrom.After(at) {
+			return 1
+		}
+		return -1
+	})
+	if ix == 0 {
+		return
+	}
+	return versions[ix-1], true
+}
@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleTemporal
+func TestVehicleTemporal(t *testing.T) {
+	t.Run("case 1: a replacement writes a version of the vehicles changed, added and removed only", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewVehicleTemporal(repository.NewVehicleMap(nil))
+		require.NoError(t, rp.ReplaceAll(ctx, map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat"}},
+			3: {Id: 3, VehicleAttributes: internal.VehicleAttributes{Brand: "Kia"}},
+		}))
+
+		// act
+		err := rp.ReplaceAll(ctx, map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat", Color: "red"}},
+			4: {Id: 4, VehicleAttributes: internal.VehicleAttributes{Brand: "Seat"}},
+		})
+
+		// assert
+		require.NoError(t, err)
+		unchanged, _ := rp.FindVersions(ctx, 1)
+		require.Len(t, unchanged, 1)
+		changed, _ := rp.FindVersions(ctx, 2)
+		require.Len(t, changed, 2)
+		require.Equal(t, "red", changed[1].Vehicle.Color)
+		removed, _ := rp.FindVersions(ctx, 3)
+		require.Len(t, removed, 2)
+		require.True(t, removed[1].Deleted)
+		require.Equal(t, "Kia", removed[1].Vehicle.Brand)
+		added, _ := rp.FindVersions(ctx, 4)
+		require.Len(t, added, 1)
+	})
+
+	t.Run("case 2: an update that changes nothing writes no version", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewVehicleTemporal(repository.NewVehicleMap(nil))
+		require.NoError(t, rp.CreateVehicle(ctx, internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{MaxSpeed: 100}}))
+
+		// act
+		err := rp.UpdateSpeed(ctx, 1, 100)
+		errMissing := rp.UpdateSpeed(ctx, 2, 100)
+
+		// assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errMissing, internal.ErrVehicleNotFound)
+		versions, _ := rp.FindVersions(ctx, 1)
+		require.Len(t, versions, 1)
+		_, errNone := rp.FindVersions(ctx, 2)
+		require.ErrorIs(t, errNone, internal.ErrVehicleNotFound)
+	})
+}
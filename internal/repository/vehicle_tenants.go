@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// NewVehicleTenants is a function that returns a new instance of VehicleTenants
+func NewVehicleTenants(tenants map[string]*VehicleTemporal) *VehicleTenants {
+	return &VehicleTenants{tenants: tenants}
+}
+
+// VehicleTenants is a struct that implements the VehicleRepository and VehicleHistory interfaces over the datasets
+// of several tenants: each operation is applied to the dataset of the tenant of its context only, so the ids,
+// the registrations and the versions of a tenant are neither seen nor checked by the other ones.
+// The tenants are fixed when it is created.
+type VehicleTenants struct {
+	// tenants are the datasets of the tenants, by name
+	tenants map[string]*VehicleTemporal
+}
+
+// Tenants is a method that returns the names of the tenants, sorted
+func (r *VehicleTenants) Tenants() (names []string) {
+	for name := range r.tenants {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return
+}
+
+// Has is a method that returns true if the tenant is served
+func (r *VehicleTenants) Has(tenant string) bool {
+	_, ok := r.tenants[tenant]
+	return ok
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (r *VehicleTenants) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindAll(ctx)
+}
+
+// FindById is a method that returns a vehicle by id
+func (r *VehicleTenants) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindById(ctx, id)
+}
+
+// FindLastId is a method that returns the id of the last vehicle registered
+func (r *VehicleTenants) FindLastId(ctx context.Context) (id int, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindLastId(ctx)
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (r *VehicleTenants) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.CreateVehicle(ctx, v)
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles by color and year
+func (r *VehicleTenants) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByColorAndYear(ctx, color, year)
+}
+
+// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
+func (r *VehicleTenants) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindAverageSpeedByBrand(ctx, brand)
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (r *VehicleTenants) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.CreateVehicles(ctx, v)
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (r *VehicleTenants) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UpdateSpeed(ctx, id, speed)
+}
+
+// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
+func (r *VehicleTenants) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByFuelType(ctx, fuelType)
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (r *VehicleTenants) DeleteVehicle(ctx context.Context, id int) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.DeleteVehicle(ctx, id)
+}
+
+// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type
+func (r *VehicleTenants) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByTransmissionType(ctx, transmissionType)
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (r *VehicleTenants) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UpdateFuel(ctx, id, fuelType)
+}
+
+// FindByDimensions is a method that returns a list of vehicles according to their dimensions (length, width)
+func (r *VehicleTenants) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (r *VehicleTenants) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByTags(ctx, tags, match)
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (r *VehicleTenants) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindTags(ctx)
+}
+
+// FindByWeight is a method that returns a list of vehicles according to their weight (minWeight, maxWeight)
+func (r *VehicleTenants) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByWeight(ctx, minWeight, maxWeight)
+}
+
+// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range
+func (r *VehicleTenants) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
+}
+
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehicleTenants) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.UpdateVehicle(ctx, v)
+}
+
+// ReplaceAll is a method that atomically replaces all the vehicles of the tenant
+func (r *VehicleTenants) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.ReplaceAll(ctx, v)
+}
+
+// FindAllAsOf is a method that returns the vehicles as they were at a time
+func (r *VehicleTenants) FindAllAsOf(ctx context.Context, at time.Time) (v map[int]internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindAllAsOf(ctx, at)
+}
+
+// FindByIdAsOf is a method that returns a vehicle as it was at a time
+func (r *VehicleTenants) FindByIdAsOf(ctx context.Context, id int, at time.Time) (v internal.Vehicle, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindByIdAsOf(ctx, id, at)
+}
+
+// FindVersions is a method that returns the versions of a vehicle, oldest first
+func (r *VehicleTenants) FindVersions(ctx context.Context, id int) (versions []internal.VehicleVersion, err error) {
+	rp, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return rp.FindVersions(ctx, id)
+}
+
+// tenantOf is a function that returns the storage of the tenant of the context, ErrTenantNotFound if it is not served
+func tenantOf[T any](ctx context.Context, tenants map[string]T) (st T, err error) {
+	tenant := internal.Tenant(ctx)
+	st, ok := tenants[tenant]
+	if !ok {
+		err = fmt.Errorf("%w: %q", internal.ErrTenantNotFound, tenant)
+	}
+	return
+}
@@ -0,0 +1,148 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newVehicleTenants is a function that returns the repository of the tenants acme and globex, with empty datasets
+func newVehicleTenants() *repository.VehicleTenants {
+	return repository.NewVehicleTenants(map[string]*repository.VehicleTemporal{
+		"acme":   repository.NewVehicleTemporal(repository.NewVehicleMap(nil)),
+		"globex": repository.NewVehicleTemporal(repository.NewVehicleMap(nil)),
+	})
+}
+
+// vehicleRegistered is a function that returns a vehicle with its registration
+func vehicleRegistered(id int, registration, brand string) internal.Vehicle {
+	return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Registration: registration, Brand: brand}}
+}
+
+// Tests for VehicleTenants
+func TestVehicleTenants(t *testing.T) {
+	acme := internal.ContextWithTenant(context.Background(), "acme")
+	globex := internal.ContextWithTenant(context.Background(), "globex")
+
+	t.Run("case 1: a tenant does not see the vehicles of another one", func(t *testing.T) {
+		// arrange
+		rp := newVehicleTenants()
+		require.NoError(t, rp.CreateVehicle(acme, vehicleRegistered(1, "AB123", "Ford")))
+
+		// act
+		vAcme, errAcme := rp.FindAll(acme)
+		vGlobex, errGlobex := rp.FindAll(globex)
+		_, errById := rp.FindById(globex, 1)
+		byBrand, _ := rp.FindByBrandAndYearRange(globex, "Ford", 0, 3000)
+
+		// assert
+		require.NoError(t, errAcme)
+		require.Len(t, vAcme, 1)
+		require.NoError(t, errGlobex)
+		require.Empty(t, vGlobex)
+		require.ErrorIs(t, errById, internal.ErrVehicleNotFound)
+		require.Empty(t, byBrand)
+	})
+
+	t.Run("case 2: a tenant can not change nor delete the vehicles of another one", func(t *testing.T) {
+		// arrange
+		rp := newVehicleTenants()
+		require.NoError(t, rp.CreateVehicle(acme, vehicleRegistered(1, "AB123", "Ford")))
+
+		// act
+		errSpeed := rp.UpdateSpeed(globex, 1, 250)
+		errUpdate := rp.UpdateVehicle(globex, vehicleRegistered(1, "AB123", "Fiat"))
+		errReplace := rp.ReplaceAll(globex, map[int]internal.Vehicle{})
+		_ = rp.DeleteVehicle(globex, 1)
+
+		// assert
+		require.ErrorIs(t, errSpeed, internal.ErrVehicleNotFound)
+		require.ErrorIs(t, errUpdate, internal.ErrVehicleNotFound)
+		require.NoError(t, errReplace)
+		v, err := rp.FindById(acme, 1)
+		require.NoError(t, err)
+		require.Equal(t, vehicleRegistered(1, "AB123", "Ford"), v)
+	})
+
+	t.Run("case 3: the ids and the registrations are unique per tenant", func(t *testing.T) {
+		// arrange
+		rp := newVehicleTenants()
+		require.NoError(t, rp.CreateVehicle(acme, vehicleRegistered(1, "AB123", "Ford")))
+
+		// act
+		errSameTenantId := rp.CreateVehicle(acme, vehicleRegistered(1, "CD456", "Fiat"))
+		errSameTenantRegistration := rp.CreateVehicle(acme, vehicleRegistered(2, "AB123", "Fiat"))
+		errBatch := rp.CreateVehicles(acme, []internal.Vehicle{vehicleRegistered(3, "EF789", "Kia"), vehicleRegistered(4, "EF789", "Kia")})
+		errOtherTenant := rp.CreateVehicle(globex, vehicleRegistered(1, "AB123", "Seat"))
+
+		// assert
+		require.ErrorIs(t, errSameTenantId, internal.ErrVehicleAlreadyExists)
+		require.ErrorIs(t, errSameTenantRegistration, internal.ErrVehicleAlreadyExists)
+		require.ErrorIs(t, errBatch, internal.ErrVehicleAlreadyExists)
+		require.NoError(t, errOtherTenant)
+		vAcme, _ := rp.FindAll(acme)
+		require.Equal(t, map[int]internal.Vehicle{1: vehicleRegistered(1, "AB123", "Ford")}, vAcme)
+		vGlobex, _ := rp.FindAll(globex)
+		require.Equal(t, map[int]internal.Vehicle{1: vehicleRegistered(1, "AB123", "Seat")}, vGlobex)
+	})
+
+	t.Run("case 4: the versions of a tenant are not seen by another one", func(t *testing.T) {
+		// arrange
+		rp := newVehicleTenants()
+		require.NoError(t, rp.CreateVehicle(acme, vehicleRegistered(1, "AB123", "Ford")))
+
+		// act
+		_, errVersions := rp.FindVersions(globex, 1)
+		asOf, errAsOf := rp.FindAllAsOf(globex, time.Now())
+
+		// assert
+		require.ErrorIs(t, errVersions, internal.ErrVehicleNotFound)
+		require.NoError(t, errAsOf)
+		require.Empty(t, asOf)
+	})
+
+	t.Run("case 5: an unknown tenant is rejected", func(t *testing.T) {
+		// arrange
+		rp := newVehicleTenants()
+
+		// act
+		_, errFind := rp.FindAll(context.Background())
+		errCreate := rp.CreateVehicle(internal.ContextWithTenant(context.Background(), "initech"), vehicleRegistered(1, "AB123", "Ford"))
+
+		// assert
+		require.ErrorIs(t, errFind, internal.ErrTenantNotFound)
+		require.ErrorIs(t, errCreate, internal.ErrTenantNotFound)
+		require.Equal(t, []string{"acme", "globex"}, rp.Tenants())
+	})
+}
+
+// Tests for VehicleTrashTenants
+func TestVehicleTrashTenants(t *testing.T) {
+	t.Run("case 1: a tenant does not see nor restore the deleted vehicles of another one", func(t *testing.T) {
+		// arrange
+		acme := internal.ContextWithTenant(context.Background(), "acme")
+		globex := internal.ContextWithTenant(context.Background(), "globex")
+		tr := repository.NewVehicleTrashTenants(map[string]internal.VehicleTrash{
+			"acme":   repository.NewVehicleTrashMap(),
+			"globex": repository.NewVehicleTrashMap(),
+		})
+		require.NoError(t, tr.Put(acme, internal.TrashedVehicle{Vehicle: vehicleRegistered(1, "AB123", "Ford"), DeletedAt: time.Now()}))
+
+		// act
+		tv, errAll := tr.FindAll(globex)
+		_, errById := tr.FindById(globex, 1)
+		errRemove := tr.Remove(globex, 1)
+
+		// assert
+		require.NoError(t, errAll)
+		require.Empty(t, tv)
+		require.ErrorIs(t, errById, internal.ErrVehicleNotInTrash)
+		require.ErrorIs(t, errRemove, internal.ErrVehicleNotInTrash)
+		_, err := tr.FindById(acme, 1)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/tracing"
+	"context"
+)
+
+// NewVehicleTraced is a function that returns a new instance of VehicleTraced
+func NewVehicleTraced(rp internal.VehicleRepository, tracer *tracing.Tracer) *VehicleTraced {
+	return &VehicleTraced{rp: rp, tracer: tracer}
+}
+
+// VehicleTraced is a struct that decorates a vehicle repository to trace each call as a span named repository.<Method>,
+// with the parameters and the size of the result as attributes
+type VehicleTraced struct {
+	// rp is the decorated vehicle repository
+	rp internal.VehicleRepository
+	// tracer starts the spans
+	tracer *tracing.Tracer
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (r *VehicleTraced) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindAll")
+	defer func() { endSpan(span, err) }()
+
+	v, err = r.rp.FindAll(ctx)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindById is a method that returns a vehicle by id
+func (r *VehicleTraced) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindById")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	v, err = r.rp.FindById(ctx, id)
+	return
+}
+
+// FindLastId is a method that returns the id of the last vehicle registered
+func (r *VehicleTraced) FindLastId(ctx context.Context) (id int, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindLastId")
+	defer func() { endSpan(span, err) }()
+
+	id, err = r.rp.FindLastId(ctx)
+	span.SetAttributes("result.id", id)
+	return
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (r *VehicleTraced) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.CreateVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", v.Id, "vehicle.registration", v.Registration)
+
+	err = r.rp.CreateVehicle(ctx, v)
+	return
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles by color and year
+func (r *VehicleTraced) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByColorAndYear")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.color", color, "filter.year", year)
+
+	v, err = r.rp.FindByColorAndYear(ctx, color, year)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
+func (r *VehicleTraced) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindAverageSpeedByBrand")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.brand", brand)
+
+	averageSpeed, err = r.rp.FindAverageSpeedByBrand(ctx, brand)
+	span.SetAttributes("result.average_speed", averageSpeed)
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (r *VehicleTraced) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.CreateVehicles")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicles.count", len(v))
+
+	err = r.rp.CreateVehicles(ctx, v)
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (r *VehicleTraced) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.UpdateSpeed")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.max_speed", speed)
+
+	err = r.rp.UpdateSpeed(ctx, id, speed)
+	return
+}
+
+// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
+func (r *VehicleTraced) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByFuelType")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.fuel_type", fuelType)
+
+	v, err = r.rp.FindByFuelType(ctx, fuelType)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (r *VehicleTraced) DeleteVehicle(ctx context.Context, id int) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.DeleteVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	err = r.rp.DeleteVehicle(ctx, id)
+	return
+}
+
+// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type
+func (r *VehicleTraced) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByTransmissionType")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.transmission", transmissionType)
+
+	v, err = r.rp.FindByTransmissionType(ctx, transmissionType)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (r *VehicleTraced) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.UpdateFuel")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.fuel_type", fuelType)
+
+	err = r.rp.UpdateFuel(ctx, id, fuelType)
+	return
+}
+
+// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+func (r *VehicleTraced) UpdateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.UpdateVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", v.Id)
+
+	err = r.rp.UpdateVehicle(ctx, v)
+	return
+}
+
+// FindByDimensions is a method that returns a list of vehicles according to their dimensions
+func (r *VehicleTraced) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByDimensions")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.min_length", minLength, "filter.max_length", maxLength, "filter.min_width", minWidth, "filter.max_width", maxWidth)
+
+	v, err = r.rp.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (r *VehicleTraced) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByTags")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.tags", tags, "filter.match", match)
+
+	v, err = r.rp.FindByTags(ctx, tags, match)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (r *VehicleTraced) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindTags")
+	defer func() { endSpan(span, err) }()
+
+	counts, err = r.rp.FindTags(ctx)
+	span.SetAttributes("result.count", len(counts))
+	return
+}
+
+// FindByWeight is a method that returns a list of vehicles according to their weight
+func (r *VehicleTraced) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByWeight")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.min_weight", minWeight, "filter.max_weight", maxWeight)
+
+	v, err = r.rp.FindByWeight(ctx, minWeight, maxWeight)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range
+func (r *VehicleTraced) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.FindByBrandAndYearRange")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.brand", brand, "filter.start_year", startYear, "filter.end_year", endYear)
+
+	v, err = r.rp.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// ReplaceAll is a method that atomically replaces all the vehicles
+func (r *VehicleTraced) ReplaceAll(ctx context.Context, v map[int]internal.Vehicle) (err error) {
+	ctx, span := r.tracer.Start(ctx, "repository.ReplaceAll")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicles.count", len(v))
+
+	err = r.rp.ReplaceAll(ctx, v)
+	return
+}
+
+// endSpan is a function that records the error of the call, if any, and ends the span
+func endSpan(span *tracing.Span, err error) {
+	span.RecordError(err)
+	span.End()
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/platform/tracing"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleTraced
+func TestVehicleTraced(t *testing.T) {
+	t.Run("case 1: a filter query is a span with the filter parameters and the result count", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		rp := repository.NewVehicleTraced(repository.NewVehicleMap(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", FabricationYear: 2010}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", FabricationYear: 2020}},
+		}), tracing.NewTracer(exporter))
+
+		// act
+		v, err := rp.FindByBrandAndYearRange(context.Background(), "Ford", 2005, 2015)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		spans := exporter.Spans()
+		require.Len(t, spans, 1)
+		require.Equal(t, "repository.FindByBrandAndYearRange", spans[0].Name)
+		require.Equal(t, map[string]any{"filter.brand": "Ford", "filter.start_year": 2005, "filter.end_year": 2015, "result.count": 1}, spans[0].Attributes)
+	})
+
+	t.Run("case 2: an error of the repository sets the status of the span", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		rp := repository.NewVehicleTraced(repository.NewVehicleMap(nil), tracing.NewTracer(exporter))
+
+		// act
+		_, err := rp.FindAverageSpeedByBrand(context.Background(), "Ford")
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrNoVehiclesWithBrand)
+		spans := exporter.Spans()
+		require.Len(t, spans, 1)
+		require.Equal(t, tracing.StatusError, spans[0].Status)
+	})
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewVehicleTrashMap is a function that returns a new instance of VehicleTrashMap kept in memory only
+func NewVehicleTrashMap() *VehicleTrashMap {
+	return &VehicleTrashMap{db: make(map[int]internal.TrashedVehicle)}
+}
+
+// OpenVehicleTrashMap is a function that returns a new instance of VehicleTrashMap saved in a file,
+// after reading the deleted vehicles already in it. The functions read and write serialize the deleted vehicles.
+func OpenVehicleTrashMap(path string, read func(r io.Reader) (tv []internal.TrashedVehicle, err error), write func(w io.Writer, tv []internal.TrashedVehicle) (err error)) (t *VehicleTrashMap, err error) {
+	t = &VehicleTrashMap{db: make(map[int]internal.TrashedVehicle), path: path, write: write}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		t = nil
+		return
+	}
+	defer file.Close()
+	items, err := read(file)
+	if err != nil {
+		t = nil
+		return
+	}
+	for _, item := range items {
+		t.db[item.Vehicle.Id] = item
+	}
+	return
+}
+
+// VehicleTrashMap is a struct that implements the trash of the deleted vehicles with a map.
+// With a file, the whole trash is written again on each change, so it survives a restart. A change costs
+// a write of every deleted vehicle: it stays cheap while the retention keeps the trash small.
+type VehicleTrashMap struct {
+	// mu protects the map and the file
+	mu sync.RWMutex
+	// db are the deleted vehicles by id
+	db map[int]internal.TrashedVehicle
+	// path is the file the trash is saved to, empty to keep it in memory only
+	path string
+	// write is the function that serializes the deleted vehicles to the file
+	write func(w io.Writer, tv []internal.TrashedVehicle) (err error)
+}
+
+// Put is a method that keeps a deleted vehicle, replacing a previous deletion of the same id
+func (t *VehicleTrashMap) Put(ctx context.Context, tv internal.TrashedVehicle) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous, existed := t.db[tv.Vehicle.Id]
+	t.db[tv.Vehicle.Id] = tv
+	if err = t.save(); err != nil {
+		// keep the trash as it is in the file
+		if existed {
+			t.db[tv.Vehicle.Id] = previous
+		} else {
+			delete(t.db, tv.Vehicle.Id)
+		}
+	}
+	return
+}
+
+// FindAll is a method that returns the deleted vehicles, the last deleted first
+func (t *VehicleTrashMap) FindAll(ctx context.Context) (tv []internal.TrashedVehicle, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tv = make([]internal.TrashedVehicle, 0, len(t.db))
+	for _, value := range t.db {
+		tv = append(tv, value)
+	}
+	sort.Slice(tv, func(i, j int) bool {
+		if !tv[i].DeletedAt.Equal(tv[j].DeletedAt) {
+			return tv[i].DeletedAt.After(tv[j].DeletedAt)
+		}
+		return tv[i].Vehicle.Id < tv[j].Vehicle.Id
+	})
+	return
+}
+
+// FindById is a method that returns a deleted vehicle by id
+func (t *VehicleTrashMap) FindById(ctx context.Context, id int) (tv internal.TrashedVehicle, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tv, ok := t.db[id]
+	if !ok {
+		err = internal.ErrVehicleNotInTrash
+	}
+	return
+}
+
+// Remove is a method that removes a deleted vehicle from the trash
+func (t *VehicleTrashMap) Remove(ctx context.Context, id int) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tv, ok := t.db[id]
+	if !ok {
+		err = internal.ErrVehicleNotInTrash
+		return
+	}
+	delete(t.db, id)
+	if err = t.save(); err != nil {
+		t.db[id] = tv
+	}
+	return
+}
+
+// RemoveDeletedBefore is a method that removes the vehicles deleted before a time and returns their ids
+func (t *VehicleTrashMap) RemoveDeletedBefore(ctx context.Context, before time.Time) (ids []int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	removed := make(map[int]internal.TrashedVehicle)
+	for id, tv := range t.db {
+		if tv.DeletedAt.Before(before) {
+			removed[id] = tv
+			delete(t.db, id)
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+	if err = t.save(); err != nil {
+		for id, tv := range removed {
+			t.db[id] = tv
+		}
+		return
+	}
+	for id := range removed {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return
+}
+
+// save is a method that writes the whole trash to its file, if any, through a temporary file renamed over it.
+// The writes are not batched: the trash only changes on a delete, a restore or a purge.
+func (t *VehicleTrashMap) save() (err error) {
+	if t.path == "" {
+		return
+	}
+	items := make([]internal.TrashedVehicle, 0, len(t.db))
+	for _, tv := range t.db {
+		items = append(items, tv)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Vehicle.Id < items[j].Vehicle.Id })
+
+	file, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	if err = t.write(file, items); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+	err = os.Rename(file.Name(), t.path)
+	return
+}
@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/loader"
+	"app/internal/repository"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleTrashMap
+func TestVehicleTrashMap(t *testing.T) {
+	t.Run("case 1: the trash saved in a file is read when it is opened again", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "vehicles.json.trash")
+		tr, err := repository.OpenVehicleTrashMap(path, loader.ReadTrashDocument, loader.WriteTrashDocument)
+		require.NoError(t, err)
+		deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		v := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Dimensions: internal.Dimensions{Width: 2}}}
+		require.NoError(t, tr.Put(ctx, internal.TrashedVehicle{Vehicle: v, DeletedAt: deletedAt, DeletedBy: "alice"}))
+		require.NoError(t, tr.Put(ctx, internal.TrashedVehicle{Vehicle: internal.Vehicle{Id: 2}, DeletedAt: deletedAt}))
+		require.NoError(t, tr.Remove(ctx, 2))
+
+		// act
+		tr, err = repository.OpenVehicleTrashMap(path, loader.ReadTrashDocument, loader.WriteTrashDocument)
+		require.NoError(t, err)
+		all, _ := tr.FindAll(ctx)
+
+		// assert
+		require.Equal(t, []internal.TrashedVehicle{{Vehicle: v, DeletedAt: deletedAt, DeletedBy: "alice"}}, all)
+	})
+
+	t.Run("case 2: a trash saved in the first schema version is upgraded when it is opened", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "vehicles.json.trash")
+		data := `[{"id":1,"brand":"Ford","passengers":5,"deleted_at":"2024-01-01T00:00:00Z","deleted_by":"alice"}]`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+		// act
+		tr, err := repository.OpenVehicleTrashMap(path, loader.ReadTrashDocument, loader.WriteTrashDocument)
+		require.NoError(t, err)
+		tv, errFind := tr.FindById(ctx, 1)
+
+		// assert
+		require.NoError(t, errFind)
+		require.Equal(t, 5, tv.Vehicle.Capacity)
+		require.Equal(t, "alice", tv.DeletedBy)
+	})
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"time"
+)
+
+// NewVehicleTrashTenants is a function that returns a new instance of VehicleTrashTenants
+func NewVehicleTrashTenants(tenants map[string]internal.VehicleTrash) *VehicleTrashTenants {
+	return &VehicleTrashTenants{tenants: tenants}
+}
+
+// VehicleTrashTenants is a struct that implements the VehicleTrash interface over the trashes of several tenants:
+// each operation is applied to the trash of the tenant of its context only
+type VehicleTrashTenants struct {
+	// tenants are the trashes of the tenants, by name
+	tenants map[string]internal.VehicleTrash
+}
+
+// Put is a method that keeps a deleted vehicle, replacing a previous deletion of the same id
+func (r *VehicleTrashTenants) Put(ctx context.Context, tv internal.TrashedVehicle) (err error) {
+	tr, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return tr.Put(ctx, tv)
+}
+
+// FindAll is a method that returns the deleted vehicles, the last deleted first
+func (r *VehicleTrashTenants) FindAll(ctx context.Context) (tv []internal.TrashedVehicle, err error) {
+	tr, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return tr.FindAll(ctx)
+}
+
+// FindById is a method that returns a deleted vehicle by id
+func (r *VehicleTrashTenants) FindById(ctx context.Context, id int) (tv internal.TrashedVehicle, err error) {
+	tr, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return tr.FindById(ctx, id)
+}
+
+// Remove is a method that removes a deleted vehicle from the trash
+func (r *VehicleTrashTenants) Remove(ctx context.Context, id int) (err error) {
+	tr, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return tr.Remove(ctx, id)
+}
+
+// RemoveDeletedBefore is a method that removes the vehicles deleted before a time and returns their ids
+func (r *VehicleTrashTenants) RemoveDeletedBefore(ctx context.Context, before time.Time) (ids []int, err error) {
+	tr, err := tenantOf(ctx, r.tenants)
+	if err != nil {
+		return
+	}
+	return tr.RemoveDeletedBefore(ctx, before)
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NewWebhookMap is a function that returns a new instance of WebhookMap
+func NewWebhookMap(maxDeliveries int) *WebhookMap {
+	// default size of the delivery log
+	if maxDeliveries <= 0 {
+		maxDeliveries = 100
+	}
+	return &WebhookMap{
+		subscriptions:  make(map[string]internal.WebhookSubscription),
+		deliveries:     make(map[string]internal.WebhookDelivery),
+		bySubscription: make(map[string][]string),
+		maxDeliveries:  maxDeliveries,
+	}
+}
+
+// WebhookMap is a struct that keeps the webhook subscriptions and their deliveries in memory.
+// The delivery log of each subscription is bounded: the oldest succeeded deliveries are dropped first.
+type WebhookMap struct {
+	// mu protects the maps
+	mu sync.RWMutex
+	// subscriptions are the subscriptions by id
+	subscriptions map[string]internal.WebhookSubscription
+	// deliveries are the deliveries by id
+	deliveries map[string]internal.WebhookDelivery
+	// bySubscription are the ids of the deliveries of each subscription, oldest first
+	bySubscription map[string][]string
+	// maxDeliveries is the maximum number of deliveries kept per subscription
+	maxDeliveries int
+}
+
+// CreateSubscription is a method that registers a subscription
+func (r *WebhookMap) CreateSubscription(ctx context.Context, s internal.WebhookSubscription) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[s.Id] = cloneSubscription(s)
+	return
+}
+
+// FindSubscriptions is a method that returns all the subscriptions, oldest first
+func (r *WebhookMap) FindSubscriptions(ctx context.Context) (s []internal.WebhookSubscription, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s = make([]internal.WebhookSubscription, 0, len(r.subscriptions))
+	for _, value := range r.subscriptions {
+		s = append(s, cloneSubscription(value))
+	}
+	sort.Slice(s, func(i, j int) bool {
+		if !s[i].CreatedAt.Equal(s[j].CreatedAt) {
+			return s[i].CreatedAt.Before(s[j].CreatedAt)
+		}
+		return s[i].Id < s[j].Id
+	})
+	return
+}
+
+// FindSubscriptionById is a method that returns a subscription by id
+func (r *WebhookMap) FindSubscriptionById(ctx context.Context, id string) (s internal.WebhookSubscription, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.subscriptions[id]
+	if !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	s = cloneSubscription(s)
+	return
+}
+
+// UpdateSubscription is a method that replaces a subscription
+func (r *WebhookMap) UpdateSubscription(ctx context.Context, s internal.WebhookSubscription) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[s.Id]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	r.subscriptions[s.Id] = cloneSubscription(s)
+	return
+}
+
+// DeleteSubscription is a method that deletes a subscription and its deliveries
+func (r *WebhookMap) DeleteSubscription(ctx context.Context, id string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	delete(r.subscriptions, id)
+	for _, deliveryId := range r.bySubscription[id] {
+		delete(r.deliveries, deliveryId)
+	}
+	delete(r.bySubscription, id)
+	return
+}
+
+// SaveDelivery is a method that creates or replaces a delivery
+func (r *WebhookMap) SaveDelivery(ctx context.Context, d internal.WebhookDelivery) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[d.SubscriptionId]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	if _, ok := r.deliveries[d.Id]; !ok {
+		r.bySubscription[d.SubscriptionId] = append(r.bySubscription[d.SubscriptionId], d.Id)
+	}
+	r.deliveries[d.Id] = cloneDelivery(d)
+	r.trim(d.SubscriptionId)
+	return
+}
+
+// FindDeliveryById is a method that returns a delivery by id
+func (r *WebhookMap) FindDeliveryById(ctx context.Context, id string) (d internal.WebhookDelivery, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	d = cloneDelivery(d)
+	return
+}
+
+// FindDeliveries is a method that returns the deliveries of a subscription with the status, newest first
+func (r *WebhookMap) FindDeliveries(ctx context.Context, subscriptionId string, status string) (d []internal.WebhookDelivery, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if subscriptionId != "" {
+		if _, ok := r.subscriptions[subscriptionId]; !ok {
+			err = internal.ErrWebhookNotFound
+			return
+		}
+	}
+	d = make([]internal.WebhookDelivery, 0)
+	for key, ids := range r.bySubscription {
+		if subscriptionId != "" && key != subscriptionId {
+			continue
+		}
+		for _, id := range ids {
+			if value := r.deliveries[id]; status == "" || value.Status == status {
+				d = append(d, cloneDelivery(value))
+			}
+		}
+	}
+	sort.SliceStable(d, func(i, j int) bool {
+		if !d[i].CreatedAt.Equal(d[j].CreatedAt) {
+			return d[i].CreatedAt.After(d[j].CreatedAt)
+		}
+		return d[i].Id > d[j].Id
+	})
+	return
+}
+
+// trim is a method that drops the oldest deliveries of a subscription over the maximum,
+// the succeeded ones first, so the pending ones and the dead letters are kept as long as possible
+func (r *WebhookMap) trim(subscriptionId string) {
+	ids := r.bySubscription[subscriptionId]
+	for _, done := range []bool{true, false} {
+		for ix := 0; len(ids) > r.maxDeliveries && ix < len(ids); {
+			if done && r.deliveries[ids[ix]].Status != internal.WebhookDeliverySucceeded {
+				ix++
+				continue
+			}
+			delete(r.deliveries, ids[ix])
+			ids = slices.Delete(ids, ix, ix+1)
+		}
+	}
+	r.bySubscription[subscriptionId] = ids
+}
+
+// cloneSubscription is a function that returns a copy of a subscription that shares no memory with it
+func cloneSubscription(s internal.WebhookSubscription) internal.WebhookSubscription {
+	s.EventTypes = slices.Clone(s.EventTypes)
+	return s
+}
+
+// cloneDelivery is a function that returns a copy of a delivery that shares no memory with it
+func cloneDelivery(d internal.WebhookDelivery) internal.WebhookDelivery {
+	d.Payload = slices.Clone(d.Payload)
+	d.Attempts = slices.Clone(d.Attempts)
+	return d
+}
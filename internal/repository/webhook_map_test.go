@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for WebhookMap
+func TestWebhookMap(t *testing.T) {
+	t.Run("case 1: the deliveries of a subscription are returned newest first and deleted with it", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewWebhookMap(0)
+		require.NoError(t, rp.CreateSubscription(ctx, internal.WebhookSubscription{Id: "s1", URL: "http://localhost"}))
+		start := time.Now()
+		for ix := 0; ix < 3; ix++ {
+			require.NoError(t, rp.SaveDelivery(ctx, internal.WebhookDelivery{
+				Id: fmt.Sprint("d", ix), SubscriptionId: "s1", Status: internal.WebhookDeliveryPending, CreatedAt: start.Add(time.Duration(ix) * time.Second),
+			}))
+		}
+
+		// act
+		d, err := rp.FindDeliveries(ctx, "s1", "")
+		errDelete := rp.DeleteSubscription(ctx, "s1")
+		_, errFind := rp.FindDeliveryById(ctx, "d0")
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"d2", "d1", "d0"}, []string{d[0].Id, d[1].Id, d[2].Id})
+		require.NoError(t, errDelete)
+		require.ErrorIs(t, errFind, internal.ErrWebhookNotFound)
+	})
+
+	t.Run("case 2: the log keeps the pending deliveries and the dead letters over the succeeded ones", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewWebhookMap(2)
+		require.NoError(t, rp.CreateSubscription(ctx, internal.WebhookSubscription{Id: "s1", URL: "http://localhost"}))
+
+		// act
+		require.NoError(t, rp.SaveDelivery(ctx, internal.WebhookDelivery{Id: "dead", SubscriptionId: "s1", Status: internal.WebhookDeliveryDead}))
+		require.NoError(t, rp.SaveDelivery(ctx, internal.WebhookDelivery{Id: "done", SubscriptionId: "s1", Status: internal.WebhookDeliverySucceeded}))
+		require.NoError(t, rp.SaveDelivery(ctx, internal.WebhookDelivery{Id: "pending", SubscriptionId: "s1", Status: internal.WebhookDeliveryPending}))
+		errUnknown := rp.SaveDelivery(ctx, internal.WebhookDelivery{Id: "other", SubscriptionId: "s2"})
+
+		// assert
+		_, err := rp.FindDeliveryById(ctx, "done")
+		require.ErrorIs(t, err, internal.ErrWebhookNotFound)
+		dead, err := rp.FindDeliveries(ctx, "", internal.WebhookDeliveryDead)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		require.ErrorIs(t, errUnknown, internal.ErrWebhookNotFound)
+	})
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NewFleetDefault is a function that returns a new instance of FleetDefault.
+// The orphan policy (unassign, parent or restrict) defaults to unassign.
+func NewFleetDefault(rp internal.FleetRepository, rpVehicle internal.VehicleRepository, orphanPolicy string) *FleetDefault {
+	// default policy
+	if orphanPolicy == "" {
+		orphanPolicy = internal.FleetOrphanUnassign
+	}
+	return &FleetDefault{rp: rp, rpVehicle: rpVehicle, orphanPolicy: orphanPolicy, now: time.Now}
+}
+
+// FleetDefault is a struct that represents the default service for the fleets
+type FleetDefault struct {
+	// rp is the storage of the fleets and of their members
+	rp internal.FleetRepository
+	// rpVehicle is the repository of the vehicles grouped by the fleets
+	rpVehicle internal.VehicleRepository
+	// orphanPolicy is what happens to the vehicles and the sub-fleets of a deleted fleet
+	orphanPolicy string
+	// now is the clock of the service
+	now func() time.Time
+}
+
+// CreateFleet is a method that validates and registers a fleet
+func (s *FleetDefault) CreateFleet(ctx context.Context, f internal.Fleet) (created internal.Fleet, err error) {
+	if f, err = validateFleet(f); err != nil {
+		return
+	}
+	f.Id = 0
+	f.CreatedAt = s.now()
+	if created, err = s.rp.CreateFleet(ctx, f); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "fleet created", "id", created.Id, "name", created.Name, "parent_id", created.ParentId)
+	return
+}
+
+// FindFleets is a method that returns all the fleets, by id
+func (s *FleetDefault) FindFleets(ctx context.Context) (f []internal.Fleet, err error) {
+	f, err = s.rp.FindFleets(ctx)
+	return
+}
+
+// FindFleetById is a method that returns a fleet by id
+func (s *FleetDefault) FindFleetById(ctx context.Context, id int) (f internal.Fleet, err error) {
+	f, err = s.rp.FindFleetById(ctx, id)
+	return
+}
+
+// UpdateFleet is a method that validates and replaces the name, the depot and the parent of a fleet.
+// A fleet can not be moved under itself nor under one of its sub-fleets.
+func (s *FleetDefault) UpdateFleet(ctx context.Context, f internal.Fleet) (updated internal.Fleet, err error) {
+	if f, err = validateFleet(f); err != nil {
+		return
+	}
+	if f.ParentId != 0 {
+		var fleets []internal.Fleet
+		if fleets, err = s.rp.FindFleets(ctx); err != nil {
+			return
+		}
+		if slices.Contains(descendants(fleets, f.Id), f.ParentId) {
+			err = fmt.Errorf("%w: a fleet can not be a sub-fleet of itself or of its sub-fleets", internal.ErrInvalidFleet)
+			return
+		}
+	}
+	if err = s.rp.UpdateFleet(ctx, f); err != nil {
+		return
+	}
+	if updated, err = s.rp.FindFleetById(ctx, f.Id); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "fleet updated", "id", updated.Id, "name", updated.Name, "parent_id", updated.ParentId)
+	return
+}
+
+// DeleteFleet is a method that deletes a fleet, handling its vehicles and its sub-fleets by the orphan policy:
+// unassign leaves the vehicles without fleet and the sub-fleets at the top level, parent moves both to the parent
+// of the fleet, and restrict rejects the deletion of a fleet that has any
+func (s *FleetDefault) DeleteFleet(ctx context.Context, id int) (err error) {
+	f, err := s.rp.FindFleetById(ctx, id)
+	if err != nil {
+		return
+	}
+	var moveTo int
+	switch s.orphanPolicy {
+	case internal.FleetOrphanParent:
+		moveTo = f.ParentId
+	case internal.FleetOrphanRestrict:
+		var members []int
+		if members, err = s.rp.FindMembers(ctx, id); err != nil {
+			return
+		}
+		var fleets []internal.Fleet
+		if fleets, err = s.rp.FindFleets(ctx); err != nil {
+			return
+		}
+		if len(members) > 0 || len(descendants(fleets, id)) > 1 {
+			err = fmt.Errorf("%w: the fleet %d has %d vehicles and %d sub-fleets", internal.ErrFleetNotEmpty, id, len(members), len(descendants(fleets, id))-1)
+			return
+		}
+	}
+	if err = s.rp.DeleteFleet(ctx, id, moveTo); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "fleet deleted", "id", id, "orphan_policy", s.orphanPolicy, "moved_to", moveTo)
+	return
+}
+
+// AssignVehicle is a method that makes a vehicle a member of a fleet, leaving its previous fleet if any
+func (s *FleetDefault) AssignVehicle(ctx context.Context, fleetId int, vehicleId int) (err error) {
+	if _, err = s.rpVehicle.FindById(ctx, vehicleId); err != nil {
+		return
+	}
+	if err = s.rp.AssignVehicle(ctx, vehicleId, fleetId); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicle assigned to fleet", "fleet_id", fleetId, "vehicle_id", vehicleId)
+	return
+}
+
+// UnassignVehicle is a method that removes a vehicle from a fleet
+func (s *FleetDefault) UnassignVehicle(ctx context.Context, fleetId int, vehicleId int) (err error) {
+	if _, err = s.rp.FindFleetById(ctx, fleetId); err != nil {
+		return
+	}
+	current, err := s.rp.FindFleetOf(ctx, vehicleId)
+	if err != nil {
+		return
+	}
+	if current != fleetId {
+		err = internal.ErrVehicleNotInFleet
+		return
+	}
+	if err = s.rp.UnassignVehicle(ctx, vehicleId); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicle removed from fleet", "fleet_id", fleetId, "vehicle_id", vehicleId)
+	return
+}
+
+// FindFleetVehicles is a method that returns the vehicles of a fleet and, if subfleets, of its sub-fleets, by id.
+// The members are read one by one from the vehicle repository, and the ones that are no longer vehicles
+// (e.g. after a reload) are skipped.
+func (s *FleetDefault) FindFleetVehicles(ctx context.Context, id int, subfleets bool) (v []internal.Vehicle, err error) {
+	members, err := s.members(ctx, id, subfleets)
+	if err != nil {
+		return
+	}
+	v = make([]internal.Vehicle, 0, len(members))
+	for _, vehicleId := range members {
+		vh, errFind := s.rpVehicle.FindById(ctx, vehicleId)
+		switch {
+		case errors.Is(errFind, internal.ErrVehicleNotFound):
+			continue
+		case errFind != nil:
+			v, err = nil, errFind
+			return
+		}
+		v = append(v, vh)
+	}
+	return
+}
+
+// FindFleetStats is a method that returns the statistics of the vehicles of a fleet and, if subfleets, of its sub-fleets
+func (s *FleetDefault) FindFleetStats(ctx context.Context, id int, subfleets bool) (st internal.VehicleStats, err error) {
+	v, err := s.FindFleetVehicles(ctx, id, subfleets)
+	if err != nil {
+		return
+	}
+	st = internal.NewVehicleStats(v)
+	return
+}
+
+// members is a method that returns the ids of the vehicles of a fleet and, if subfleets, of its sub-fleets, in order
+func (s *FleetDefault) members(ctx context.Context, id int, subfleets bool) (vehicleIds []int, err error) {
+	ids := []int{id}
+	if subfleets {
+		var fleets []internal.Fleet
+		if fleets, err = s.rp.FindFleets(ctx); err != nil {
+			return
+		}
+		ids = descendants(fleets, id)
+	}
+	for _, fleetId := range ids {
+		var members []int
+		if members, err = s.rp.FindMembers(ctx, fleetId); err != nil {
+			vehicleIds = nil
+			return
+		}
+		vehicleIds = append(vehicleIds, members...)
+	}
+	slices.Sort(vehicleIds)
+	return
+}
+
+// validateFleet is a function that validates and trims the name and the depot of a fleet
+func validateFleet(f internal.Fleet) (valid internal.Fleet, err error) {
+	f.Name = strings.TrimSpace(f.Name)
+	f.Depot = strings.TrimSpace(f.Depot)
+	switch {
+	case f.Name == "" || len(f.Name) > 100:
+		err = fmt.Errorf("%w: name must have between 1 and 100 characters", internal.ErrInvalidFleet)
+	case len(f.Depot) > 100:
+		err = fmt.Errorf("%w: depot must have at most 100 characters", internal.ErrInvalidFleet)
+	case f.ParentId < 0:
+		err = fmt.Errorf("%w: parent_id must not be negative", internal.ErrInvalidFleet)
+	case f.ParentId != 0 && f.ParentId == f.Id:
+		err = fmt.Errorf("%w: a fleet can not be a sub-fleet of itself or of its sub-fleets", internal.ErrInvalidFleet)
+	}
+	if err != nil {
+		return
+	}
+	valid = f
+	return
+}
+
+// descendants is a function that returns the id of a fleet followed by the ids of its sub-fleets, at any depth
+func descendants(fleets []internal.Fleet, id int) (ids []int) {
+	ids = []int{id}
+	for ix := 0; ix < len(ids); ix++ {
+		for _, f := range fleets {
+			if f.ParentId == ids[ix] && !slices.Contains(ids, f.Id) {
+				ids = append(ids, f.Id)
+			}
+		}
+	}
+	return
+}
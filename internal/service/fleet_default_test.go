@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFleetDefault is a function that returns the fleet service of the vehicles, with the orphan policy
+func newFleetDefault(db map[int]internal.Vehicle, orphanPolicy string) (sv *service.FleetDefault, rp *repository.FleetMap) {
+	rpVehicle := repository.NewVehicleMap(db)
+	rp = repository.NewFleetMap()
+	sv = service.NewFleetDefault(rp, rpVehicle, orphanPolicy)
+	return
+}
+
+// vehicleFueled is a function that returns a vehicle with its maximum speed and its fuel type
+func vehicleFueled(id int, maxSpeed float64, fuelType string) internal.Vehicle {
+	return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{MaxSpeed: maxSpeed, FuelType: fuelType}}
+}
+
+// Tests for FleetDefault
+func TestFleetDefault(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: the statistics aggregate the vehicles of the fleet and, on demand, of its sub-fleets", func(t *testing.T) {
+		// arrange
+		sv, _ := newFleetDefault(map[int]internal.Vehicle{
+			1: vehicleFueled(1, 100, "diesel"),
+			2: vehicleFueled(2, 200, "gasoline"),
+			3: vehicleFueled(3, 150, "diesel"),
+		}, "")
+		north, err := sv.CreateFleet(ctx, internal.Fleet{Name: " North ", Depot: "Leeds"})
+		require.NoError(t, err)
+		vans, err := sv.CreateFleet(ctx, internal.Fleet{Name: "Vans", ParentId: north.Id})
+		require.NoError(t, err)
+		require.NoError(t, sv.AssignVehicle(ctx, north.Id, 1))
+		require.NoError(t, sv.AssignVehicle(ctx, north.Id, 2))
+		require.NoError(t, sv.AssignVehicle(ctx, vans.Id, 3))
+
+		// act
+		direct, errDirect := sv.FindFleetStats(ctx, north.Id, false)
+		all, errAll := sv.FindFleetStats(ctx, north.Id, true)
+		v, errVehicles := sv.FindFleetVehicles(ctx, north.Id, true)
+
+		// assert
+		require.Equal(t, "North", north.Name)
+		require.NoError(t, errDirect)
+		require.Equal(t, internal.VehicleStats{Count: 2, AverageMaxSpeed: 150, FuelMix: map[string]int{"diesel": 1, "gasoline": 1}}, direct)
+		require.NoError(t, errAll)
+		require.Equal(t, internal.VehicleStats{Count: 3, AverageMaxSpeed: 150, FuelMix: map[string]int{"diesel": 2, "gasoline": 1}}, all)
+		require.NoError(t, errVehicles)
+		require.Len(t, v, 3)
+		require.Equal(t, 1, v[0].Id)
+	})
+
+	t.Run("case 2: invalid fleets, unknown vehicles and cycles are rejected", func(t *testing.T) {
+		// arrange
+		sv, _ := newFleetDefault(nil, "")
+		north, _ := sv.CreateFleet(ctx, internal.Fleet{Name: "North"})
+		vans, _ := sv.CreateFleet(ctx, internal.Fleet{Name: "Vans", ParentId: north.Id})
+
+		// act
+		_, errName := sv.CreateFleet(ctx, internal.Fleet{Name: "  "})
+		_, errCycle := sv.UpdateFleet(ctx, internal.Fleet{Id: north.Id, Name: "North", ParentId: vans.Id})
+		_, errSelf := sv.UpdateFleet(ctx, internal.Fleet{Id: north.Id, Name: "North", ParentId: north.Id})
+		errVehicle := sv.AssignVehicle(ctx, north.Id, 42)
+		errMember := sv.UnassignVehicle(ctx, north.Id, 42)
+
+		// assert
+		require.ErrorIs(t, errName, internal.ErrInvalidFleet)
+		require.ErrorIs(t, errCycle, internal.ErrInvalidFleet)
+		require.ErrorIs(t, errSelf, internal.ErrInvalidFleet)
+		require.ErrorIs(t, errVehicle, internal.ErrVehicleNotFound)
+		require.ErrorIs(t, errMember, internal.ErrVehicleNotInFleet)
+	})
+
+	t.Run("case 3: the orphan policy handles the vehicles and the sub-fleets of a deleted fleet", func(t *testing.T) {
+		for _, policy := range []string{internal.FleetOrphanUnassign, internal.FleetOrphanParent, internal.FleetOrphanRestrict} {
+			// arrange
+			sv, rp := newFleetDefault(map[int]internal.Vehicle{1: vehicleFueled(1, 100, "diesel")}, policy)
+			north, _ := sv.CreateFleet(ctx, internal.Fleet{Name: "North"})
+			vans, _ := sv.CreateFleet(ctx, internal.Fleet{Name: "Vans", ParentId: north.Id})
+			cargo, _ := sv.CreateFleet(ctx, internal.Fleet{Name: "Cargo", ParentId: vans.Id})
+			require.NoError(t, sv.AssignVehicle(ctx, vans.Id, 1))
+
+			// act
+			err := sv.DeleteFleet(ctx, vans.Id)
+
+			// assert
+			fleetId, _ := rp.FindFleetOf(ctx, 1)
+			sub, _ := sv.FindFleetById(ctx, cargo.Id)
+			switch policy {
+			case internal.FleetOrphanUnassign:
+				require.NoError(t, err)
+				require.Zero(t, fleetId)
+				require.Zero(t, sub.ParentId)
+			case internal.FleetOrphanParent:
+				require.NoError(t, err)
+				require.Equal(t, north.Id, fleetId)
+				require.Equal(t, north.Id, sub.ParentId)
+			case internal.FleetOrphanRestrict:
+				require.ErrorIs(t, err, internal.ErrFleetNotEmpty)
+				require.Equal(t, vans.Id, fleetId)
+				require.Equal(t, vans.Id, sub.ParentId)
+			}
+		}
+	})
+}
+
+// Tests for VehicleGrouped
+func TestVehicleGrouped(t *testing.T) {
+	t.Run("case 1: a deleted vehicle leaves its fleet", func(t *testing.T) {
+		// arrange
+		rp := repository.NewFleetMap()
+		north, _ := rp.CreateFleet(context.Background(), internal.Fleet{Name: "North"})
+		require.NoError(t, rp.AssignVehicle(context.Background(), 42, north.Id))
+		sv := service.NewVehicleGrouped(newVehicleDefault(map[int]internal.Vehicle{42: vehicleFueled(42, 100, "diesel")}), rp)
+
+		// act
+		err := sv.DeleteVehicle(context.Background(), 42)
+
+		// assert
+		require.NoError(t, err)
+		members, _ := rp.FindMembers(context.Background(), north.Id)
+		require.Empty(t, members)
+	})
+}
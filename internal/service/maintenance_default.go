@@ -0,0 +1,128 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NewMaintenanceDefault is a function that returns a new instance of MaintenanceDefault
+func NewMaintenanceDefault(rp internal.MaintenanceRepository, rpVehicle internal.VehicleRepository) *MaintenanceDefault {
+	return &MaintenanceDefault{rp: rp, rpVehicle: rpVehicle, now: time.Now}
+}
+
+// MaintenanceDefault is a struct that represents the default service for the maintenance records of the vehicles
+type MaintenanceDefault struct {
+	// rp is the storage of the maintenance records
+	rp internal.MaintenanceRepository
+	// rpVehicle is the repository of the vehicles maintained
+	rpVehicle internal.VehicleRepository
+	// now is the clock of the service
+	now func() time.Time
+}
+
+// CreateRecord is a method that validates and registers a maintenance record of an existing vehicle
+func (s *MaintenanceDefault) CreateRecord(ctx context.Context, rc internal.MaintenanceRecord) (created internal.MaintenanceRecord, err error) {
+	if _, err = s.rpVehicle.FindById(ctx, rc.VehicleId); err != nil {
+		return
+	}
+	if rc, err = s.validate(rc); err != nil {
+		return
+	}
+	rc.Id = 0
+	if created, err = s.rp.CreateRecord(ctx, rc); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "maintenance record created", "id", created.Id, "vehicle_id", created.VehicleId, "type", created.Type)
+	return
+}
+
+// FindRecords is a method that returns the maintenance records of an existing vehicle, by date and id
+func (s *MaintenanceDefault) FindRecords(ctx context.Context, vehicleId int) (rc []internal.MaintenanceRecord, err error) {
+	if _, err = s.rpVehicle.FindById(ctx, vehicleId); err != nil {
+		return
+	}
+	rc, err = s.rp.FindRecords(ctx, vehicleId)
+	return
+}
+
+// FindRecordById is a method that returns a maintenance record of an existing vehicle by id
+func (s *MaintenanceDefault) FindRecordById(ctx context.Context, vehicleId int, id int) (rc internal.MaintenanceRecord, err error) {
+	if _, err = s.rpVehicle.FindById(ctx, vehicleId); err != nil {
+		return
+	}
+	rc, err = s.rp.FindRecordById(ctx, vehicleId, id)
+	return
+}
+
+// UpdateRecord is a method that validates and replaces a maintenance record of an existing vehicle
+func (s *MaintenanceDefault) UpdateRecord(ctx context.Context, rc internal.MaintenanceRecord) (updated internal.MaintenanceRecord, err error) {
+	if _, err = s.rpVehicle.FindById(ctx, rc.VehicleId); err != nil {
+		return
+	}
+	if rc, err = s.validate(rc); err != nil {
+		return
+	}
+	if err = s.rp.UpdateRecord(ctx, rc); err != nil {
+		return
+	}
+	if updated, err = s.rp.FindRecordById(ctx, rc.VehicleId, rc.Id); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "maintenance record updated", "id", updated.Id, "vehicle_id", updated.VehicleId, "type", updated.Type)
+	return
+}
+
+// DeleteRecord is a method that deletes a maintenance record of an existing vehicle
+func (s *MaintenanceDefault) DeleteRecord(ctx context.Context, vehicleId int, id int) (err error) {
+	if _, err = s.rpVehicle.FindById(ctx, vehicleId); err != nil {
+		return
+	}
+	if err = s.rp.DeleteRecord(ctx, vehicleId, id); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "maintenance record deleted", "id", id, "vehicle_id", vehicleId)
+	return
+}
+
+// validate is a method that validates and trims a maintenance record: the date can not be in the future,
+// and a part replacement needs at least one part
+func (s *MaintenanceDefault) validate(rc internal.MaintenanceRecord) (valid internal.MaintenanceRecord, err error) {
+	rc.Notes = strings.TrimSpace(rc.Notes)
+	rc.Parts = slices.Clone(rc.Parts)
+	for ix := range rc.Parts {
+		rc.Parts[ix].Name = strings.TrimSpace(rc.Parts[ix].Name)
+		rc.Parts[ix].Number = strings.TrimSpace(rc.Parts[ix].Number)
+	}
+	switch {
+	case rc.Type != internal.MaintenanceTypeService && rc.Type != internal.MaintenanceTypeRepair && rc.Type != internal.MaintenanceTypePartReplacement:
+		err = fmt.Errorf("%w: type must be service, repair or part_replacement, got %q", internal.ErrInvalidMaintenanceRecord, rc.Type)
+	case rc.Date.IsZero():
+		err = fmt.Errorf("%w: date is required", internal.ErrInvalidMaintenanceRecord)
+	case rc.Date.After(s.now()):
+		err = fmt.Errorf("%w: date can not be in the future", internal.ErrInvalidMaintenanceRecord)
+	case rc.Odometer < 0:
+		err = fmt.Errorf("%w: odometer can not be negative", internal.ErrInvalidMaintenanceRecord)
+	case rc.Cost < 0:
+		err = fmt.Errorf("%w: cost can not be negative", internal.ErrInvalidMaintenanceRecord)
+	case len(rc.Notes) > 2000:
+		err = fmt.Errorf("%w: notes must have up to 2000 characters", internal.ErrInvalidMaintenanceRecord)
+	case rc.Type == internal.MaintenanceTypePartReplacement && len(rc.Parts) == 0:
+		err = fmt.Errorf("%w: a part replacement needs at least one part", internal.ErrInvalidMaintenanceRecord)
+	}
+	if err != nil {
+		return
+	}
+	for _, part := range rc.Parts {
+		if part.Name == "" || part.Quantity < 1 {
+			err = fmt.Errorf("%w: every part needs a name and a positive quantity", internal.ErrInvalidMaintenanceRecord)
+			return
+		}
+	}
+	valid = rc
+	return
+}
@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serviceOn is a function that returns a service record of a vehicle on a day
+func serviceOn(vehicleId int, day string) internal.MaintenanceRecord {
+	date, _ := time.Parse(time.DateOnly, day)
+	return internal.MaintenanceRecord{VehicleId: vehicleId, Date: date, Odometer: 12000, Type: internal.MaintenanceTypeService, Cost: 150}
+}
+
+// Tests for MaintenanceDefault
+func TestMaintenanceDefault(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: the records of an existing vehicle are validated, trimmed and updated", func(t *testing.T) {
+		// arrange
+		sv := service.NewMaintenanceDefault(repository.NewMaintenanceMap(), repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}}))
+		rc := serviceOn(1, "2024-01-02")
+		rc.Notes = " oil change "
+		rc.Parts = []internal.MaintenancePart{{Name: " oil filter ", Number: "OF-1", Quantity: 1}}
+
+		// act
+		created, errCreate := sv.CreateRecord(ctx, rc)
+		created.Type = internal.MaintenanceTypeRepair
+		created.Cost = 300
+		updated, errUpdate := sv.UpdateRecord(ctx, created)
+
+		// assert
+		require.NoError(t, errCreate)
+		require.Equal(t, 1, created.Id)
+		require.Equal(t, "oil change", created.Notes)
+		require.Equal(t, "oil filter", created.Parts[0].Name)
+		require.NoError(t, errUpdate)
+		require.Equal(t, created, updated)
+		all, _ := sv.FindRecords(ctx, 1)
+		require.Equal(t, []internal.MaintenanceRecord{updated}, all)
+	})
+
+	t.Run("case 2: invalid records and unknown vehicles are rejected", func(t *testing.T) {
+		// arrange
+		sv := service.NewMaintenanceDefault(repository.NewMaintenanceMap(), repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}}))
+		invalid := map[string]func(rc *internal.MaintenanceRecord){
+			"type":     func(rc *internal.MaintenanceRecord) { rc.Type = "wash" },
+			"date":     func(rc *internal.MaintenanceRecord) { rc.Date = time.Time{} },
+			"future":   func(rc *internal.MaintenanceRecord) { rc.Date = time.Now().Add(48 * time.Hour) },
+			"odometer": func(rc *internal.MaintenanceRecord) { rc.Odometer = -1 },
+			"cost":     func(rc *internal.MaintenanceRecord) { rc.Cost = -1 },
+			"parts":    func(rc *internal.MaintenanceRecord) { rc.Type = internal.MaintenanceTypePartReplacement },
+			"quantity": func(rc *internal.MaintenanceRecord) { rc.Parts = []internal.MaintenancePart{{Name: "tyre"}} },
+		}
+
+		for name, fn := range invalid {
+			// act
+			rc := serviceOn(1, "2024-01-02")
+			fn(&rc)
+			_, err := sv.CreateRecord(ctx, rc)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrInvalidMaintenanceRecord, name)
+		}
+		_, errVehicle := sv.CreateRecord(ctx, serviceOn(42, "2024-01-02"))
+		require.ErrorIs(t, errVehicle, internal.ErrVehicleNotFound)
+		errRecord := sv.DeleteRecord(ctx, 1, 42)
+		require.ErrorIs(t, errRecord, internal.ErrMaintenanceRecordNotFound)
+	})
+}
+
+// Tests for VehicleMaintained
+func TestVehicleMaintained(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("case 1: the cascade policy handles the records of a deleted vehicle", func(t *testing.T) {
+		for _, policy := range []string{internal.MaintenanceCascadeKeep, internal.MaintenanceCascadeDelete, internal.MaintenanceCascadeRestrict} {
+			// arrange
+			rp := repository.NewMaintenanceMap()
+			_, _ = rp.CreateRecord(ctx, serviceOn(1, "2024-01-02"))
+			sv := service.NewVehicleMaintained(newVehicleDefault(map[int]internal.Vehicle{1: {Id: 1}}), rp, policy)
+
+			// act
+			err := sv.DeleteVehicle(ctx, 1)
+
+			// assert
+			rc, _ := rp.FindRecords(ctx, 1)
+			_, errFind := sv.FindById(ctx, 1)
+			switch policy {
+			case internal.MaintenanceCascadeKeep:
+				require.NoError(t, err)
+				require.Len(t, rc, 1)
+				require.ErrorIs(t, errFind, internal.ErrVehicleNotFound)
+			case internal.MaintenanceCascadeDelete:
+				require.NoError(t, err)
+				require.Empty(t, rc)
+			case internal.MaintenanceCascadeRestrict:
+				require.ErrorIs(t, err, internal.ErrVehicleHasMaintenance)
+				require.Len(t, rc, 1)
+				require.NoError(t, errFind)
+			}
+		}
+	})
+
+	t.Run("case 2: the records kept are removed when the vehicle is purged", func(t *testing.T) {
+		// arrange
+		rp := repository.NewMaintenanceMap()
+		_, _ = rp.CreateRecord(ctx, serviceOn(1, "2024-01-02"))
+		_, _ = rp.CreateRecord(ctx, serviceOn(2, "2024-01-02"))
+		sv := service.NewVehicleMaintained(newVehicleDefault(map[int]internal.Vehicle{1: {Id: 1}, 2: {Id: 2}}), rp, "")
+		require.NoError(t, sv.DeleteVehicle(ctx, 1))
+		require.NoError(t, sv.DeleteVehicle(ctx, 2))
+
+		// act
+		errPurge := sv.PurgeVehicle(ctx, 1)
+		ids, errTrash := sv.PurgeTrash(ctx, time.Now())
+
+		// assert
+		require.NoError(t, errPurge)
+		require.NoError(t, errTrash)
+		require.Equal(t, []int{2}, ids)
+		for _, id := range []int{1, 2} {
+			rc, _ := rp.FindRecords(ctx, id)
+			require.Empty(t, rc)
+		}
+	})
+
+	t.Run("case 3: the id of a vehicle in the trash is not reused, so its purge keeps the records of the live vehicles", func(t *testing.T) {
+		// arrange
+		rp := repository.NewMaintenanceMap()
+		_, _ = rp.CreateRecord(ctx, serviceOn(1, "2024-01-02"))
+		sv := service.NewVehicleMaintained(newVehicleDefault(map[int]internal.Vehicle{1: {Id: 1}}), rp, internal.MaintenanceCascadeKeep)
+		require.NoError(t, sv.DeleteVehicle(ctx, 1))
+
+		// act
+		errCreate := sv.CreateVehicle(ctx, internal.Vehicle{Id: 1})
+		errPurge := sv.PurgeVehicle(ctx, 1)
+		errRecreate := sv.CreateVehicle(ctx, internal.Vehicle{Id: 1})
+		_, errRecord := rp.CreateRecord(ctx, serviceOn(1, "2024-02-01"))
+		ids, errTrash := sv.PurgeTrash(ctx, time.Now())
+
+		// assert
+		require.ErrorIs(t, errCreate, internal.ErrVehicleAlreadyExists)
+		require.NoError(t, errPurge)
+		require.NoError(t, errRecreate)
+		require.NoError(t, errRecord)
+		require.NoError(t, errTrash)
+		require.Empty(t, ids)
+		rc, _ := rp.FindRecords(ctx, 1)
+		require.Len(t, rc, 1)
+		require.Equal(t, 2, rc[0].Id)
+	})
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// NewTrashPurger is a function that returns a new instance of TrashPurger
+func NewTrashPurger(sv internal.VehicleService, retention time.Duration, interval time.Duration) *TrashPurger {
+	// default interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurger{sv: sv, retention: retention, interval: interval, now: time.Now}
+}
+
+// TrashPurger is a struct that applies the retention policy of the trash: the vehicles deleted
+// for longer than the retention are purged for good, through the service so the purges are audited
+type TrashPurger struct {
+	// sv is the service of the vehicles
+	sv internal.VehicleService
+	// retention is the time the deleted vehicles are kept
+	retention time.Duration
+	// interval is the time between two purges
+	interval time.Duration
+	// now is the clock of the purges
+	now func() time.Time
+}
+
+// Run is a method that purges the trash on each interval until the context is done
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctxPurge := internal.ContextWithPrincipal(ctx, internal.Principal{Subject: "retention", Method: "system"})
+		if _, err := p.sv.PurgeTrash(ctxPurge, p.now().Add(-p.retention)); err != nil {
+			slog.ErrorContext(ctx, "trash: purge failed", "retention", p.retention, "error", err)
+		}
+	}
+}
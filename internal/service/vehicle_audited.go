@@ -0,0 +1,197 @@
+package service
+
+import (
+	"app/internal"
+	"app/platform/logging"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewVehicleAudited is a function that returns a new instance of VehicleAudited
+func NewVehicleAudited(sv internal.VehicleService, rp internal.AuditRepository) *VehicleAudited {
+	return &VehicleAudited{VehicleService: sv, rp: rp, now: time.Now}
+}
+
+// VehicleAudited is a struct that decorates a vehicle service to record each successful mutation in the audit log,
+// with the actor and the request of the context and the attributes changed.
+// The mutations are serialized, so the diff of a change is not mixed with another change of the same vehicle.
+// The queries are not decorated.
+type VehicleAudited struct {
+	// VehicleService is the decorated vehicle service
+	internal.VehicleService
+	// rp is the audit log
+	rp internal.AuditRepository
+	// mu serializes the mutations
+	mu sync.Mutex
+	// now is the clock of the audit log
+	now func() time.Time
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (s *VehicleAudited) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.VehicleService.CreateVehicle(ctx, v); err != nil {
+		return
+	}
+	s.record(ctx, s.entry(ctx, internal.AuditCreate, v.Id, nil, &v.VehicleAttributes))
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time, recorded one by one
+func (s *VehicleAudited) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.VehicleService.CreateVehicles(ctx, v); err != nil {
+		return
+	}
+	entries := make([]internal.AuditEntry, len(v))
+	for ix := range v {
+		entries[ix] = s.entry(ctx, internal.AuditCreateBatch, v[ix].Id, nil, &v[ix].VehicleAttributes)
+	}
+	s.record(ctx, entries...)
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (s *VehicleAudited) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	return s.update(ctx, internal.AuditUpdateSpeed, id, func() error { return s.VehicleService.UpdateSpeed(ctx, id, speed) })
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (s *VehicleAudited) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	return s.update(ctx, internal.AuditUpdateFuel, id, func() error { return s.VehicleService.UpdateFuel(ctx, id, fuelType) })
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (s *VehicleAudited) DeleteVehicle(ctx context.Context, id int) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, errFind := s.VehicleService.FindById(ctx, id)
+	if err = s.VehicleService.DeleteVehicle(ctx, id); err != nil || errFind != nil {
+		// nothing was deleted if the vehicle did not exist
+		return
+	}
+	s.record(ctx, s.entry(ctx, internal.AuditDelete, id, &before.VehicleAttributes, nil))
+	return
+}
+
+// RevertVehicle is a method that restores a previous version of a vehicle as a new change
+func (s *VehicleAudited) RevertVehicle(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, errFind := s.VehicleService.FindById(ctx, id)
+	if v, err = s.VehicleService.RevertVehicle(ctx, id, version); err != nil {
+		return
+	}
+	if errFind != nil {
+		// the vehicle was deleted, it is recreated
+		s.record(ctx, s.entry(ctx, internal.AuditRevert, id, nil, &v.VehicleAttributes))
+		return
+	}
+	s.record(ctx, s.entry(ctx, internal.AuditRevert, id, &before.VehicleAttributes, &v.VehicleAttributes))
+	return
+}
+
+// RestoreVehicle is a method that restores a deleted vehicle from the trash
+func (s *VehicleAudited) RestoreVehicle(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, err = s.VehicleService.RestoreVehicle(ctx, id); err != nil {
+		return
+	}
+	s.record(ctx, s.entry(ctx, internal.AuditRestore, id, nil, &v.VehicleAttributes))
+	return
+}
+
+// PurgeVehicle is a method that removes a deleted vehicle from the trash for good
+func (s *VehicleAudited) PurgeVehicle(ctx context.Context, id int) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.VehicleService.PurgeVehicle(ctx, id); err != nil {
+		return
+	}
+	s.record(ctx, s.entry(ctx, internal.AuditPurge, id, nil, nil))
+	return
+}
+
+// PurgeTrash is a method that removes the vehicles deleted before a time from the trash for good
+func (s *VehicleAudited) PurgeTrash(ctx context.Context, before time.Time) (ids []int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ids, err = s.VehicleService.PurgeTrash(ctx, before); err != nil || len(ids) == 0 {
+		return
+	}
+	entries := make([]internal.AuditEntry, len(ids))
+	for ix, id := range ids {
+		entries[ix] = s.entry(ctx, internal.AuditPurge, id, nil, nil)
+	}
+	s.record(ctx, entries...)
+	return
+}
+
+// AddTags is a method that adds tags to a vehicle
+func (s *VehicleAudited) AddTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	err = s.update(ctx, internal.AuditAddTags, id, func() (e error) {
+		v, e = s.VehicleService.AddTags(ctx, id, tags)
+		return
+	})
+	return
+}
+
+// RemoveTags is a method that removes tags from a vehicle
+func (s *VehicleAudited) RemoveTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	err = s.update(ctx, internal.AuditRemoveTags, id, func() (e error) {
+		v, e = s.VehicleService.RemoveTags(ctx, id, tags)
+		return
+	})
+	return
+}
+
+// update is a method that runs an update of a vehicle and records the attributes changed
+func (s *VehicleAudited) update(ctx context.Context, operation string, id int, fn func() error) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, _ := s.VehicleService.FindById(ctx, id)
+	if err = fn(); err != nil {
+		return
+	}
+	after, e := s.VehicleService.FindById(ctx, id)
+	if e != nil {
+		// the vehicle was deleted meanwhile, the update is recorded without a diff
+		after = before
+	}
+	s.record(ctx, s.entry(ctx, operation, id, &before.VehicleAttributes, &after.VehicleAttributes))
+	return
+}
+
+// entry is a method that returns an entry of the audit log made by the actor, in the tenant and the request of the context
+func (s *VehicleAudited) entry(ctx context.Context, operation string, id int, before, after *internal.VehicleAttributes) internal.AuditEntry {
+	return internal.AuditEntry{
+		At:        s.now(),
+		Actor:     internal.Actor(ctx),
+		Tenant:    internal.Tenant(ctx),
+		RequestId: logging.RequestID(ctx),
+		Operation: operation,
+		VehicleId: id,
+		Changes:   internal.AuditChanges(before, after),
+	}
+}
+
+// record is a method that appends entries to the audit log.
+// The change is already applied, so a failure is logged instead of being returned.
+func (s *VehicleAudited) record(ctx context.Context, entries ...internal.AuditEntry) {
+	if err := s.rp.Append(ctx, entries...); err != nil {
+		slog.ErrorContext(ctx, "audit: entries not recorded", "count", len(entries), "error", err)
+	}
+}
@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/logging"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for VehicleAudited
+func TestVehicleAudited(t *testing.T) {
+	t.Run("case 1: an update is recorded with the actor, the request and the attributes changed", func(t *testing.T) {
+		// arrange
+		l := repository.NewAuditLog()
+		sv := service.NewVehicleAudited(newVehicleDefault(map[int]internal.Vehicle{
+			42: {Id: 42, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", FuelType: "diesel"}},
+		}), l)
+		ctx := internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: "alice"})
+		ctx = logging.WithRequestID(ctx, "req-1")
+
+		// act
+		err := sv.UpdateFuel(ctx, 42, "gasoline")
+
+		// assert
+		require.NoError(t, err)
+		entries, _ := l.Find(context.Background(), internal.AuditQuery{VehicleId: 42})
+		require.Len(t, entries, 1)
+		require.Equal(t, "alice", entries[0].Actor)
+		require.Equal(t, "req-1", entries[0].RequestId)
+		require.Equal(t, internal.AuditUpdateFuel, entries[0].Operation)
+		require.Equal(t, map[string]internal.FieldChange{"fuel_type": {From: "diesel", To: "gasoline"}}, entries[0].Changes)
+	})
+
+	t.Run("case 2: a creation and a deletion are recorded with every attribute, a failed change or a missing vehicle is not", func(t *testing.T) {
+		// arrange
+		l := repository.NewAuditLog()
+		sv := service.NewVehicleAudited(newVehicleDefault(nil), l)
+		v := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}}
+
+		// act
+		errCreate := sv.CreateVehicle(context.Background(), v)
+		errDelete := sv.DeleteVehicle(context.Background(), 1)
+		errMissing := sv.UpdateSpeed(context.Background(), 1, 100)
+		errAgain := sv.DeleteVehicle(context.Background(), 1)
+
+		// assert
+		require.NoError(t, errCreate)
+		require.NoError(t, errDelete)
+		require.Error(t, errMissing)
+		require.ErrorIs(t, errAgain, internal.ErrVehicleNotFound)
+		entries, _ := l.Find(context.Background(), internal.AuditQuery{})
+		require.Len(t, entries, 2)
+		require.Equal(t, internal.AuditAnonymous, entries[0].Actor)
+		require.Equal(t, internal.FieldChange{From: nil, To: "Ford"}, entries[0].Changes["brand"])
+		require.Equal(t, internal.FieldChange{From: "Ford", To: nil}, entries[1].Changes["brand"])
+		require.Len(t, entries[1].Changes, 14)
+	})
+}
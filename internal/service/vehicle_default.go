@@ -2,149 +2,369 @@ package service
 
 import (
 	"app/internal"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"time"
 )
 
 // NewVehicleDefault is a function that returns a new instance of VehicleDefault
-func NewVehicleDefault(rp internal.VehicleRepository) *VehicleDefault {
-	return &VehicleDefault{rp: rp}
+func NewVehicleDefault(rp internal.VehicleRepository, hs internal.VehicleHistory, tr internal.VehicleTrash) *VehicleDefault {
+	return &VehicleDefault{rp: rp, hs: hs, tr: tr, now: time.Now}
 }
 
 // VehicleDefault is a struct that represents the default service for vehicles
 type VehicleDefault struct {
 	// rp is the repository that will be used by the service
 	rp internal.VehicleRepository
+	// hs is the history of the versions of the vehicles
+	hs internal.VehicleHistory
+	// tr is the trash of the deleted vehicles
+	tr internal.VehicleTrash
+	// now is the clock of the deletions
+	now func() time.Time
 }
 
 // FindById is a method that returns a vehicle by id
-func (s *VehicleDefault) FindById(id int) (v internal.Vehicle, err error) {
-	v, err = s.rp.FindById(id)
+func (s *VehicleDefault) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	v, err = s.rp.FindById(ctx, id)
 	return
 }
 
-// FindLastId is a method that returns the last vehicle registered
-func (s *VehicleDefault) FindLastId() (id int, err error) {
-	return s.rp.FindLastId()
+// FindLastId is a method that returns the highest id taken, by a vehicle or by a deleted vehicle in the trash.
+// The trash counts too since the ids it holds can not be registered again (e.g. after a restart in file mode).
+func (s *VehicleDefault) FindLastId(ctx context.Context) (id int, err error) {
+	if id, err = s.rp.FindLastId(ctx); err != nil {
+		return
+	}
+	tv, err := s.tr.FindAll(ctx)
+	if err != nil {
+		return
+	}
+	for _, value := range tv {
+		id = max(id, value.Vehicle.Id)
+	}
+	return
 }
+
 // FindAll is a method that returns a map of all vehicles
-func (s *VehicleDefault) FindAll() (v map[int]internal.Vehicle, err error) {
-	v, err = s.rp.FindAll()
+func (s *VehicleDefault) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	v, err = s.rp.FindAll(ctx)
 	return
 }
 
-// CreateVehicle is a method that registers a vehicle
-func (s *VehicleDefault) CreateVehicle(v internal.Vehicle) (err error) {
-	err = s.rp.CreateVehicle(v)
+// CreateVehicle is a method that registers a vehicle, unless its id belongs to a deleted vehicle still in the trash
+func (s *VehicleDefault) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	if err = s.trashed(ctx, v); err != nil {
+		return
+	}
+	err = s.rp.CreateVehicle(ctx, v)
+	if err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicle created", "id", v.Id, "registration", v.Registration)
 	return
 }
 
 // FindByColorAndYear is a method that returns a map of vehicles by color and year
-func (s *VehicleDefault) FindByColorAndYear(color string, year int) (v map[int]internal.Vehicle, err error) {
-	v, err = s.rp.FindByColorAndYear(color, year)
+func (s *VehicleDefault) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	v, err = s.rp.FindByColorAndYear(ctx, color, year)
 	return
 }
 
 // FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
-func (s *VehicleDefault) FindAverageSpeedByBrand(brand string) (averageSpeed float64, err error) {
-	averageSpeed, err = s.rp.FindAverageSpeedByBrand(brand)
+func (s *VehicleDefault) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	averageSpeed, err = s.rp.FindAverageSpeedByBrand(ctx, brand)
 	if err != nil {
-		fmt.Errorf("the average speed of vehicles of the brand %s is %f", brand, averageSpeed)
+		err = fmt.Errorf("average speed of the vehicles of the brand %s: %w", brand, err)
+		return
 	}
+	slog.DebugContext(ctx, "average speed by brand", "brand", brand, "average_speed", averageSpeed)
 	return
 }
 
-// CreateVehicles is a method that registers several vehicles at the same time
-func (s *VehicleDefault) CreateVehicles(v []internal.Vehicle) (err error) {
-	err = s.rp.CreateVehicles(v)
+// CreateVehicles is a method that registers several vehicles at the same time,
+// unless an id belongs to a deleted vehicle still in the trash
+func (s *VehicleDefault) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	if err = s.trashed(ctx, v...); err != nil {
+		return
+	}
+	err = s.rp.CreateVehicles(ctx, v)
+	if err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicles created", "count", len(v))
 	return
 }
 
 // UpdateSpeed is a method that updates the maximum speed of a specific vehicle
-func (s *VehicleDefault) UpdateSpeed(id int, speed float64) (err error) {
-	err = s.rp.UpdateSpeed(id, speed)
+func (s *VehicleDefault) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	if err = s.validateUpdate(ctx, id, "max_speed", func(v *internal.Vehicle) { v.MaxSpeed = speed }); err != nil {
+		return
+	}
+	err = s.rp.UpdateSpeed(ctx, id, speed)
+	if err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicle speed updated", "id", id, "max_speed", speed)
 	return
 }
 
 // FindByFuelType is a method that returns a list of vehicles according to the type of fuel
-func (s *VehicleDefault) FindByFuelType(fuelType string) (v []internal.Vehicle, err error) {
-	v, err = s.rp.FindByFuelType(fuelType)
+func (s *VehicleDefault) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindByFuelType(ctx, fuelType)
 	return
 }
 
-// DeleteVehicle is a method that deletes a vehicle
-func (s *VehicleDefault) DeleteVehicle(id int) (err error) {
-	err = s.rp.DeleteVehicle(id)
+// DeleteVehicle is a method that deletes a vehicle, which is kept in the trash until it is restored or purged
+func (s *VehicleDefault) DeleteVehicle(ctx context.Context, id int) (err error) {
+	v, err := s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	actor := internal.Actor(ctx)
+	if err = s.tr.Put(ctx, internal.TrashedVehicle{Vehicle: v, DeletedAt: s.now(), DeletedBy: actor}); err != nil {
+		return
+	}
+	err = s.rp.DeleteVehicle(ctx, id)
+	if err != nil {
+		_ = s.tr.Remove(ctx, id)
+		return
+	}
+	slog.InfoContext(ctx, "vehicle deleted", "id", id, "deleted_by", actor)
 	return
 }
 
 // FindByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.)
-func (s *VehicleDefault) FindByTransmissionType(transmissionType string) (v []internal.Vehicle, err error) {
-	v, err = s.rp.FindByTransmissionType(transmissionType)
+func (s *VehicleDefault) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindByTransmissionType(ctx, transmissionType)
 	return
 }
 
 // UpdateFuel is a method that updates the fuel type of a specific vehicle
-func (s *VehicleDefault) UpdateFuel(id int, fuelType string) (err error) {
-	err = s.rp.UpdateFuel(id, fuelType)
+func (s *VehicleDefault) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	if err = s.validateUpdate(ctx, id, "fuel_type", func(v *internal.Vehicle) { v.FuelType = fuelType }); err != nil {
+		return
+	}
+	err = s.rp.UpdateFuel(ctx, id, fuelType)
+	if err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "vehicle fuel type updated", "id", id, "fuel_type", fuelType)
 	return
 }
 
 // FindByDimensions is a method that returns a list of vehicles according to their dimensions (length, width)
-func (s *VehicleDefault) FindByDimensions(minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
-	v, err = s.rp.FindByDimensions(minLength, maxLength, minWidth, maxWidth)
+func (s *VehicleDefault) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
 	return
 }
 
 // FindByWeight is a method that returns a list of vehicles according to their weight (minWeight, maxWeight)
-func (s *VehicleDefault) FindByWeight(minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
-	v, err = s.rp.FindByWeight(minWeight, maxWeight)
+func (s *VehicleDefault) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindByWeight(ctx, minWeight, maxWeight)
 	return
 }
 
 // FindByBrandAndYearRange is a method that returns a list of vehicles of a specific brand and year
-func (s *VehicleDefault) FindByBrandAndYearRange(brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
-	v, err = s.rp.FindByBrandAndYearRange(brand, startYear, endYear)
+func (s *VehicleDefault) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
 	return
 }
 
-// ValidateVehicleData is a method that validates the data of a vehicle
-func (s *VehicleDefault) ValidateVehicleData(vehicle internal.Vehicle) error {
-    
-	// Validate is not empty
-    if vehicle.Brand == "" {
-		return errors.New("the brand cannot be empty")
+// FindAllAsOf is a method that returns a map of all vehicles as they were at a time
+func (s *VehicleDefault) FindAllAsOf(ctx context.Context, at time.Time) (v map[int]internal.Vehicle, err error) {
+	v, err = s.hs.FindAllAsOf(ctx, at)
+	return
+}
+
+// FindByIdAsOf is a method that returns a vehicle by id as it was at a time
+func (s *VehicleDefault) FindByIdAsOf(ctx context.Context, id int, at time.Time) (v internal.Vehicle, err error) {
+	v, err = s.hs.FindByIdAsOf(ctx, id, at)
+	return
+}
+
+// FindVersions is a method that returns the versions of a vehicle, oldest first
+func (s *VehicleDefault) FindVersions(ctx context.Context, id int) (versions []internal.VehicleVersion, err error) {
+	versions, err = s.hs.FindVersions(ctx, id)
+	return
+}
+
+// RevertVehicle is a method that restores a previous version of a vehicle as a new change, recreating it if it was deleted
+func (s *VehicleDefault) RevertVehicle(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	versions, err := s.hs.FindVersions(ctx, id)
+	if err != nil {
+		return
 	}
-	if vehicle.Model == "" {
-		return errors.New("the model cannot be empty")
+	if version < 1 || version > len(versions) {
+		err = fmt.Errorf("%w: vehicle %d has no version %d", internal.ErrVehicleVersionNotFound, id, version)
+		return
 	}
-	if vehicle.Registration == "" {
-		return errors.New("the registration cannot be empty")
+	if versions[version-1].Deleted {
+		err = fmt.Errorf("%w: version %d of the vehicle %d", internal.ErrVehicleVersionDeleted, version, id)
+		return
 	}
-	if vehicle.FabricationYear == 0 {
-		return errors.New("the year cannot be empty")
+	v = versions[version-1].Vehicle
+
+	_, err = s.rp.FindById(ctx, id)
+	switch {
+	case errors.Is(err, internal.ErrVehicleNotFound):
+		// the vehicle is recreated, so it is no longer deleted
+		if err = s.rp.CreateVehicle(ctx, v); err == nil {
+			_ = s.tr.Remove(ctx, id)
+		}
+	case err == nil:
+		err = s.rp.UpdateVehicle(ctx, v)
 	}
-	if vehicle.Color == "" {
-		return errors.New("the color cannot be empty")
+	if err != nil {
+		return
 	}
-	if vehicle.MaxSpeed == 0 {
-		return errors.New("the max speed cannot be empty")
+	slog.InfoContext(ctx, "vehicle reverted", "id", id, "version", version)
+	return
+}
+
+// AddTags is a method that adds tags to a vehicle, the tags it already has are kept
+func (s *VehicleDefault) AddTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	tags, err = internal.NormalizeTags(tags)
+	if err != nil {
+		return
 	}
-	if vehicle.FuelType == "" {
-		return errors.New("the fuel type cannot be empty")
+	return s.retag(ctx, id, func(current []string) []string {
+		return internal.CleanTags(append(slices.Clone(current), tags...))
+	})
+}
+
+// RemoveTags is a method that removes tags from a vehicle, the tags it does not have are ignored
+func (s *VehicleDefault) RemoveTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	tags = internal.CleanTags(tags)
+	return s.retag(ctx, id, func(current []string) []string {
+		return slices.DeleteFunc(slices.Clone(current), func(tag string) bool { return slices.Contains(tags, tag) })
+	})
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (s *VehicleDefault) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	v, err = s.rp.FindByTags(ctx, tags, match)
+	return
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (s *VehicleDefault) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	counts, err = s.rp.FindTags(ctx)
+	return
+}
+
+// retag is a method that replaces the tags of a vehicle with the ones returned by fn from its current tags
+func (s *VehicleDefault) retag(ctx context.Context, id int, fn func(current []string) []string) (v internal.Vehicle, err error) {
+	v, err = s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	v.Tags = fn(v.Tags)
+	if len(v.Tags) == 0 {
+		v.Tags = nil
+	}
+	if err = s.rp.UpdateVehicle(ctx, v); err != nil {
+		v = internal.Vehicle{}
+		return
+	}
+	slog.InfoContext(ctx, "vehicle tags updated", "id", id, "tags", v.Tags)
+	return
+}
+
+// FindTrash is a method that returns the deleted vehicles, the last deleted first
+func (s *VehicleDefault) FindTrash(ctx context.Context) (tv []internal.TrashedVehicle, err error) {
+	tv, err = s.tr.FindAll(ctx)
+	return
+}
+
+// RestoreVehicle is a method that restores a deleted vehicle from the trash
+func (s *VehicleDefault) RestoreVehicle(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	tv, err := s.tr.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	// the id may have been registered again since the deletion
+	if _, err = s.rp.FindById(ctx, id); err == nil {
+		err = fmt.Errorf("%w: id %d is registered again", internal.ErrVehicleAlreadyExists, id)
+		return
+	}
+	if err = s.rp.CreateVehicle(ctx, tv.Vehicle); err != nil {
+		return
 	}
-	if vehicle.Transmission == "" {
-		return errors.New("the transmission cannot be empty")
+	if e := s.tr.Remove(ctx, id); e != nil {
+		slog.WarnContext(ctx, "vehicle restored but still in the trash", "id", id, "error", e)
 	}
-	if vehicle.Height == 0 {
-		return errors.New("the height cannot be empty")
+	v = tv.Vehicle
+	slog.InfoContext(ctx, "vehicle restored", "id", id, "deleted_at", tv.DeletedAt, "deleted_by", tv.DeletedBy)
+	return
+}
+
+// PurgeVehicle is a method that removes a deleted vehicle from the trash for good
+func (s *VehicleDefault) PurgeVehicle(ctx context.Context, id int) (err error) {
+	if err = s.tr.Remove(ctx, id); err != nil {
+		return
 	}
-	if vehicle.Width == 0 {
-		return errors.New("the width cannot be empty")
+	slog.InfoContext(ctx, "vehicle purged", "id", id)
+	return
+}
+
+// PurgeTrash is a method that removes the vehicles deleted before a time from the trash for good
+func (s *VehicleDefault) PurgeTrash(ctx context.Context, before time.Time) (ids []int, err error) {
+	if ids, err = s.tr.RemoveDeletedBefore(ctx, before); err != nil {
+		return
 	}
-	if vehicle.Weight == 0.0 {
-		return errors.New("the weight cannot be empty")
+	slog.InfoContext(ctx, "trash purged", "deleted_before", before, "count", len(ids))
+	return
+}
+
+// trashed is a method that returns an error if the id of a vehicle belongs to a deleted vehicle still in the trash.
+// The id stays taken until the vehicle is restored or purged, so whatever refers to the deleted vehicle by id
+// (e.g. its maintenance records) is not handed over to a new vehicle.
+func (s *VehicleDefault) trashed(ctx context.Context, v ...internal.Vehicle) (err error) {
+	for _, value := range v {
+		_, err = s.tr.FindById(ctx, value.Id)
+		switch {
+		case err == nil:
+			err = fmt.Errorf("%w: id %d belongs to a deleted vehicle in the trash", internal.ErrVehicleAlreadyExists, value.Id)
+			return
+		case errors.Is(err, internal.ErrVehicleNotInTrash):
+			err = nil
+		default:
+			return
+		}
 	}
+	return
+}
 
-    return nil
+// ValidateVehicleData is a method that validates the data of a vehicle
+func (s *VehicleDefault) ValidateVehicleData(vehicle internal.Vehicle) error {
+	return internal.ValidateVehicle(vehicle)
+}
+
+// validateUpdate is a method that validates the field of a vehicle changed by an update,
+// so an update can not leave a value that a snapshot would refuse to restore
+func (s *VehicleDefault) validateUpdate(ctx context.Context, id int, field string, change func(v *internal.Vehicle)) (err error) {
+	v, err := s.rp.FindById(ctx, id)
+	if err != nil {
+		return
+	}
+	change(&v)
+	var errValidation *internal.VehicleValidationError
+	if !errors.As(internal.ValidateVehicle(v), &errValidation) {
+		return
+	}
+	// - only the issues of the changed field, the others were already there
+	var issues []internal.VehicleFieldIssue
+	for _, issue := range errValidation.Issues {
+		if issue.Field == field {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) > 0 {
+		err = &internal.VehicleValidationError{Issues: issues}
+	}
+	return
 }
\ No newline at end of file
@@ -0,0 +1,264 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newVehicleDefault is a function that returns a default service over the vehicles, keeping their versions
+func newVehicleDefault(db map[int]internal.Vehicle) *service.VehicleDefault {
+	rp := repository.NewVehicleTemporal(repository.NewVehicleMap(nil))
+	v := make(map[int]internal.Vehicle, len(db))
+	for key, value := range db {
+		v[key] = value
+	}
+	_ = rp.ReplaceAll(context.Background(), v)
+	return service.NewVehicleDefault(rp, rp, repository.NewVehicleTrashMap())
+}
+
+// Tests for VehicleDefault
+func TestVehicleDefault_RevertVehicle(t *testing.T) {
+	t.Run("case 1: a previous version is restored as a new version", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", FuelType: "diesel", MaxSpeed: 100}},
+		})
+		require.NoError(t, sv.UpdateFuel(ctx, 1, "gasoline"))
+		require.NoError(t, sv.UpdateSpeed(ctx, 1, 150))
+
+		// act
+		v, err := sv.RevertVehicle(ctx, 1, 1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "diesel", v.FuelType)
+		current, _ := sv.FindById(ctx, 1)
+		require.Equal(t, v, current)
+		versions, _ := sv.FindVersions(ctx, 1)
+		require.Len(t, versions, 4)
+		require.Equal(t, 4, versions[3].Version)
+		require.Equal(t, 100.0, versions[3].Vehicle.MaxSpeed)
+	})
+
+	t.Run("case 2: a deleted vehicle is recreated, a deletion or a missing version is not restored", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}},
+		})
+		require.NoError(t, sv.DeleteVehicle(ctx, 1))
+
+		// act
+		_, errDeletion := sv.RevertVehicle(ctx, 1, 2)
+		_, errMissing := sv.RevertVehicle(ctx, 1, 3)
+		_, errUnknown := sv.RevertVehicle(ctx, 2, 1)
+		v, err := sv.RevertVehicle(ctx, 1, 1)
+
+		// assert
+		require.ErrorIs(t, errDeletion, internal.ErrVehicleVersionDeleted)
+		require.ErrorIs(t, errMissing, internal.ErrVehicleVersionNotFound)
+		require.ErrorIs(t, errUnknown, internal.ErrVehicleNotFound)
+		require.NoError(t, err)
+		current, err := sv.FindById(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, v, current)
+	})
+}
+
+// Tests for VehicleDefault point-in-time queries
+func TestVehicleDefault_FindAsOf(t *testing.T) {
+	t.Run("case 1: the vehicles are read as they were, without the ones created later or deleted before", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", MaxSpeed: 100}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Brand: "Fiat"}},
+		})
+		time.Sleep(time.Millisecond)
+		loaded := time.Now()
+		time.Sleep(time.Millisecond)
+		require.NoError(t, sv.UpdateSpeed(ctx, 1, 200))
+		require.NoError(t, sv.DeleteVehicle(ctx, 2))
+		require.NoError(t, sv.CreateVehicle(ctx, internal.Vehicle{Id: 3}))
+
+		// act
+		all, err := sv.FindAllAsOf(ctx, loaded)
+		one, errOne := sv.FindByIdAsOf(ctx, 1, loaded)
+		_, errBefore := sv.FindByIdAsOf(ctx, 1, loaded.Add(-time.Hour))
+		now, _ := sv.FindAllAsOf(ctx, time.Now())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		require.NoError(t, errOne)
+		require.Equal(t, 100.0, one.MaxSpeed)
+		require.ErrorIs(t, errBefore, internal.ErrVehicleNotFound)
+		require.Len(t, now, 2)
+		require.Equal(t, 200.0, now[1].MaxSpeed)
+		require.Contains(t, now, 3)
+	})
+}
+
+// Tests for VehicleDefault soft delete
+func TestVehicleDefault_Trash(t *testing.T) {
+	t.Run("case 1: a deleted vehicle is hidden, kept in the trash with its actor and restored", func(t *testing.T) {
+		// arrange
+		sv := newVehicleDefault(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}},
+		})
+		ctx := internal.ContextWithPrincipal(context.Background(), internal.Principal{Subject: "alice"})
+
+		// act
+		errDelete := sv.DeleteVehicle(ctx, 1)
+		_, errFind := sv.FindById(ctx, 1)
+		all, _ := sv.FindAll(ctx)
+		trash, _ := sv.FindTrash(ctx)
+		v, errRestore := sv.RestoreVehicle(ctx, 1)
+
+		// assert
+		require.NoError(t, errDelete)
+		require.ErrorIs(t, errFind, internal.ErrVehicleNotFound)
+		require.Empty(t, all)
+		require.Len(t, trash, 1)
+		require.Equal(t, "alice", trash[0].DeletedBy)
+		require.Equal(t, "Ford", trash[0].Vehicle.Brand)
+		require.NoError(t, errRestore)
+		require.Equal(t, "Ford", v.Brand)
+		current, err := sv.FindById(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, v, current)
+		trash, _ = sv.FindTrash(ctx)
+		require.Empty(t, trash)
+	})
+
+	t.Run("case 2: the id of a deleted vehicle is not registered again, a vehicle whose id is reloaded is not restored", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewVehicleTemporal(repository.NewVehicleMap(nil))
+		_ = rp.ReplaceAll(ctx, map[int]internal.Vehicle{1: {Id: 1}})
+		sv := service.NewVehicleDefault(rp, rp, repository.NewVehicleTrashMap())
+		require.NoError(t, sv.DeleteVehicle(ctx, 1))
+		kia := internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Kia"}}
+
+		// act
+		errCreate := sv.CreateVehicle(ctx, kia)
+		errBatch := sv.CreateVehicles(ctx, []internal.Vehicle{{Id: 2}, kia})
+		require.NoError(t, rp.CreateVehicle(ctx, kia))
+		_, err := sv.RestoreVehicle(ctx, 1)
+		_, errUnknown := sv.RestoreVehicle(ctx, 2)
+
+		// assert
+		require.ErrorIs(t, errCreate, internal.ErrVehicleAlreadyExists)
+		require.ErrorIs(t, errBatch, internal.ErrVehicleAlreadyExists)
+		_, errFind := sv.FindById(ctx, 2)
+		require.ErrorIs(t, errFind, internal.ErrVehicleNotFound)
+		require.ErrorIs(t, err, internal.ErrVehicleAlreadyExists)
+		require.ErrorIs(t, errUnknown, internal.ErrVehicleNotInTrash)
+	})
+
+	t.Run("case 3: the purge removes the vehicles deleted before the retention only", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{1: {Id: 1}, 2: {Id: 2}})
+		require.NoError(t, sv.DeleteVehicle(ctx, 1))
+		time.Sleep(time.Millisecond)
+		retention := time.Now()
+		time.Sleep(time.Millisecond)
+		require.NoError(t, sv.DeleteVehicle(ctx, 2))
+
+		// act
+		ids, err := sv.PurgeTrash(ctx, retention)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []int{1}, ids)
+		trash, _ := sv.FindTrash(ctx)
+		require.Len(t, trash, 1)
+		require.Equal(t, 2, trash[0].Vehicle.Id)
+		_, err = sv.RestoreVehicle(ctx, 1)
+		require.ErrorIs(t, err, internal.ErrVehicleNotInTrash)
+	})
+
+	t.Run("case 4: the last id counts the deleted vehicles, so the next one is free after the highest id is deleted", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		tr := repository.NewVehicleTrashMap()
+		sv := service.NewVehicleDefault(repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}}), nil, tr)
+		require.NoError(t, sv.CreateVehicle(ctx, internal.Vehicle{Id: 2}))
+		require.NoError(t, sv.DeleteVehicle(ctx, 2))
+		// - the repository forgets its ids, as after a restart with the trash saved in a file
+		restarted := service.NewVehicleDefault(repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}}), nil, tr)
+
+		// act
+		lastId, err := sv.FindLastId(ctx)
+		errCreate := sv.CreateVehicle(ctx, internal.Vehicle{Id: lastId + 1})
+		lastIdRestarted, errRestarted := restarted.FindLastId(ctx)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, lastId)
+		require.NoError(t, errCreate)
+		require.NoError(t, errRestarted)
+		require.Equal(t, 2, lastIdRestarted)
+	})
+}
+
+// vehicleTagged is a function that returns a vehicle with its tags
+func vehicleTagged(id int, tags ...string) internal.Vehicle {
+	return internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Tags: tags}}
+}
+
+// Tests for VehicleDefault tags
+func TestVehicleDefault_Tags(t *testing.T) {
+	t.Run("case 1: the tags are normalized when added and the index follows the changes", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{
+			1: vehicleTagged(1, "airport"),
+			2: vehicleTagged(2, "airport", "electric"),
+			3: vehicleTagged(3),
+		})
+
+		// act
+		v, err := sv.AddTags(ctx, 3, []string{" Electric ", "shuttle", "electric"})
+		_, errRemove := sv.RemoveTags(ctx, 2, []string{"airport", "unknown"})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"electric", "shuttle"}, v.Tags)
+		require.NoError(t, errRemove)
+		all, _ := sv.FindByTags(ctx, []string{"electric", "shuttle"}, internal.TagMatchAll)
+		require.Equal(t, map[int]internal.Vehicle{3: v}, all)
+		any, _ := sv.FindByTags(ctx, []string{"airport", "shuttle"}, internal.TagMatchAny)
+		require.Len(t, any, 2)
+		require.Contains(t, any, 1)
+		require.Contains(t, any, 3)
+		counts, _ := sv.FindTags(ctx)
+		require.Equal(t, map[string]int{"airport": 1, "electric": 2, "shuttle": 1}, counts)
+	})
+
+	t.Run("case 2: invalid tags and unknown vehicles are rejected, deleted vehicles leave the index", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		sv := newVehicleDefault(map[int]internal.Vehicle{1: vehicleTagged(1, "airport")})
+
+		// act
+		_, errTag := sv.AddTags(ctx, 1, []string{"no spaces"})
+		_, errVehicle := sv.AddTags(ctx, 42, []string{"airport"})
+		errDelete := sv.DeleteVehicle(ctx, 1)
+
+		// assert
+		require.ErrorIs(t, errTag, internal.ErrInvalidTag)
+		require.ErrorIs(t, errVehicle, internal.ErrVehicleNotFound)
+		require.NoError(t, errDelete)
+		counts, _ := sv.FindTags(ctx)
+		require.Empty(t, counts)
+	})
+}
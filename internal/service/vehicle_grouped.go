@@ -0,0 +1,33 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"log/slog"
+)
+
+// NewVehicleGrouped is a function that returns a new instance of VehicleGrouped
+func NewVehicleGrouped(sv internal.VehicleService, rp internal.FleetRepository) *VehicleGrouped {
+	return &VehicleGrouped{VehicleService: sv, rp: rp}
+}
+
+// VehicleGrouped is a struct that decorates a vehicle service to remove the deleted vehicles from their fleet,
+// so a vehicle restored from the trash, or registered later with the same id, starts without fleet.
+// The other methods are not decorated.
+type VehicleGrouped struct {
+	// VehicleService is the decorated vehicle service
+	internal.VehicleService
+	// rp is the storage of the fleets of the vehicles
+	rp internal.FleetRepository
+}
+
+// DeleteVehicle is a method that deletes a vehicle and removes it from its fleet
+func (s *VehicleGrouped) DeleteVehicle(ctx context.Context, id int) (err error) {
+	if err = s.VehicleService.DeleteVehicle(ctx, id); err != nil {
+		return
+	}
+	if e := s.rp.UnassignVehicle(ctx, id); e != nil {
+		slog.WarnContext(ctx, "the deleted vehicle was not removed from its fleet", "id", id, "error", e)
+	}
+	return
+}
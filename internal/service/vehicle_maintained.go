@@ -0,0 +1,88 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// NewVehicleMaintained is a function that returns a new instance of VehicleMaintained.
+// The cascade policy (keep, delete or restrict) defaults to keep.
+func NewVehicleMaintained(sv internal.VehicleService, rp internal.MaintenanceRepository, cascadePolicy string) *VehicleMaintained {
+	// default policy
+	if cascadePolicy == "" {
+		cascadePolicy = internal.MaintenanceCascadeKeep
+	}
+	return &VehicleMaintained{VehicleService: sv, rp: rp, cascadePolicy: cascadePolicy}
+}
+
+// VehicleMaintained is a struct that decorates a vehicle service to handle the maintenance records of the deleted
+// vehicles by the cascade policy: keep leaves them until the vehicle is purged from the trash, delete removes them
+// with the vehicle, and restrict rejects the deletion of a vehicle that has any.
+// Under every policy the records of a purged vehicle are removed: they are its own, since the id of a vehicle
+// in the trash is not registered again until it is purged. The other methods are not decorated.
+type VehicleMaintained struct {
+	// VehicleService is the decorated vehicle service
+	internal.VehicleService
+	// rp is the storage of the maintenance records of the vehicles
+	rp internal.MaintenanceRepository
+	// cascadePolicy is what happens to the maintenance records of a deleted vehicle
+	cascadePolicy string
+}
+
+// DeleteVehicle is a method that deletes a vehicle and handles its maintenance records by the cascade policy
+func (s *VehicleMaintained) DeleteVehicle(ctx context.Context, id int) (err error) {
+	if s.cascadePolicy == internal.MaintenanceCascadeRestrict {
+		var rc []internal.MaintenanceRecord
+		if rc, err = s.rp.FindRecords(ctx, id); err != nil {
+			return
+		}
+		if len(rc) > 0 {
+			err = fmt.Errorf("%w: the vehicle %d has %d maintenance records", internal.ErrVehicleHasMaintenance, id, len(rc))
+			return
+		}
+	}
+	if err = s.VehicleService.DeleteVehicle(ctx, id); err != nil {
+		return
+	}
+	if s.cascadePolicy == internal.MaintenanceCascadeDelete {
+		s.deleteRecords(ctx, id)
+	}
+	return
+}
+
+// PurgeVehicle is a method that removes a deleted vehicle from the trash for good, with its maintenance records
+func (s *VehicleMaintained) PurgeVehicle(ctx context.Context, id int) (err error) {
+	if err = s.VehicleService.PurgeVehicle(ctx, id); err != nil {
+		return
+	}
+	s.deleteRecords(ctx, id)
+	return
+}
+
+// PurgeTrash is a method that removes the vehicles deleted before a time from the trash for good,
+// with their maintenance records
+func (s *VehicleMaintained) PurgeTrash(ctx context.Context, before time.Time) (ids []int, err error) {
+	if ids, err = s.VehicleService.PurgeTrash(ctx, before); err != nil {
+		return
+	}
+	for _, id := range ids {
+		s.deleteRecords(ctx, id)
+	}
+	return
+}
+
+// deleteRecords is a method that removes the maintenance records of a vehicle, logging a failure since the vehicle
+// is already gone
+func (s *VehicleMaintained) deleteRecords(ctx context.Context, id int) {
+	n, err := s.rp.DeleteRecords(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "the maintenance records of the vehicle were not deleted", "id", id, "error", err)
+		return
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "maintenance records deleted with the vehicle", "id", id, "records", n)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+)
+
+// NewVehicleNotified is a function that returns a new instance of VehicleNotified
+func NewVehicleNotified(sv internal.VehicleService, nt internal.WebhookNotifier) *VehicleNotified {
+	return &VehicleNotified{VehicleService: sv, nt: nt}
+}
+
+// VehicleNotified is a struct that decorates a vehicle service to notify the webhooks of each successful mutation.
+// The queries are not decorated.
+type VehicleNotified struct {
+	// VehicleService is the decorated vehicle service
+	internal.VehicleService
+	// nt is the notifier of the webhooks
+	nt internal.WebhookNotifier
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (s *VehicleNotified) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	if err = s.VehicleService.CreateVehicle(ctx, v); err != nil {
+		return
+	}
+	s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: v.Id, Vehicle: v})
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time, notified one by one
+func (s *VehicleNotified) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	if err = s.VehicleService.CreateVehicles(ctx, v); err != nil {
+		return
+	}
+	for _, vh := range v {
+		s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: vh.Id, Vehicle: vh})
+	}
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (s *VehicleNotified) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	return s.update(ctx, id, func() error { return s.VehicleService.UpdateSpeed(ctx, id, speed) })
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (s *VehicleNotified) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	return s.update(ctx, id, func() error { return s.VehicleService.UpdateFuel(ctx, id, fuelType) })
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (s *VehicleNotified) DeleteVehicle(ctx context.Context, id int) (err error) {
+	before, errFind := s.VehicleService.FindById(ctx, id)
+	if err = s.VehicleService.DeleteVehicle(ctx, id); err != nil || errFind != nil {
+		// nothing was deleted if the vehicle did not exist
+		return
+	}
+	s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventDeleted, VehicleId: id, Vehicle: before})
+	return
+}
+
+// RevertVehicle is a method that restores a previous version of a vehicle as a new change
+func (s *VehicleNotified) RevertVehicle(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	before, errFind := s.VehicleService.FindById(ctx, id)
+	if v, err = s.VehicleService.RevertVehicle(ctx, id, version); err != nil {
+		return
+	}
+	if errFind != nil {
+		// the vehicle was deleted, it is recreated
+		s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: id, Vehicle: v})
+		return
+	}
+	if changes := internal.VehicleChanges(before.VehicleAttributes, v.VehicleAttributes); len(changes) > 0 {
+		s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventUpdated, VehicleId: id, Vehicle: v, Changes: changes})
+	}
+	return
+}
+
+// RestoreVehicle is a method that restores a deleted vehicle from the trash
+func (s *VehicleNotified) RestoreVehicle(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	if v, err = s.VehicleService.RestoreVehicle(ctx, id); err != nil {
+		return
+	}
+	s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: id, Vehicle: v})
+	return
+}
+
+// AddTags is a method that adds tags to a vehicle
+func (s *VehicleNotified) AddTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	err = s.update(ctx, id, func() (e error) {
+		v, e = s.VehicleService.AddTags(ctx, id, tags)
+		return
+	})
+	return
+}
+
+// RemoveTags is a method that removes tags from a vehicle
+func (s *VehicleNotified) RemoveTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	err = s.update(ctx, id, func() (e error) {
+		v, e = s.VehicleService.RemoveTags(ctx, id, tags)
+		return
+	})
+	return
+}
+
+// update is a method that runs an update of a vehicle and notifies the attributes changed, if any
+func (s *VehicleNotified) update(ctx context.Context, id int, fn func() error) (err error) {
+	before, _ := s.VehicleService.FindById(ctx, id)
+	if err = fn(); err != nil {
+		return
+	}
+	after, e := s.VehicleService.FindById(ctx, id)
+	if e != nil {
+		return
+	}
+	changes := internal.VehicleChanges(before.VehicleAttributes, after.VehicleAttributes)
+	if len(changes) == 0 {
+		return
+	}
+	s.nt.Notify(ctx, internal.VehicleEvent{Type: internal.VehicleEventUpdated, VehicleId: id, Vehicle: after, Changes: changes})
+	return
+}
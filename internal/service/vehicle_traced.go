@@ -0,0 +1,314 @@
+package service
+
+import (
+	"app/internal"
+	"app/platform/tracing"
+	"context"
+	"time"
+)
+
+// NewVehicleTraced is a function that returns a new instance of VehicleTraced
+func NewVehicleTraced(sv internal.VehicleService, tracer *tracing.Tracer) *VehicleTraced {
+	return &VehicleTraced{sv: sv, tracer: tracer}
+}
+
+// VehicleTraced is a struct that decorates a vehicle service to trace each call as a span named service.<Method>,
+// with the parameters and the size of the result as attributes
+type VehicleTraced struct {
+	// sv is the decorated vehicle service
+	sv internal.VehicleService
+	// tracer starts the spans
+	tracer *tracing.Tracer
+}
+
+// FindAll is a method that returns a map of all vehicles
+func (s *VehicleTraced) FindAll(ctx context.Context) (v map[int]internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindAll")
+	defer func() { endSpan(span, err) }()
+
+	v, err = s.sv.FindAll(ctx)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindById is a method that returns a vehicle by id
+func (s *VehicleTraced) FindById(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindById")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	v, err = s.sv.FindById(ctx, id)
+	return
+}
+
+// FindLastId is a method that returns the id of the last vehicle registered
+func (s *VehicleTraced) FindLastId(ctx context.Context) (id int, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindLastId")
+	defer func() { endSpan(span, err) }()
+
+	id, err = s.sv.FindLastId(ctx)
+	span.SetAttributes("result.id", id)
+	return
+}
+
+// CreateVehicle is a method that registers a vehicle
+func (s *VehicleTraced) CreateVehicle(ctx context.Context, v internal.Vehicle) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.CreateVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", v.Id, "vehicle.registration", v.Registration)
+
+	err = s.sv.CreateVehicle(ctx, v)
+	return
+}
+
+// FindByColorAndYear is a method that returns a map of vehicles by color and year
+func (s *VehicleTraced) FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByColorAndYear")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.color", color, "filter.year", year)
+
+	v, err = s.sv.FindByColorAndYear(ctx, color, year)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
+func (s *VehicleTraced) FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindAverageSpeedByBrand")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.brand", brand)
+
+	averageSpeed, err = s.sv.FindAverageSpeedByBrand(ctx, brand)
+	span.SetAttributes("result.average_speed", averageSpeed)
+	return
+}
+
+// CreateVehicles is a method that registers several vehicles at the same time
+func (s *VehicleTraced) CreateVehicles(ctx context.Context, v []internal.Vehicle) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.CreateVehicles")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicles.count", len(v))
+
+	err = s.sv.CreateVehicles(ctx, v)
+	return
+}
+
+// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
+func (s *VehicleTraced) UpdateSpeed(ctx context.Context, id int, speed float64) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.UpdateSpeed")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.max_speed", speed)
+
+	err = s.sv.UpdateSpeed(ctx, id, speed)
+	return
+}
+
+// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
+func (s *VehicleTraced) FindByFuelType(ctx context.Context, fuelType string) (v []internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByFuelType")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.fuel_type", fuelType)
+
+	v, err = s.sv.FindByFuelType(ctx, fuelType)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// DeleteVehicle is a method that deletes a vehicle
+func (s *VehicleTraced) DeleteVehicle(ctx context.Context, id int) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.DeleteVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	err = s.sv.DeleteVehicle(ctx, id)
+	return
+}
+
+// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type
+func (s *VehicleTraced) FindByTransmissionType(ctx context.Context, transmissionType string) (v []internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByTransmissionType")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.transmission", transmissionType)
+
+	v, err = s.sv.FindByTransmissionType(ctx, transmissionType)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// UpdateFuel is a method that updates the fuel type of a specific vehicle
+func (s *VehicleTraced) UpdateFuel(ctx context.Context, id int, fuelType string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.UpdateFuel")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.fuel_type", fuelType)
+
+	err = s.sv.UpdateFuel(ctx, id, fuelType)
+	return
+}
+
+// FindByDimensions is a method that returns a list of vehicles according to their dimensions
+func (s *VehicleTraced) FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByDimensions")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.min_length", minLength, "filter.max_length", maxLength, "filter.min_width", minWidth, "filter.max_width", maxWidth)
+
+	v, err = s.sv.FindByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindByWeight is a method that returns a list of vehicles according to their weight
+func (s *VehicleTraced) FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByWeight")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.min_weight", minWeight, "filter.max_weight", maxWeight)
+
+	v, err = s.sv.FindByWeight(ctx, minWeight, maxWeight)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range
+func (s *VehicleTraced) FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByBrandAndYearRange")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.brand", brand, "filter.start_year", startYear, "filter.end_year", endYear)
+
+	v, err = s.sv.FindByBrandAndYearRange(ctx, brand, startYear, endYear)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindAllAsOf is a method that returns a map of all vehicles as they were at a time
+func (s *VehicleTraced) FindAllAsOf(ctx context.Context, at time.Time) (v map[int]internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindAllAsOf")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.as_of", at.Format(time.RFC3339))
+
+	v, err = s.sv.FindAllAsOf(ctx, at)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindByIdAsOf is a method that returns a vehicle by id as it was at a time
+func (s *VehicleTraced) FindByIdAsOf(ctx context.Context, id int, at time.Time) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByIdAsOf")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "filter.as_of", at.Format(time.RFC3339))
+
+	v, err = s.sv.FindByIdAsOf(ctx, id, at)
+	return
+}
+
+// FindVersions is a method that returns the versions of a vehicle
+func (s *VehicleTraced) FindVersions(ctx context.Context, id int) (versions []internal.VehicleVersion, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindVersions")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	versions, err = s.sv.FindVersions(ctx, id)
+	span.SetAttributes("result.count", len(versions))
+	return
+}
+
+// FindTrash is a method that returns the deleted vehicles
+func (s *VehicleTraced) FindTrash(ctx context.Context) (tv []internal.TrashedVehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindTrash")
+	defer func() { endSpan(span, err) }()
+
+	tv, err = s.sv.FindTrash(ctx)
+	span.SetAttributes("result.count", len(tv))
+	return
+}
+
+// RestoreVehicle is a method that restores a deleted vehicle from the trash
+func (s *VehicleTraced) RestoreVehicle(ctx context.Context, id int) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.RestoreVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	v, err = s.sv.RestoreVehicle(ctx, id)
+	return
+}
+
+// PurgeVehicle is a method that removes a deleted vehicle from the trash for good
+func (s *VehicleTraced) PurgeVehicle(ctx context.Context, id int) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id)
+
+	err = s.sv.PurgeVehicle(ctx, id)
+	return
+}
+
+// PurgeTrash is a method that removes the vehicles deleted before a time from the trash for good
+func (s *VehicleTraced) PurgeTrash(ctx context.Context, before time.Time) (ids []int, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.PurgeTrash")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.deleted_before", before.Format(time.RFC3339))
+
+	ids, err = s.sv.PurgeTrash(ctx, before)
+	span.SetAttributes("result.count", len(ids))
+	return
+}
+
+// RevertVehicle is a method that restores a previous version of a vehicle as a new change
+func (s *VehicleTraced) RevertVehicle(ctx context.Context, id int, version int) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.RevertVehicle")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.version", version)
+
+	v, err = s.sv.RevertVehicle(ctx, id, version)
+	return
+}
+
+// AddTags is a method that adds tags to a vehicle
+func (s *VehicleTraced) AddTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.AddTags")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.tags", tags)
+
+	v, err = s.sv.AddTags(ctx, id, tags)
+	return
+}
+
+// RemoveTags is a method that removes tags from a vehicle
+func (s *VehicleTraced) RemoveTags(ctx context.Context, id int, tags []string) (v internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.RemoveTags")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("vehicle.id", id, "vehicle.tags", tags)
+
+	v, err = s.sv.RemoveTags(ctx, id, tags)
+	return
+}
+
+// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+func (s *VehicleTraced) FindByTags(ctx context.Context, tags []string, match string) (v map[int]internal.Vehicle, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindByTags")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes("filter.tags", tags, "filter.match", match)
+
+	v, err = s.sv.FindByTags(ctx, tags, match)
+	span.SetAttributes("result.count", len(v))
+	return
+}
+
+// FindTags is a method that returns the number of vehicles of each tag
+func (s *VehicleTraced) FindTags(ctx context.Context) (counts map[string]int, err error) {
+	ctx, span := s.tracer.Start(ctx, "service.FindTags")
+	defer func() { endSpan(span, err) }()
+
+	counts, err = s.sv.FindTags(ctx)
+	span.SetAttributes("result.count", len(counts))
+	return
+}
+
+// ValidateVehicleData is a method that validates the data of a vehicle, which is not traced since it does no I/O
+func (s *VehicleTraced) ValidateVehicleData(vehicle internal.Vehicle) error {
+	return s.sv.ValidateVehicleData(vehicle)
+}
+
+// endSpan is a function that records the error of the call, if any, and ends the span
+func endSpan(span *tracing.Span, err error) {
+	span.RecordError(err)
+	span.End()
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/tracing"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newVehicleTraced is a function that returns a traced vehicle service over a traced repository of the vehicles,
+// both exporting their spans to the exporter
+func newVehicleTraced(db map[int]internal.Vehicle, exporter tracing.Exporter) *service.VehicleTraced {
+	tracer := tracing.NewTracer(exporter)
+	rp := repository.NewVehicleTemporal(repository.NewVehicleMap(nil))
+	_ = rp.ReplaceAll(context.Background(), db)
+	rpTraced := repository.NewVehicleTraced(rp, tracer)
+	return service.NewVehicleTraced(service.NewVehicleDefault(rpTraced, rp, repository.NewVehicleTrashMap()), tracer)
+}
+
+// Tests for VehicleTraced
+func TestVehicleTraced(t *testing.T) {
+	t.Run("case 1: a query is a service span parent of the repository span, with the result count", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		sv := newVehicleTraced(map[int]internal.Vehicle{
+			1: {Id: 1, VehicleAttributes: internal.VehicleAttributes{Color: "red", FabricationYear: 2010}},
+			2: {Id: 2, VehicleAttributes: internal.VehicleAttributes{Color: "red", FabricationYear: 2020}},
+		}, exporter)
+
+		// act
+		v, err := sv.FindByColorAndYear(context.Background(), "red", 2010)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, v, 1)
+		spans := exporter.Spans()
+		require.Len(t, spans, 2)
+		child, parent := spans[0], spans[1]
+		require.Equal(t, "repository.FindByColorAndYear", child.Name)
+		require.Equal(t, "service.FindByColorAndYear", parent.Name)
+		require.Equal(t, parent.SpanID, child.ParentID)
+		require.Equal(t, parent.TraceID, child.TraceID)
+		require.Empty(t, parent.ParentID)
+		require.Equal(t, map[string]any{"filter.color": "red", "filter.year": 2010, "result.count": 1}, parent.Attributes)
+		require.Equal(t, tracing.StatusOK, parent.Status)
+	})
+
+	t.Run("case 2: an error sets the status and the error of the service span and of the repository span", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		sv := newVehicleTraced(nil, exporter)
+
+		// act
+		_, err := sv.FindById(context.Background(), 42)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrVehicleNotFound)
+		spans := exporter.Spans()
+		require.Len(t, spans, 2)
+		require.Equal(t, "repository.FindById", spans[0].Name)
+		require.Equal(t, "service.FindById", spans[1].Name)
+		require.Equal(t, spans[1].SpanID, spans[0].ParentID)
+		for _, span := range spans {
+			require.Equal(t, tracing.StatusError, span.Status)
+			require.Equal(t, err.Error(), span.Error)
+			require.Equal(t, 42, span.Attributes["vehicle.id"])
+		}
+	})
+}
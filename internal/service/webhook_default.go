@@ -0,0 +1,187 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// NewWebhookDefault is a function that returns a new instance of WebhookDefault
+func NewWebhookDefault(rp internal.WebhookRepository, dp internal.WebhookDispatcher) *WebhookDefault {
+	return &WebhookDefault{rp: rp, dp: dp, now: time.Now}
+}
+
+// WebhookDefault is a struct that represents the default service for the webhooks
+type WebhookDefault struct {
+	// rp is the storage of the subscriptions and the deliveries
+	rp internal.WebhookRepository
+	// dp is the sender of the deliveries
+	dp internal.WebhookDispatcher
+	// now is the clock of the service
+	now func() time.Time
+}
+
+// CreateSubscription is a method that validates and registers a subscription of the tenant of the context,
+// generating its id and its secret if it has none
+func (s *WebhookDefault) CreateSubscription(ctx context.Context, sub internal.WebhookSubscription) (created internal.WebhookSubscription, err error) {
+	if err = validateWebhook(sub); err != nil {
+		return
+	}
+	created = sub
+	created.Id = randomHex(8)
+	created.Tenant = internal.Tenant(ctx)
+	if created.Secret == "" {
+		created.Secret = randomHex(32)
+	}
+	created.CreatedAt = s.now()
+	if err = s.rp.CreateSubscription(ctx, created); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "webhook created", "id", created.Id, "tenant", created.Tenant, "url", created.URL, "event_types", created.EventTypes)
+	return
+}
+
+// FindSubscriptions is a method that returns all the subscriptions of the tenant of the context
+func (s *WebhookDefault) FindSubscriptions(ctx context.Context) (subs []internal.WebhookSubscription, err error) {
+	all, err := s.rp.FindSubscriptions(ctx)
+	if err != nil {
+		return
+	}
+	tenant := internal.Tenant(ctx)
+	subs = make([]internal.WebhookSubscription, 0, len(all))
+	for _, sub := range all {
+		if sub.Tenant == tenant {
+			subs = append(subs, sub)
+		}
+	}
+	return
+}
+
+// FindSubscriptionById is a method that returns a subscription by id
+func (s *WebhookDefault) FindSubscriptionById(ctx context.Context, id string) (sub internal.WebhookSubscription, err error) {
+	sub, err = s.owned(ctx, id)
+	return
+}
+
+// UpdateSubscription is a method that validates and replaces the url, the event types and, if set, the secret
+func (s *WebhookDefault) UpdateSubscription(ctx context.Context, sub internal.WebhookSubscription) (updated internal.WebhookSubscription, err error) {
+	if err = validateWebhook(sub); err != nil {
+		return
+	}
+	updated, err = s.owned(ctx, sub.Id)
+	if err != nil {
+		return
+	}
+	updated.URL = sub.URL
+	updated.EventTypes = sub.EventTypes
+	if sub.Secret != "" {
+		updated.Secret = sub.Secret
+	}
+	if err = s.rp.UpdateSubscription(ctx, updated); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "webhook updated", "id", updated.Id, "url", updated.URL, "event_types", updated.EventTypes)
+	return
+}
+
+// DeleteSubscription is a method that deletes a subscription
+func (s *WebhookDefault) DeleteSubscription(ctx context.Context, id string) (err error) {
+	if _, err = s.owned(ctx, id); err != nil {
+		return
+	}
+	if err = s.rp.DeleteSubscription(ctx, id); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "webhook deleted", "id", id)
+	return
+}
+
+// FindDeliveries is a method that returns the delivery log of a subscription, newest first
+func (s *WebhookDefault) FindDeliveries(ctx context.Context, subscriptionId string) (d []internal.WebhookDelivery, err error) {
+	if _, err = s.owned(ctx, subscriptionId); err != nil {
+		return
+	}
+	d, err = s.rp.FindDeliveries(ctx, subscriptionId, "")
+	return
+}
+
+// FindDeadLetters is a method that returns the deliveries of the tenant that failed every attempt, newest first
+func (s *WebhookDefault) FindDeadLetters(ctx context.Context) (d []internal.WebhookDelivery, err error) {
+	subs, err := s.FindSubscriptions(ctx)
+	if err != nil {
+		return
+	}
+	dead, err := s.rp.FindDeliveries(ctx, "", internal.WebhookDeliveryDead)
+	if err != nil {
+		return
+	}
+	d = make([]internal.WebhookDelivery, 0, len(dead))
+	for _, dl := range dead {
+		if slices.ContainsFunc(subs, func(sub internal.WebhookSubscription) bool { return sub.Id == dl.SubscriptionId }) {
+			d = append(d, dl)
+		}
+	}
+	return
+}
+
+// Redeliver is a method that queues a dead letter again as a new delivery of the same event
+func (s *WebhookDefault) Redeliver(ctx context.Context, deliveryId string) (d internal.WebhookDelivery, err error) {
+	dead, err := s.rp.FindDeliveryById(ctx, deliveryId)
+	if err != nil {
+		return
+	}
+	if _, err = s.owned(ctx, dead.SubscriptionId); err != nil {
+		return
+	}
+	if d, err = s.dp.Redeliver(ctx, deliveryId); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "webhook delivery queued again", "dead_letter_id", deliveryId, "delivery_id", d.Id)
+	return
+}
+
+// owned is a method that returns a subscription of the tenant of the context by id,
+// ErrWebhookNotFound if it belongs to another tenant
+func (s *WebhookDefault) owned(ctx context.Context, id string) (sub internal.WebhookSubscription, err error) {
+	if sub, err = s.rp.FindSubscriptionById(ctx, id); err != nil {
+		return
+	}
+	if sub.Tenant != internal.Tenant(ctx) {
+		err = internal.ErrWebhookNotFound
+		sub = internal.WebhookSubscription{}
+	}
+	return
+}
+
+// validateWebhook is a function that validates the url and the event types of a subscription
+func validateWebhook(sub internal.WebhookSubscription) (err error) {
+	u, e := url.Parse(sub.URL)
+	if e != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = fmt.Errorf("%w: url must be an absolute http or https url", internal.ErrInvalidWebhook)
+		return
+	}
+	if len(sub.EventTypes) == 0 {
+		err = fmt.Errorf("%w: event_types must not be empty", internal.ErrInvalidWebhook)
+		return
+	}
+	for _, t := range sub.EventTypes {
+		if !slices.Contains(internal.WebhookEventTypes, t) {
+			err = fmt.Errorf("%w: unknown event type %q", internal.ErrInvalidWebhook, t)
+			return
+		}
+	}
+	return
+}
+
+// randomHex is a function that returns n random bytes as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
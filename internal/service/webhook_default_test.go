@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/webhook"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for WebhookDefault
+func TestWebhookDefault(t *testing.T) {
+	t.Run("case 1: a tenant does not see, change nor get the changes of the webhooks of another one", func(t *testing.T) {
+		// arrange
+		acme := internal.ContextWithTenant(context.Background(), "acme")
+		globex := internal.ContextWithTenant(context.Background(), "globex")
+		rp := repository.NewWebhookMap(0)
+		dp := webhook.NewDispatcher(rp, webhook.ConfigDispatcher{})
+		sv := service.NewWebhookDefault(rp, dp)
+		created, err := sv.CreateSubscription(acme, internal.WebhookSubscription{URL: "https://acme.example/hooks", EventTypes: []string{"vehicle.created"}})
+		require.NoError(t, err)
+
+		// act
+		subs, errAll := sv.FindSubscriptions(globex)
+		_, errById := sv.FindSubscriptionById(globex, created.Id)
+		_, errUpdate := sv.UpdateSubscription(globex, internal.WebhookSubscription{Id: created.Id, URL: "https://globex.example/hooks", EventTypes: []string{"vehicle.created"}})
+		errDelete := sv.DeleteSubscription(globex, created.Id)
+		_, errDeliveries := sv.FindDeliveries(globex, created.Id)
+		dp.Notify(globex, internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: 1})
+
+		// assert
+		require.Equal(t, "acme", created.Tenant)
+		require.NoError(t, errAll)
+		require.Empty(t, subs)
+		require.ErrorIs(t, errById, internal.ErrWebhookNotFound)
+		require.ErrorIs(t, errUpdate, internal.ErrWebhookNotFound)
+		require.ErrorIs(t, errDelete, internal.ErrWebhookNotFound)
+		require.ErrorIs(t, errDeliveries, internal.ErrWebhookNotFound)
+		s, err := sv.FindSubscriptionById(acme, created.Id)
+		require.NoError(t, err)
+		require.Equal(t, "https://acme.example/hooks", s.URL)
+		deliveries, _ := sv.FindDeliveries(acme, created.Id)
+		require.Empty(t, deliveries)
+	})
+}
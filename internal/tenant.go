@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// ErrTenantNotFound is returned when a request is scoped to a tenant the service does not serve
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantDefault is the tenant of the requests that do not name one, which owns the dataset of the loader
+const TenantDefault = "default"
+
+// tenantPattern is the pattern of the names of the tenants, which are also part of the paths of their files
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// IsTenantName is a function that returns true if the name is a valid name of a tenant (e.g. acme, acme-logistics)
+func IsTenantName(name string) bool {
+	return tenantPattern.MatchString(name)
+}
+
+// tenantKey is the key of the tenant in the context
+type tenantKey struct{}
+
+// ContextWithTenant is a function that returns a copy of the context scoped to the tenant
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant is a function that returns the tenant the context is scoped to, TenantDefault if there is none
+// (e.g. the background tasks of the default dataset)
+func Tenant(ctx context.Context) (tenant string) {
+	if tenant, _ = ctx.Value(tenantKey{}).(string); tenant == "" {
+		tenant = TenantDefault
+	}
+	return
+}
@@ -34,6 +34,18 @@ type VehicleAttributes struct {
 	Weight float64
 	// Dimensions is the dimensions of the vehicle
 	Dimensions
+	// Tags are the free-form labels of the vehicle (e.g. airport-shuttle), sorted and without duplicates
+	Tags []string
+}
+
+// Provenance is a struct that represents the origin of a vehicle loaded from a dataset
+type Provenance struct {
+	// Source is the source the vehicle came from (e.g. the path of the file)
+	Source string
+	// Format is the format of the source (json, csv, ndjson)
+	Format string
+	// Record is the position of the vehicle in the source
+	Record int
 }
 
 // Vehicle is a struct that represents a vehicle
@@ -43,4 +55,12 @@ type Vehicle struct {
 
 	// VehicleAttribue is the attributes of a vehicle
 	VehicleAttributes
+
+	// Provenance is the origin of the vehicle, empty if it was not loaded from a dataset
+	Provenance Provenance
+}
+
+// Equal is a method that returns true if two vehicles have the same id, attributes and provenance
+func (v Vehicle) Equal(other Vehicle) bool {
+	return v.Id == other.Id && v.Provenance == other.Provenance && len(VehicleChanges(v.VehicleAttributes, other.VehicleAttributes)) == 0
 }
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const (
+	// VehicleEventCreated is the type of the event of a vehicle registered
+	VehicleEventCreated = "created"
+	// VehicleEventUpdated is the type of the event of a vehicle whose attributes changed
+	VehicleEventUpdated = "updated"
+	// VehicleEventDeleted is the type of the event of a vehicle deleted
+	VehicleEventDeleted = "deleted"
+	// VehicleEventReplaced is the type of the event of all the vehicles replaced at once (e.g. a reload)
+	VehicleEventReplaced = "replaced"
+)
+
+// FieldChange is a struct that represents the change of an attribute of a vehicle
+type FieldChange struct {
+	// From is the value before the change
+	From any
+	// To is the value after the change
+	To any
+}
+
+// VehicleEvent is a struct that represents a change of the vehicles
+type VehicleEvent struct {
+	// Id is the sequence number of the event, assigned when it is published
+	Id uint64
+	// Type is the type of the event (created, updated, deleted, replaced)
+	Type string
+	// Tenant is the tenant of the vehicles changed, assigned when it is published if it is empty
+	Tenant string
+	// VehicleId is the id of the vehicle, 0 for a replaced event
+	VehicleId int
+	// Vehicle is the vehicle after the change, or before it for a deleted event
+	Vehicle Vehicle
+	// Changes are the attributes changed by an updated event, by name
+	Changes map[string]FieldChange
+	// Count is the number of vehicles after a replaced event
+	Count int
+	// OccurredAt is the time when the event was published
+	OccurredAt time.Time
+}
+
+// VehicleEventPublisher is an interface that represents the destination of the changes of the vehicles
+type VehicleEventPublisher interface {
+	// Publish is a method that publishes the events in order. It must not block on slow subscribers.
+	Publish(ctx context.Context, events ...VehicleEvent)
+}
+
+// VehicleSubscription is an interface that represents the events received by a subscriber
+type VehicleSubscription interface {
+	// Events is a method that returns the channel of the events, closed when the subscription ends
+	Events() <-chan VehicleEvent
+	// Overflowed is a method that returns true if the subscription ended because the subscriber was too slow
+	Overflowed() bool
+	// Close is a method that ends the subscription
+	Close()
+}
+
+// VehicleEventSubscriber is an interface that represents a source of the changes of the vehicles
+type VehicleEventSubscriber interface {
+	// Subscribe is a method that subscribes to the events of the tenant of the context published after the event with id after.
+	// The events still buffered are returned to be replayed; complete is false if some were already dropped.
+	// An after of 0 subscribes to the new events only.
+	Subscribe(ctx context.Context, after uint64) (s VehicleSubscription, replay []VehicleEvent, complete bool)
+}
+
+// VehicleChanges is a function that returns the attributes that differ between two versions of a vehicle,
+// named as in the JSON format (e.g. max_speed)
+func VehicleChanges(before, after VehicleAttributes) (changes map[string]FieldChange) {
+	changes = make(map[string]FieldChange)
+	from, to := VehicleFields(before), VehicleFields(after)
+	for name, value := range from {
+		if !fieldEqual(value, to[name]) {
+			changes[name] = FieldChange{From: value, To: to[name]}
+		}
+	}
+	return
+}
+
+// VehicleFields is a function that returns the attributes of a vehicle by name, named as in the JSON format
+func VehicleFields(a VehicleAttributes) map[string]any {
+	return map[string]any{
+		"brand":        a.Brand,
+		"model":        a.Model,
+		"registration": a.Registration,
+		"color":        a.Color,
+		"year":         a.FabricationYear,
+		"passengers":   a.Capacity,
+		"max_speed":    a.MaxSpeed,
+		"fuel_type":    a.FuelType,
+		"transmission": a.Transmission,
+		"weight":       a.Weight,
+		"height":       a.Height,
+		"length":       a.Length,
+		"width":        a.Width,
+		"tags":         append([]string{}, a.Tags...),
+	}
+}
+
+// fieldEqual is a function that returns true if two attributes returned by VehicleFields are equal,
+// the tags by their elements
+func fieldEqual(a, b any) bool {
+	if tags, ok := a.([]string); ok {
+		other, _ := b.([]string)
+		return slices.Equal(tags, other)
+	}
+	return a == b
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidFilter is returned when a filter expression can not be parsed
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// vehicleFilterFields are the attributes a filter can compare, named as in the JSON format.
+// A string attribute returns its text with isText true, a numeric one returns its number.
+var vehicleFilterFields = map[string]func(v Vehicle) (text string, number float64, isText bool){
+	"brand":        func(v Vehicle) (string, float64, bool) { return v.Brand, 0, true },
+	"model":        func(v Vehicle) (string, float64, bool) { return v.Model, 0, true },
+	"registration": func(v Vehicle) (string, float64, bool) { return v.Registration, 0, true },
+	"color":        func(v Vehicle) (string, float64, bool) { return v.Color, 0, true },
+	"fuel_type":    func(v Vehicle) (string, float64, bool) { return v.FuelType, 0, true },
+	"transmission": func(v Vehicle) (string, float64, bool) { return v.Transmission, 0, true },
+	"id":           func(v Vehicle) (string, float64, bool) { return "", float64(v.Id), false },
+	"year":         func(v Vehicle) (string, float64, bool) { return "", float64(v.FabricationYear), false },
+	"passengers":   func(v Vehicle) (string, float64, bool) { return "", float64(v.Capacity), false },
+	"max_speed":    func(v Vehicle) (string, float64, bool) { return "", v.MaxSpeed, false },
+	"weight":       func(v Vehicle) (string, float64, bool) { return "", v.Weight, false },
+	"height":       func(v Vehicle) (string, float64, bool) { return "", v.Height, false },
+	"length":       func(v Vehicle) (string, float64, bool) { return "", v.Length, false },
+	"width":        func(v Vehicle) (string, float64, bool) { return "", v.Width, false },
+}
+
+// vehicleCondition is a struct that represents a comparison of an attribute with a value
+type vehicleCondition struct {
+	field    string
+	operator string
+	text     string
+	number   float64
+}
+
+// VehicleFilter is a struct that represents a conjunction of comparisons of the attributes of a vehicle,
+// e.g. brand = Ford and max_speed >= 200 and model != "Yukon XL"
+type VehicleFilter struct {
+	conditions []vehicleCondition
+}
+
+// ParseVehicleFilter is a function that parses a filter expression: comparisons joined by "and".
+// The string attributes support = and !=, the numeric ones also <, <=, > and >=.
+// Values with spaces are double-quoted. An empty expression matches every vehicle.
+func ParseVehicleFilter(expr string) (f VehicleFilter, err error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return
+	}
+	for ix := 0; ix < len(tokens); ix += 4 {
+		if len(tokens)-ix < 3 {
+			err = fmt.Errorf("%w: incomplete comparison at the end of %q", ErrInvalidFilter, expr)
+			return
+		}
+		c := vehicleCondition{field: tokens[ix], operator: tokens[ix+1]}
+		get, ok := vehicleFilterFields[c.field]
+		if !ok {
+			err = fmt.Errorf("%w: unknown attribute %q", ErrInvalidFilter, c.field)
+			return
+		}
+		_, _, isText := get(Vehicle{})
+		switch {
+		case isText && c.operator != "=" && c.operator != "!=":
+			err = fmt.Errorf("%w: %s only supports = and !=", ErrInvalidFilter, c.field)
+			return
+		case isText:
+			c.text = tokens[ix+2]
+		default:
+			switch c.operator {
+			case "=", "!=", "<", "<=", ">", ">=":
+			default:
+				err = fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, c.operator)
+				return
+			}
+			if c.number, err = strconv.ParseFloat(tokens[ix+2], 64); err != nil {
+				err = fmt.Errorf("%w: %s must be compared with a number, got %q", ErrInvalidFilter, c.field, tokens[ix+2])
+				return
+			}
+		}
+		f.conditions = append(f.conditions, c)
+
+		if ix+3 < len(tokens) && !strings.EqualFold(tokens[ix+3], "and") {
+			err = fmt.Errorf("%w: expected and, got %q", ErrInvalidFilter, tokens[ix+3])
+			return
+		}
+	}
+	return
+}
+
+// Match is a method that returns true if the vehicle satisfies every comparison of the filter
+func (f VehicleFilter) Match(v Vehicle) bool {
+	for _, c := range f.conditions {
+		text, number, isText := vehicleFilterFields[c.field](v)
+		var ok bool
+		switch {
+		case isText && c.operator == "=":
+			ok = text == c.text
+		case isText:
+			ok = text != c.text
+		case c.operator == "=":
+			ok = number == c.number
+		case c.operator == "!=":
+			ok = number != c.number
+		case c.operator == "<":
+			ok = number < c.number
+		case c.operator == "<=":
+			ok = number <= c.number
+		case c.operator == ">":
+			ok = number > c.number
+		case c.operator == ">=":
+			ok = number >= c.number
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenizeFilter is a function that splits a filter expression in words, operators and quoted values
+func tokenizeFilter(expr string) (tokens []string, err error) {
+	for ix := 0; ix < len(expr); {
+		switch ch := expr[ix]; {
+		case ch == ' ' || ch == '\t':
+			ix++
+		case ch == '"':
+			end := strings.IndexByte(expr[ix+1:], '"')
+			if end < 0 {
+				err = fmt.Errorf("%w: unterminated quote in %q", ErrInvalidFilter, expr)
+				return
+			}
+			tokens = append(tokens, expr[ix+1:ix+1+end])
+			ix += end + 2
+		case strings.IndexByte("=!<>", ch) >= 0:
+			end := ix + 1
+			if end < len(expr) && expr[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, expr[ix:end])
+			ix = end
+		default:
+			end := ix
+			for end < len(expr) && strings.IndexByte(" \t\"=!<>", expr[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, expr[ix:end])
+			ix = end
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package internal_test
+
+import (
+	"app/internal"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseVehicleFilter
+func TestParseVehicleFilter(t *testing.T) {
+	yukon := internal.Vehicle{Id: 10, VehicleAttributes: internal.VehicleAttributes{Brand: "GMC", Model: "Yukon XL", MaxSpeed: 194, FabricationYear: 2005}}
+
+	t.Run("case 1: the comparisons joined by and must all match", func(t *testing.T) {
+		cases := map[string]bool{
+			"":                                  true,
+			"brand = GMC":                       true,
+			`brand=GMC and model = "Yukon XL"`:  true,
+			"brand = GMC AND max_speed >= 194":  true,
+			"brand = GMC and max_speed > 194":   false,
+			"brand != GMC":                      false,
+			"year < 2010 and id = 10":           true,
+			"year <= 2004":                      false,
+			`model != "Yukon XL" and brand=GMC`: false,
+		}
+		for expr, expected := range cases {
+			// act
+			f, err := internal.ParseVehicleFilter(expr)
+
+			// assert
+			require.NoError(t, err, expr)
+			require.Equal(t, expected, f.Match(yukon), expr)
+		}
+	})
+
+	t.Run("case 2: invalid expressions are rejected", func(t *testing.T) {
+		for _, expr := range []string{
+			"colour = red",
+			"brand > GMC",
+			"max_speed = fast",
+			"brand = GMC or brand = Ford",
+			"brand =",
+			`model = "Yukon`,
+			"max_speed == 10",
+		} {
+			// act
+			_, err := internal.ParseVehicleFilter(expr)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrInvalidFilter, expr)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrVehicleVersionNotFound is returned when a version of a vehicle does not exist
+	ErrVehicleVersionNotFound = errors.New("vehicle version not found")
+	// ErrVehicleVersionDeleted is returned when a vehicle is reverted to the version of its deletion
+	ErrVehicleVersionDeleted = errors.New("vehicle version is a deletion")
+)
+
+// VehicleVersion is a struct that represents a version of a vehicle
+type VehicleVersion struct {
+	// Version is the number of the version, starting at 1 for each vehicle
+	Version int
+	// Vehicle is the vehicle of the version, or the last one before the deletion
+	Vehicle Vehicle
+	// Deleted is true if the version is the deletion of the vehicle
+	Deleted bool
+	// ValidFrom is the time when the version was written, it is valid until the next one
+	ValidFrom time.Time
+}
+
+// VehicleHistory is an interface that represents the prior versions of the vehicles
+type VehicleHistory interface {
+	// FindAllAsOf is a method that returns the vehicles as they were at a time
+	FindAllAsOf(ctx context.Context, at time.Time) (v map[int]Vehicle, err error)
+	// FindByIdAsOf is a method that returns a vehicle as it was at a time, ErrVehicleNotFound if it did not exist
+	FindByIdAsOf(ctx context.Context, id int, at time.Time) (v Vehicle, err error)
+	// FindVersions is a method that returns the versions of a vehicle, oldest first
+	FindVersions(ctx context.Context, id int) (versions []VehicleVersion, err error)
+}
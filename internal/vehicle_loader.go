@@ -1,7 +1,131 @@
 package internal
 
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrDatasetRejected is returned by a loader in strict mode when the dataset has invalid records
+	ErrDatasetRejected = errors.New("dataset rejected")
+	// ErrDatasetEmpty is returned by a reloader when the new dataset has no valid records
+	ErrDatasetEmpty = errors.New("dataset without valid records")
+	// ErrDatasetConflict is returned by a loader with the fail conflict policy when two records share an id or registration
+	ErrDatasetConflict = errors.New("dataset conflict")
+	// ErrDatasetMalformed is returned by a loader when the document is not a vehicles document
+	ErrDatasetMalformed = errors.New("dataset malformed")
+)
+
+const (
+	// LoadModeStrict fails the load when any record is invalid
+	LoadModeStrict = "strict"
+	// LoadModeLenient skips the invalid records and keeps the rest
+	LoadModeLenient = "lenient"
+)
+
+const (
+	// ConflictFirstWins keeps the first record of a duplicated id or registration
+	ConflictFirstWins = "first-wins"
+	// ConflictLastWins keeps the last record of a duplicated id or registration
+	ConflictLastWins = "last-wins"
+	// ConflictFail fails the load when an id or registration is duplicated
+	ConflictFail = "fail"
+)
+
+const (
+	// IssueDuplicateId is the kind of issue for a record whose id was already loaded
+	IssueDuplicateId = "duplicate_id"
+	// IssueDuplicateRegistration is the kind of issue for a record whose registration was already loaded
+	IssueDuplicateRegistration = "duplicate_registration"
+)
+
+// LoadIssue is a struct that represents a problem found in a record while loading the vehicles
+type LoadIssue struct {
+	// Index is the position of the record in the source
+	Index int
+	// Id is the id of the vehicle of the record
+	Id int
+	// Source is the source of the record
+	Source string
+	// VehicleFieldIssue is the detail of the issue
+	VehicleFieldIssue
+	// Skipped is true when the record was not loaded because of the issue
+	Skipped bool
+}
+
+// LoadReport is a struct that represents the data-quality report of a load
+type LoadReport struct {
+	// Source is the origin of the records (e.g. the path of the file)
+	Source string
+	// Mode is the load mode (strict, lenient)
+	Mode string
+	// ConflictPolicy is the policy for duplicated ids or registrations (first-wins, last-wins, fail)
+	ConflictPolicy string
+	// LoadedAt is the moment the load finished
+	LoadedAt time.Time
+	// Total is the number of records read
+	Total int
+	// Loaded is the number of records loaded
+	Loaded int
+	// Skipped is the number of records skipped
+	Skipped int
+	// Duplicates are the issues of records with a duplicated id or registration
+	Duplicates []LoadIssue
+	// MissingFields are the issues of records with fields without value
+	MissingFields []LoadIssue
+	// OutOfRange are the issues of records with implausible or placeholder values
+	OutOfRange []LoadIssue
+}
+
+const (
+	// ReloadApplied is the outcome of a reload whose dataset replaced the previous one
+	ReloadApplied = "applied"
+	// ReloadRolledBack is the outcome of a reload whose dataset failed, so the previous one is kept
+	ReloadRolledBack = "rolled_back"
+)
+
+// ReloadResult is a struct that represents the result of a reload of the vehicles
+type ReloadResult struct {
+	// Trigger is what started the reload (e.g. watcher, manual)
+	Trigger string
+	// StartedAt is the moment the reload started
+	StartedAt time.Time
+	// FinishedAt is the moment the reload finished
+	FinishedAt time.Time
+	// Outcome is the outcome of the reload (applied, rolled_back)
+	Outcome string
+	// Loaded is the number of vehicles in the repository after the reload
+	Loaded int
+	// Error is the reason why the reload was rolled back
+	Error string
+}
+
 // VehicleLoader is an interface that represents the loader for vehicles
 type VehicleLoader interface {
 	// Load is a method that loads the vehicles
 	Load() (v map[int]Vehicle, err error)
+}
+
+// VehicleLoadReporter is an interface that represents a loader that keeps the report of its last load
+type VehicleLoadReporter interface {
+	// Report is a method that returns the data-quality report of the last load
+	Report() (r LoadReport)
+}
+
+// VehicleReloader is an interface that represents a reloader of the vehicles dataset
+type VehicleReloader interface {
+	// Reload is a method that loads the dataset again and replaces the vehicles of the repository
+	Reload(trigger string) (r ReloadResult, err error)
+}
+
+// VehicleSnapshotter is an interface that represents a point-in-time backup of the vehicles
+type VehicleSnapshotter interface {
+	// Snapshot is a method that writes a consistent copy of the vehicles and returns how many were written
+	Snapshot(ctx context.Context, w io.Writer) (n int, err error)
+
+	// Restore is a method that validates a snapshot and replaces the vehicles with it.
+	// A snapshot without vehicles is only restored if allowEmpty is set.
+	Restore(ctx context.Context, r io.Reader, allowEmpty bool) (report LoadReport, err error)
 }
\ No newline at end of file
@@ -1,50 +1,70 @@
 package internal
 
+import "context"
+
+const (
+	// StorageModeMemory keeps the vehicles only in memory
+	StorageModeMemory = "memory"
+	// StorageModeFile keeps the vehicles in memory and persists them in a file
+	StorageModeFile = "file"
+)
+
 // VehicleRepository is an interface that represents a vehicle repository
 type VehicleRepository interface {
 	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]Vehicle, err error)
+	FindAll(ctx context.Context) (v map[int]Vehicle, err error)
 
 	// FindById is a method that returns a vehicle by id
-	FindById(id int) (v Vehicle, err error)
+	FindById(ctx context.Context, id int) (v Vehicle, err error)
 
-	//FindLast is a method that returns the last vehicle registered
-	FindLastId() (id int, err error)
+	// FindLastId is a method that returns the highest id ever registered, the deleted vehicles included
+	FindLastId(ctx context.Context) (id int, err error)
 
 	// Post is a method that registers a vehicle
-	CreateVehicle(v Vehicle) (err error)
+	CreateVehicle(ctx context.Context, v Vehicle) (err error)
 
 	// FindByColorAndYear is a method that returns a map of vehicles by color and year
-	FindByColorAndYear(color string, year int) (v map[int]Vehicle, err error)
+	FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]Vehicle, err error)
 
 	// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
-	FindAverageSpeedByBrand(brand string) (averageSpeed float64, err error)
+	FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error)
 
 	// CreateVehicles is a method that registers several vehicles at the same time
-	CreateVehicles(v []Vehicle) (err error)
+	CreateVehicles(ctx context.Context, v []Vehicle) (err error)
 
 	// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
-	UpdateSpeed(id int, speed float64) (err error)
+	UpdateSpeed(ctx context.Context, id int, speed float64) (err error)
 
 	// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
-	FindByFuelType(fuelType string) (v []Vehicle, err error)
+	FindByFuelType(ctx context.Context, fuelType string) (v []Vehicle, err error)
 
 	// DeleteVehicle is a method that deletes a vehicle
-	DeleteVehicle(id int) (err error)
-	
+	DeleteVehicle(ctx context.Context, id int) (err error)
+
 	// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.)
-	FindByTransmissionType(transmissionType string) (v []Vehicle, err error)
+	FindByTransmissionType(ctx context.Context, transmissionType string) (v []Vehicle, err error)
 
 	// UpdateFuel is a method that updates the fuel type of a specific vehicle
-	UpdateFuel(id int, fuelType string) (err error)
+	UpdateFuel(ctx context.Context, id int, fuelType string) (err error)
 
 	// FindByDimensions is a method that returns a list of vehicles according to their dimensions (length, width)
-	FindByDimensions(minLength, maxLength, minWidth, maxWidth float64) (v []Vehicle, err error)
+	FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []Vehicle, err error)
 
 	// FindByWeight is a method that returns a list of vehicles according to their weight (minWeight, maxWeight)
-	FindByWeight(minWeight, maxWeight float64) (v []Vehicle, err error)
+	FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []Vehicle, err error)
 
 	// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range (startYear, endYear)
-	FindByBrandAndYearRange(brand string, startYear, endYear int) (v []Vehicle, err error)
+	FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []Vehicle, err error)
+
+	// UpdateVehicle is a method that replaces the attributes of an existing vehicle
+	UpdateVehicle(ctx context.Context, v Vehicle) (err error)
+
+	// ReplaceAll is a method that atomically replaces all the vehicles (e.g. when the dataset is reloaded)
+	ReplaceAll(ctx context.Context, v map[int]Vehicle) (err error)
+
+	// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+	FindByTags(ctx context.Context, tags []string, match string) (v map[int]Vehicle, err error)
 
+	// FindTags is a method that returns the number of vehicles of each tag
+	FindTags(ctx context.Context) (counts map[string]int, err error)
 }
\ No newline at end of file
@@ -1,6 +1,10 @@
 package internal
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var (
 	ErrVehicleAlreadyExists = errors.New("vehicle already exists")
@@ -12,50 +16,86 @@ var (
 // VehicleService is an interface that represents a vehicle service
 type VehicleService interface {
 	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]Vehicle, err error)
+	FindAll(ctx context.Context) (v map[int]Vehicle, err error)
 
 	// FindById is a method that returns a vehicle by id
-	FindById(id int) (v Vehicle, err error)
-	
-	// FindLastId is a method that returns the last vehicle registered
-	FindLastId() (id int, err error)
-	
+	FindById(ctx context.Context, id int) (v Vehicle, err error)
+
+	// FindLastId is a method that returns the highest id taken, by a vehicle or by a deleted vehicle in the trash
+	FindLastId(ctx context.Context) (id int, err error)
+
 	// CreateVehicle is a method that registers a vehicle
-	CreateVehicle(v Vehicle) (err error)
+	CreateVehicle(ctx context.Context, v Vehicle) (err error)
 
 	// FindByColorAndYear is a method that returns a map of vehicles by color and year
-	FindByColorAndYear(color string, year int) (v map[int]Vehicle, err error)
+	FindByColorAndYear(ctx context.Context, color string, year int) (v map[int]Vehicle, err error)
 
 	// FindAverageSpeedByBrand is a method that returns the average speed of vehicles of a specific brand
-	FindAverageSpeedByBrand(brand string) (averageSpeed float64, err error)
+	FindAverageSpeedByBrand(ctx context.Context, brand string) (averageSpeed float64, err error)
 
 	// CreateVehicles is a method that registers several vehicles at the same time
-	CreateVehicles(v []Vehicle) (err error)
+	CreateVehicles(ctx context.Context, v []Vehicle) (err error)
 
 	// UpdateSpeed is a method that updates the maximum speed of a specific vehicle
-	UpdateSpeed(id int, speed float64) (err error)
+	UpdateSpeed(ctx context.Context, id int, speed float64) (err error)
 
 	// FindByFuelType is a method that returns a list of vehicles according to the type of fuel
-	FindByFuelType(fuelType string) (v []Vehicle, err error)
+	FindByFuelType(ctx context.Context, fuelType string) (v []Vehicle, err error)
 
 	// DeleteVehicle is a method that deletes a vehicle
-	DeleteVehicle(id int) (err error)
+	DeleteVehicle(ctx context.Context, id int) (err error)
 
 	// FindByTransmissionType is a method that returns a list of vehicles according to their transmission type (manual, automatic, etc.)
-	FindByTransmissionType(transmissionType string) (v []Vehicle, err error)
+	FindByTransmissionType(ctx context.Context, transmissionType string) (v []Vehicle, err error)
 
 	// UpdateFuel is a method that updates the fuel type of a specific vehicle
-	UpdateFuel(id int, fuelType string) (err error)
+	UpdateFuel(ctx context.Context, id int, fuelType string) (err error)
 
 	// FindByDimensions is a method that returns a list of vehicles according to their dimensions (length, width)
-	FindByDimensions(minLength, maxLength, minWidth, maxWidth float64) (v []Vehicle, err error)
+	FindByDimensions(ctx context.Context, minLength, maxLength, minWidth, maxWidth float64) (v []Vehicle, err error)
 
 	// FindByWeight is a method that returns a list of vehicles according to their weight (minWeight, maxWeight)
-	FindByWeight(minWeight, maxWeight float64) (v []Vehicle, err error)
+	FindByWeight(ctx context.Context, minWeight, maxWeight float64) (v []Vehicle, err error)
 
 	// Validate is a method that validates the data of a vehicle
 	ValidateVehicleData(vehicle Vehicle) error
 
 	// FindByBrandAndYearRange is a method that returns a list of vehicles according to their brand and year range (startYear, endYear)
-	FindByBrandAndYearRange(brand string, startYear, endYear int) (v []Vehicle, err error)
+	FindByBrandAndYearRange(ctx context.Context, brand string, startYear, endYear int) (v []Vehicle, err error)
+
+	// FindAllAsOf is a method that returns a map of all vehicles as they were at a time
+	FindAllAsOf(ctx context.Context, at time.Time) (v map[int]Vehicle, err error)
+
+	// FindByIdAsOf is a method that returns a vehicle by id as it was at a time
+	FindByIdAsOf(ctx context.Context, id int, at time.Time) (v Vehicle, err error)
+
+	// FindVersions is a method that returns the versions of a vehicle, oldest first
+	FindVersions(ctx context.Context, id int) (versions []VehicleVersion, err error)
+
+	// FindTrash is a method that returns the deleted vehicles, the last deleted first
+	FindTrash(ctx context.Context) (tv []TrashedVehicle, err error)
+
+	// RestoreVehicle is a method that restores a deleted vehicle from the trash
+	RestoreVehicle(ctx context.Context, id int) (v Vehicle, err error)
+
+	// PurgeVehicle is a method that removes a deleted vehicle from the trash for good
+	PurgeVehicle(ctx context.Context, id int) (err error)
+
+	// PurgeTrash is a method that removes the vehicles deleted before a time from the trash for good
+	PurgeTrash(ctx context.Context, before time.Time) (ids []int, err error)
+
+	// RevertVehicle is a method that restores a previous version of a vehicle as a new change, recreating it if it was deleted
+	RevertVehicle(ctx context.Context, id int, version int) (v Vehicle, err error)
+
+	// AddTags is a method that adds tags to a vehicle, the tags it already has are kept
+	AddTags(ctx context.Context, id int, tags []string) (v Vehicle, err error)
+
+	// RemoveTags is a method that removes tags from a vehicle, the tags it does not have are ignored
+	RemoveTags(ctx context.Context, id int, tags []string) (v Vehicle, err error)
+
+	// FindByTags is a method that returns a map of the vehicles with every tag (all) or with at least one of them (any)
+	FindByTags(ctx context.Context, tags []string, match string) (v map[int]Vehicle, err error)
+
+	// FindTags is a method that returns the number of vehicles of each tag
+	FindTags(ctx context.Context) (counts map[string]int, err error)
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidTag is returned when a tag is not a valid tag name
+var ErrInvalidTag = errors.New("invalid tag")
+
+const (
+	// TagMatchAll matches the vehicles with every tag queried
+	TagMatchAll = "all"
+	// TagMatchAny matches the vehicles with at least one of the tags queried
+	TagMatchAny = "any"
+)
+
+// tagPattern is the pattern of a tag name: lowercase letters, digits, '-', '_', '.' and ':', up to 64 characters
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+
+// IsTagName is a function that returns true if the tag is a valid tag name (e.g. airport-shuttle)
+func IsTagName(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// CleanTags is a function that trims and lowercases the tags and returns them sorted, without duplicates,
+// nil if there are none. The invalid tags are kept, so they can be reported.
+func CleanTags(tags []string) (cleaned []string) {
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	slices.Sort(cleaned)
+	cleaned = slices.Compact(cleaned)
+	return
+}
+
+// NormalizeTags is a function that cleans the tags like CleanTags and rejects the invalid ones
+func NormalizeTags(tags []string) (normalized []string, err error) {
+	normalized = CleanTags(tags)
+	for _, tag := range normalized {
+		if !IsTagName(tag) {
+			err = fmt.Errorf("%w: %q must be up to 64 lowercase letters, digits, '-', '_', '.' or ':'", ErrInvalidTag, tag)
+			normalized = nil
+			return
+		}
+	}
+	return
+}
+
+// MatchTags is a function that returns true if the vehicle tags have every tag (all) or at least one of them (any)
+func MatchTags(vehicleTags []string, tags []string, match string) bool {
+	tags = CleanTags(tags)
+	found := 0
+	for _, tag := range tags {
+		if slices.Contains(vehicleTags, tag) {
+			found++
+		}
+	}
+	if match == TagMatchAny {
+		return found > 0
+	}
+	return found > 0 && found == len(tags)
+}
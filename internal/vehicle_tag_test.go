@@ -0,0 +1,51 @@
+package internal_test
+
+import (
+	"app/internal"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for NormalizeTags
+func TestNormalizeTags(t *testing.T) {
+	t.Run("case 1: the tags are trimmed, lowercased, sorted and without duplicates", func(t *testing.T) {
+		// act
+		tags, err := internal.NormalizeTags([]string{" Shuttle", "airport", "", "AIRPORT", "zone:north"})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"airport", "shuttle", "zone:north"}, tags)
+	})
+
+	t.Run("case 2: invalid tags are rejected", func(t *testing.T) {
+		for _, tag := range []string{"two words", "-leading", "ünicode", strings.Repeat("a", 65)} {
+			// act
+			_, err := internal.NormalizeTags([]string{"airport", tag})
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrInvalidTag, tag)
+		}
+	})
+}
+
+// Tests for MatchTags
+func TestMatchTags(t *testing.T) {
+	t.Run("case 1: all requires every tag, any at least one of them", func(t *testing.T) {
+		// arrange
+		tags := []string{"airport", "electric"}
+
+		// act
+		all := internal.MatchTags(tags, []string{"Electric", "airport"}, internal.TagMatchAll)
+		allMissing := internal.MatchTags(tags, []string{"electric", "shuttle"}, internal.TagMatchAll)
+		any := internal.MatchTags(tags, []string{"electric", "shuttle"}, internal.TagMatchAny)
+		anyMissing := internal.MatchTags(tags, []string{"shuttle"}, internal.TagMatchAny)
+
+		// assert
+		require.True(t, all)
+		require.False(t, allMissing)
+		require.True(t, any)
+		require.False(t, anyMissing)
+	})
+}
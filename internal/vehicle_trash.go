@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrVehicleNotInTrash is returned when a deleted vehicle is not in the trash (never deleted, restored or purged)
+var ErrVehicleNotInTrash = errors.New("vehicle not in trash")
+
+// TrashedVehicle is a struct that represents a deleted vehicle kept in the trash until it is restored or purged
+type TrashedVehicle struct {
+	// Vehicle is the vehicle as it was when it was deleted
+	Vehicle Vehicle
+	// DeletedAt is the time of the deletion
+	DeletedAt time.Time
+	// DeletedBy is the subject of the principal that deleted the vehicle
+	DeletedBy string
+}
+
+// VehicleTrash is an interface that represents the storage of the deleted vehicles, by id
+type VehicleTrash interface {
+	// Put is a method that keeps a deleted vehicle, replacing a previous deletion of the same id
+	Put(ctx context.Context, tv TrashedVehicle) (err error)
+	// FindAll is a method that returns the deleted vehicles, the last deleted first
+	FindAll(ctx context.Context) (tv []TrashedVehicle, err error)
+	// FindById is a method that returns a deleted vehicle by id
+	FindById(ctx context.Context, id int) (tv TrashedVehicle, err error)
+	// Remove is a method that removes a deleted vehicle from the trash
+	Remove(ctx context.Context, id int) (err error)
+	// RemoveDeletedBefore is a method that removes the vehicles deleted before a time and returns their ids
+	RemoveDeletedBefore(ctx context.Context, before time.Time) (ids []int, err error)
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// IssueMissingField is the kind of issue for a field that has no value
+	IssueMissingField = "missing_field"
+	// IssueOutOfRange is the kind of issue for a field whose value is not plausible
+	IssueOutOfRange = "out_of_range"
+	// IssuePlaceholder is the kind of issue for a field filled with a placeholder value
+	IssuePlaceholder = "placeholder"
+	// IssueInvalidTag is the kind of issue for a tag that is not a valid tag name
+	IssueInvalidTag = "invalid_tag"
+)
+
+// VehicleFieldIssue is a struct that represents a problem found in a field of a vehicle
+type VehicleFieldIssue struct {
+	// Field is the name of the field, as it is known in the JSON format
+	Field string
+	// Kind is the kind of issue (missing_field, out_of_range, placeholder, invalid_tag)
+	Kind string
+	// Value is the value that caused the issue
+	Value any
+	// Message is a human readable description of the issue
+	Message string
+}
+
+// VehicleValidationError is an error that contains every issue found validating a vehicle
+type VehicleValidationError struct {
+	// Issues is the list of issues found
+	Issues []VehicleFieldIssue
+}
+
+// Error returns the message of the issues found
+func (e *VehicleValidationError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap allows to match the error with ErrInvalidVehicle
+func (e *VehicleValidationError) Unwrap() error {
+	return ErrInvalidVehicle
+}
+
+// VehicleRange is a struct that represents the plausible range of a numeric field
+type VehicleRange struct {
+	// Min is the minimum plausible value
+	Min float64
+	// Max is the maximum plausible value
+	Max float64
+}
+
+// VehicleLimits are the plausible ranges for the numeric fields of a vehicle.
+// Dimensions are expressed in centimeters and speed in km/h.
+var VehicleLimits = map[string]VehicleRange{
+	"passengers": {Min: 1, Max: 100},
+	"max_speed":  {Min: 1, Max: 500},
+	"height":     {Min: 10, Max: 450},
+	"length":     {Min: 50, Max: 2500},
+	"width":      {Min: 10, Max: 450},
+	"weight":     {Min: 1, Max: 60000},
+}
+
+// ValidateVehicle is a function that validates the data of a vehicle.
+// It returns a *VehicleValidationError with every issue found, or nil if the vehicle is valid.
+func ValidateVehicle(v Vehicle) (err error) {
+	var issues []VehicleFieldIssue
+
+	// required fields
+	required := []struct {
+		field   string
+		name    string
+		value   any
+		isEmpty bool
+	}{
+		{"brand", "brand", v.Brand, v.Brand == ""},
+		{"model", "model", v.Model, v.Model == ""},
+		{"registration", "registration", v.Registration, v.Registration == ""},
+		{"year", "year", v.FabricationYear, v.FabricationYear == 0},
+		{"color", "color", v.Color, v.Color == ""},
+		{"max_speed", "max speed", v.MaxSpeed, v.MaxSpeed == 0},
+		{"fuel_type", "fuel type", v.FuelType, v.FuelType == ""},
+		{"transmission", "transmission", v.Transmission, v.Transmission == ""},
+		{"height", "height", v.Height, v.Height == 0},
+		{"width", "width", v.Width, v.Width == 0},
+		{"weight", "weight", v.Weight, v.Weight == 0},
+	}
+	missing := make(map[string]bool)
+	for _, r := range required {
+		if r.isEmpty {
+			missing[r.field] = true
+			issues = append(issues, VehicleFieldIssue{
+				Field:   r.field,
+				Kind:    IssueMissingField,
+				Value:   r.value,
+				Message: fmt.Sprintf("the %s cannot be empty", r.name),
+			})
+		}
+	}
+
+	// placeholder values
+	if v.Registration != "" && strings.Trim(v.Registration, "0") == "" {
+		issues = append(issues, VehicleFieldIssue{
+			Field:   "registration",
+			Kind:    IssuePlaceholder,
+			Value:   v.Registration,
+			Message: fmt.Sprintf("the registration %q is a placeholder", v.Registration),
+		})
+	}
+
+	// tags
+	for _, tag := range v.Tags {
+		if !IsTagName(tag) {
+			issues = append(issues, VehicleFieldIssue{
+				Field:   "tags",
+				Kind:    IssueInvalidTag,
+				Value:   tag,
+				Message: fmt.Sprintf("the tag %q must be up to 64 lowercase letters, digits, '-', '_', '.' or ':'", tag),
+			})
+		}
+	}
+
+	// plausible ranges
+	if !missing["year"] {
+		maxYear := time.Now().Year() + 1
+		if v.FabricationYear < 1886 || v.FabricationYear > maxYear {
+			issues = append(issues, VehicleFieldIssue{
+				Field:   "year",
+				Kind:    IssueOutOfRange,
+				Value:   v.FabricationYear,
+				Message: fmt.Sprintf("the year %d is out of range [1886, %d]", v.FabricationYear, maxYear),
+			})
+		}
+	}
+	numeric := []struct {
+		field string
+		value float64
+	}{
+		{"passengers", float64(v.Capacity)},
+		{"max_speed", v.MaxSpeed},
+		{"height", v.Height},
+		{"length", v.Length},
+		{"width", v.Width},
+		{"weight", v.Weight},
+	}
+	for _, n := range numeric {
+		// - empty values are either reported as missing or optional
+		if n.value == 0 || missing[n.field] {
+			continue
+		}
+		limit := VehicleLimits[n.field]
+		if n.value < limit.Min || n.value > limit.Max {
+			issues = append(issues, VehicleFieldIssue{
+				Field:   n.field,
+				Kind:    IssueOutOfRange,
+				Value:   n.value,
+				Message: fmt.Sprintf("the %s %g is out of range [%g, %g]", n.field, n.value, limit.Min, limit.Max),
+			})
+		}
+	}
+
+	if len(issues) > 0 {
+		err = &VehicleValidationError{Issues: issues}
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook subscription or delivery does not exist
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook is returned when a webhook subscription has invalid values
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrWebhookNotDead is returned when a delivery that is not a dead letter is delivered again
+	ErrWebhookNotDead = errors.New("webhook delivery is not a dead letter")
+)
+
+const (
+	// WebhookDeliveryPending is the status of a delivery waiting for its first attempt
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryRetrying is the status of a delivery that failed and waits for its next attempt
+	WebhookDeliveryRetrying = "retrying"
+	// WebhookDeliverySucceeded is the status of a delivery accepted by the receiver
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryDead is the status of a delivery that failed every attempt (a dead letter)
+	WebhookDeliveryDead = "dead"
+	// WebhookDeliveryRedelivered is the status of a dead letter queued again as a new delivery
+	WebhookDeliveryRedelivered = "redelivered"
+)
+
+// WebhookEventTypes are the types of the events the webhooks can subscribe to
+var WebhookEventTypes = []string{"vehicle.created", "vehicle.updated", "vehicle.deleted", "vehicle.replaced"}
+
+// WebhookSubscription is a struct that represents a partner system notified of the changes of the vehicles
+type WebhookSubscription struct {
+	// Id is the unique identifier of the subscription
+	Id string
+	// Tenant is the tenant whose changes are sent
+	Tenant string
+	// URL is the endpoint the events are posted to
+	URL string
+	// EventTypes are the types of the events sent (e.g. vehicle.created)
+	EventTypes []string
+	// Secret is the key of the HMAC-SHA256 signature of the deliveries
+	Secret string
+	// CreatedAt is the time when the subscription was created
+	CreatedAt time.Time
+}
+
+// WebhookAttempt is a struct that represents an attempt to deliver an event
+type WebhookAttempt struct {
+	// At is the time of the attempt
+	At time.Time
+	// StatusCode is the status code answered by the receiver, 0 if there was no answer
+	StatusCode int
+	// Error describes the failure of the attempt
+	Error string
+	// Duration is the time the attempt took
+	Duration time.Duration
+}
+
+// WebhookDelivery is a struct that represents an event sent to a subscription
+type WebhookDelivery struct {
+	// Id is the unique identifier of the delivery
+	Id string
+	// SubscriptionId is the id of the subscription
+	SubscriptionId string
+	// EventId is the unique identifier of the event, the same for every subscription
+	EventId string
+	// EventType is the type of the event
+	EventType string
+	// Payload is the body posted to the receiver
+	Payload []byte
+	// Status is the status of the delivery (pending, retrying, succeeded, dead)
+	Status string
+	// Attempts are the attempts made, oldest first
+	Attempts []WebhookAttempt
+	// NextAttemptAt is the time of the next attempt of a retrying delivery
+	NextAttemptAt time.Time
+	// CreatedAt is the time when the delivery was created
+	CreatedAt time.Time
+}
+
+// WebhookRepository is an interface that represents the storage of the webhook subscriptions and their deliveries
+type WebhookRepository interface {
+	// CreateSubscription is a method that registers a subscription
+	CreateSubscription(ctx context.Context, s WebhookSubscription) (err error)
+	// FindSubscriptions is a method that returns all the subscriptions, oldest first
+	FindSubscriptions(ctx context.Context) (s []WebhookSubscription, err error)
+	// FindSubscriptionById is a method that returns a subscription by id
+	FindSubscriptionById(ctx context.Context, id string) (s WebhookSubscription, err error)
+	// UpdateSubscription is a method that replaces a subscription
+	UpdateSubscription(ctx context.Context, s WebhookSubscription) (err error)
+	// DeleteSubscription is a method that deletes a subscription and its deliveries
+	DeleteSubscription(ctx context.Context, id string) (err error)
+	// SaveDelivery is a method that creates or replaces a delivery
+	SaveDelivery(ctx context.Context, d WebhookDelivery) (err error)
+	// FindDeliveryById is a method that returns a delivery by id
+	FindDeliveryById(ctx context.Context, id string) (d WebhookDelivery, err error)
+	// FindDeliveries is a method that returns the deliveries of a subscription (every one if empty)
+	// with the status (any if empty), newest first
+	FindDeliveries(ctx context.Context, subscriptionId string, status string) (d []WebhookDelivery, err error)
+}
+
+// WebhookNotifier is an interface that represents the sender of the changes of the vehicles to the webhooks
+type WebhookNotifier interface {
+	// Notify is a method that queues the event for the subscriptions of its tenant to its type. It must not block on the receivers.
+	Notify(ctx context.Context, ev VehicleEvent)
+}
+
+// WebhookDispatcher is an interface that represents the sender of the deliveries, which can queue a dead letter again
+type WebhookDispatcher interface {
+	WebhookNotifier
+	// Redeliver is a method that queues a dead letter again as a new delivery of the same event
+	Redeliver(ctx context.Context, deliveryId string) (d WebhookDelivery, err error)
+}
+
+// WebhookService is an interface that represents the management of the webhooks
+type WebhookService interface {
+	// CreateSubscription is a method that validates and registers a subscription of the tenant of the context,
+	// generating its id and its secret if it has none
+	CreateSubscription(ctx context.Context, s WebhookSubscription) (created WebhookSubscription, err error)
+	// FindSubscriptions is a method that returns all the subscriptions of the tenant of the context
+	FindSubscriptions(ctx context.Context) (s []WebhookSubscription, err error)
+	// FindSubscriptionById is a method that returns a subscription by id
+	FindSubscriptionById(ctx context.Context, id string) (s WebhookSubscription, err error)
+	// UpdateSubscription is a method that validates and replaces the url, the event types and, if set, the secret
+	UpdateSubscription(ctx context.Context, s WebhookSubscription) (updated WebhookSubscription, err error)
+	// DeleteSubscription is a method that deletes a subscription
+	DeleteSubscription(ctx context.Context, id string) (err error)
+	// FindDeliveries is a method that returns the delivery log of a subscription, newest first
+	FindDeliveries(ctx context.Context, subscriptionId string) (d []WebhookDelivery, err error)
+	// FindDeadLetters is a method that returns the deliveries of the tenant that failed every attempt, newest first
+	FindDeadLetters(ctx context.Context) (d []WebhookDelivery, err error)
+	// Redeliver is a method that queues a dead letter again as a new delivery of the same event
+	Redeliver(ctx context.Context, deliveryId string) (d WebhookDelivery, err error)
+}
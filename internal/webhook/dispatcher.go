@@ -0,0 +1,330 @@
+package webhook
+
+import (
+	"app/internal"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConfigDispatcher is a struct that represents the configuration of a Dispatcher
+type ConfigDispatcher struct {
+	// Client is the client used to post the events (nil for a client with Timeout)
+	Client *http.Client
+	// Timeout is the maximum time of an attempt
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery becomes a dead letter
+	MaxAttempts int
+	// Backoff is the time before the first retry, doubled on each retry
+	Backoff time.Duration
+	// MaxBackoff is the maximum time between two attempts
+	MaxBackoff time.Duration
+	// Workers is the number of deliveries attempted at the same time
+	Workers int
+	// QueueSize is the number of deliveries waiting for a worker
+	QueueSize int
+}
+
+// NewDispatcher is a function that returns a new instance of Dispatcher
+func NewDispatcher(rp internal.WebhookRepository, cfg ConfigDispatcher) *Dispatcher {
+	// default values
+	defaultConfig := ConfigDispatcher{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  5 * time.Minute,
+		Workers:     4,
+		QueueSize:   1024,
+	}
+	if cfg.Timeout > 0 {
+		defaultConfig.Timeout = cfg.Timeout
+	}
+	if cfg.MaxAttempts > 0 {
+		defaultConfig.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.Backoff > 0 {
+		defaultConfig.Backoff = cfg.Backoff
+	}
+	if cfg.MaxBackoff > 0 {
+		defaultConfig.MaxBackoff = cfg.MaxBackoff
+	}
+	if cfg.Workers > 0 {
+		defaultConfig.Workers = cfg.Workers
+	}
+	if cfg.QueueSize > 0 {
+		defaultConfig.QueueSize = cfg.QueueSize
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultConfig.Timeout,
+			// a redirect is an answer of the receiver, not an acceptance
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+
+	return &Dispatcher{
+		rp:          rp,
+		client:      client,
+		maxAttempts: defaultConfig.MaxAttempts,
+		backoff:     defaultConfig.Backoff,
+		maxBackoff:  defaultConfig.MaxBackoff,
+		workers:     defaultConfig.Workers,
+		queue:       make(chan string, defaultConfig.QueueSize),
+		timers:      make(map[string]*time.Timer),
+		now:         time.Now,
+	}
+}
+
+// Dispatcher is a struct that delivers the changes of the vehicles to the webhook subscriptions.
+// Each attempt is signed with the secret of the subscription, a failed one is retried with an exponential backoff,
+// and a delivery that fails every attempt is kept as a dead letter. Every attempt is recorded in the delivery log.
+type Dispatcher struct {
+	// rp is the storage of the subscriptions and the deliveries
+	rp internal.WebhookRepository
+	// client is the client used to post the events
+	client *http.Client
+	// maxAttempts is the number of attempts before a delivery becomes a dead letter
+	maxAttempts int
+	// backoff is the time before the first retry
+	backoff time.Duration
+	// maxBackoff is the maximum time between two attempts
+	maxBackoff time.Duration
+	// workers is the number of deliveries attempted at the same time
+	workers int
+	// queue are the ids of the deliveries to attempt
+	queue chan string
+	// mu protects the timers
+	mu sync.Mutex
+	// timers are the retries scheduled by delivery id
+	timers map[string]*time.Timer
+	// stopped is true once the dispatcher stopped, so no retry is scheduled
+	stopped bool
+	// now is the clock of the dispatcher
+	now func() time.Time
+}
+
+// Notify is a method that creates a delivery of the event for each subscription of its tenant to its type and queues them.
+// The tenant of the event defaults to the tenant of the context.
+func (d *Dispatcher) Notify(ctx context.Context, ev internal.VehicleEvent) {
+	eventType := "vehicle." + ev.Type
+	if ev.Tenant == "" {
+		ev.Tenant = internal.Tenant(ctx)
+	}
+	subscriptions, err := d.rp.FindSubscriptions(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhooks: subscriptions not available, event dropped", "event_type", eventType, "error", err)
+		return
+	}
+
+	var payload []byte
+	eventId := newId()
+	for _, s := range subscriptions {
+		if s.Tenant != ev.Tenant || !slices.Contains(s.EventTypes, eventType) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(newPayloadJSON(eventId, eventType, ev, d.now())); err != nil {
+				slog.ErrorContext(ctx, "webhooks: event not serializable, event dropped", "event_type", eventType, "error", err)
+				return
+			}
+		}
+		dl := internal.WebhookDelivery{
+			Id:             newId(),
+			SubscriptionId: s.Id,
+			EventId:        eventId,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         internal.WebhookDeliveryPending,
+			CreatedAt:      d.now(),
+		}
+		if err := d.rp.SaveDelivery(ctx, dl); err != nil {
+			// the subscription was deleted meanwhile
+			continue
+		}
+		d.enqueue(dl.Id)
+	}
+}
+
+// Redeliver is a method that queues a dead letter again as a new delivery of the same event
+func (d *Dispatcher) Redeliver(ctx context.Context, deliveryId string) (dl internal.WebhookDelivery, err error) {
+	dead, err := d.rp.FindDeliveryById(ctx, deliveryId)
+	if err != nil {
+		return
+	}
+	if dead.Status != internal.WebhookDeliveryDead {
+		err = internal.ErrWebhookNotDead
+		return
+	}
+	dl = internal.WebhookDelivery{
+		Id:             newId(),
+		SubscriptionId: dead.SubscriptionId,
+		EventId:        dead.EventId,
+		EventType:      dead.EventType,
+		Payload:        dead.Payload,
+		Status:         internal.WebhookDeliveryPending,
+		CreatedAt:      d.now(),
+	}
+	if err = d.rp.SaveDelivery(ctx, dl); err != nil {
+		return
+	}
+	dead.Status = internal.WebhookDeliveryRedelivered
+	if err = d.rp.SaveDelivery(ctx, dead); err != nil {
+		return
+	}
+	d.enqueue(dl.Id)
+	return
+}
+
+// Run is a method that attempts the queued deliveries until the context is done.
+// The retries still scheduled are dropped, their deliveries stay in the log with the status retrying.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for ix := 0; ix < d.workers; ix++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-d.queue:
+					d.attempt(ctx, id)
+				}
+			}
+		}()
+	}
+	<-ctx.Done()
+
+	d.mu.Lock()
+	d.stopped = true
+	for id, t := range d.timers {
+		t.Stop()
+		delete(d.timers, id)
+	}
+	d.mu.Unlock()
+	wg.Wait()
+}
+
+// enqueue is a method that queues a delivery, or retries later if the queue is full
+func (d *Dispatcher) enqueue(id string) {
+	select {
+	case d.queue <- id:
+	default:
+		d.schedule(id, d.backoff)
+	}
+}
+
+// schedule is a method that queues a delivery after the delay
+func (d *Dispatcher) schedule(id string, delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if t, ok := d.timers[id]; ok {
+		t.Stop()
+	}
+	d.timers[id] = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		delete(d.timers, id)
+		d.mu.Unlock()
+		d.enqueue(id)
+	})
+}
+
+// backoffFor is a method that returns the time before the retry that follows the attempt n (1 for the first one)
+func (d *Dispatcher) backoffFor(n int) time.Duration {
+	delay := d.backoff
+	for ix := 1; ix < n && delay < d.maxBackoff; ix++ {
+		delay *= 2
+	}
+	return min(delay, d.maxBackoff)
+}
+
+// attempt is a method that posts a delivery to its subscription and records the outcome
+func (d *Dispatcher) attempt(ctx context.Context, id string) {
+	dl, err := d.rp.FindDeliveryById(ctx, id)
+	if err != nil || (dl.Status != internal.WebhookDeliveryPending && dl.Status != internal.WebhookDeliveryRetrying) {
+		// the subscription was deleted, or the delivery is done
+		return
+	}
+	s, err := d.rp.FindSubscriptionById(ctx, dl.SubscriptionId)
+	if err != nil {
+		return
+	}
+
+	// post
+	start := d.now()
+	at := internal.WebhookAttempt{At: start}
+	at.StatusCode, err = d.post(ctx, s, dl, start)
+	at.Duration = d.now().Sub(start)
+	if err != nil {
+		at.Error = err.Error()
+	}
+	dl.Attempts = append(dl.Attempts, at)
+
+	// outcome
+	switch {
+	case err == nil:
+		dl.Status = internal.WebhookDeliverySucceeded
+		dl.NextAttemptAt = time.Time{}
+	case len(dl.Attempts) >= d.maxAttempts:
+		dl.Status = internal.WebhookDeliveryDead
+		dl.NextAttemptAt = time.Time{}
+		slog.WarnContext(ctx, "webhooks: delivery failed every attempt", "delivery_id", dl.Id, "subscription_id", s.Id, "event_type", dl.EventType, "attempts", len(dl.Attempts), "error", at.Error)
+	default:
+		delay := d.backoffFor(len(dl.Attempts))
+		dl.Status = internal.WebhookDeliveryRetrying
+		dl.NextAttemptAt = d.now().Add(delay)
+		defer d.schedule(dl.Id, delay)
+	}
+	if err := d.rp.SaveDelivery(ctx, dl); err != nil {
+		slog.WarnContext(ctx, "webhooks: delivery not recorded", "delivery_id", dl.Id, "error", err)
+	}
+}
+
+// post is a method that posts a delivery, signed at the time of the attempt, and fails unless the receiver answers 2xx
+func (d *Dispatcher) post(ctx context.Context, s internal.WebhookSubscription, dl internal.WebhookDelivery, now time.Time) (statusCode int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vehicles-webhooks/1")
+	req.Header.Set(HeaderEventId, dl.EventId)
+	req.Header.Set(HeaderEventType, dl.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(s.Secret, now, dl.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	statusCode = res.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		err = fmt.Errorf("the receiver answered %s", res.Status)
+	}
+	return
+}
+
+// newId is a function that returns a random identifier of 32 hex characters
+func newId() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,203 @@
+package webhook_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/webhook"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a struct that records the deliveries posted to an httptest server
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	status   func(n int) int
+}
+
+// ServeHTTP is a method that records the request and answers the status of the nth one
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	n := len(rc.requests)
+	rc.mu.Unlock()
+	w.WriteHeader(rc.status(n))
+}
+
+// count is a method that returns the number of requests received
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// newDispatcher is a function that returns a running dispatcher with a subscription to the url
+func newDispatcher(t *testing.T, url string) (dp *webhook.Dispatcher, rp *repository.WebhookMap) {
+	rp = repository.NewWebhookMap(0)
+	require.NoError(t, rp.CreateSubscription(context.Background(), internal.WebhookSubscription{
+		Id: "s1", Tenant: internal.TenantDefault, URL: url, EventTypes: []string{"vehicle.created", "vehicle.updated"}, Secret: "shh",
+	}))
+	dp = webhook.NewDispatcher(rp, webhook.ConfigDispatcher{
+		MaxAttempts: 3,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		Workers:     2,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dp.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return
+}
+
+// deliveries is a function that returns the deliveries of the subscription once they all have one of the statuses
+func deliveries(t *testing.T, rp *repository.WebhookMap, n int, status string) (d []internal.WebhookDelivery) {
+	require.Eventually(t, func() bool {
+		d, _ = rp.FindDeliveries(context.Background(), "s1", status)
+		return len(d) == n
+	}, 2*time.Second, 5*time.Millisecond)
+	return
+}
+
+// Tests for Dispatcher
+func TestDispatcher(t *testing.T) {
+	t.Run("case 1: an event is posted signed to the subscriptions to its type", func(t *testing.T) {
+		// arrange
+		rc := &receiver{status: func(n int) int { return http.StatusNoContent }}
+		sv := httptest.NewServer(rc)
+		defer sv.Close()
+		dp, rp := newDispatcher(t, sv.URL)
+
+		// act
+		dp.Notify(context.Background(), internal.VehicleEvent{Type: internal.VehicleEventDeleted, VehicleId: 2})
+		dp.Notify(context.Background(), internal.VehicleEvent{
+			Type: internal.VehicleEventCreated, VehicleId: 1,
+			Vehicle: internal.Vehicle{Id: 1, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford"}},
+		})
+
+		// assert
+		d := deliveries(t, rp, 1, internal.WebhookDeliverySucceeded)
+		require.Len(t, d[0].Attempts, 1)
+		require.Equal(t, http.StatusNoContent, d[0].Attempts[0].StatusCode)
+		require.Equal(t, 1, rc.count())
+		req := rc.requests[0]
+		require.Equal(t, "vehicle.created", req.Header.Get(webhook.HeaderEventType))
+		require.Equal(t, d[0].EventId, req.Header.Get(webhook.HeaderEventId))
+		require.True(t, webhook.Verify("shh", req.Header.Get(webhook.HeaderTimestamp), req.Header.Get(webhook.HeaderSignature), rc.bodies[0], time.Now(), time.Minute))
+		require.False(t, webhook.Verify("other", req.Header.Get(webhook.HeaderTimestamp), req.Header.Get(webhook.HeaderSignature), rc.bodies[0], time.Now(), time.Minute))
+		var payload webhook.PayloadJSON
+		require.NoError(t, json.Unmarshal(rc.bodies[0], &payload))
+		require.Equal(t, "vehicle.created", payload.Type)
+		require.Equal(t, "Ford", payload.Data.Vehicle.Brand)
+	})
+
+	t.Run("case 2: a failed attempt is retried until the receiver accepts it", func(t *testing.T) {
+		// arrange
+		rc := &receiver{status: func(n int) int {
+			if n < 3 {
+				return http.StatusServiceUnavailable
+			}
+			return http.StatusOK
+		}}
+		sv := httptest.NewServer(rc)
+		defer sv.Close()
+		dp, rp := newDispatcher(t, sv.URL)
+
+		// act
+		dp.Notify(context.Background(), internal.VehicleEvent{
+			Type: internal.VehicleEventUpdated, VehicleId: 1,
+			Changes: map[string]internal.FieldChange{"max_speed": {From: 100.0, To: 120.0}},
+		})
+
+		// assert
+		d := deliveries(t, rp, 1, internal.WebhookDeliverySucceeded)
+		require.Len(t, d[0].Attempts, 3)
+		require.Equal(t, http.StatusServiceUnavailable, d[0].Attempts[0].StatusCode)
+		require.NotEmpty(t, d[0].Attempts[0].Error)
+		require.Empty(t, d[0].Attempts[2].Error)
+	})
+
+	t.Run("case 3: a delivery that fails every attempt is a dead letter, which can be delivered again", func(t *testing.T) {
+		// arrange
+		rc := &receiver{status: func(n int) int {
+			if n <= 3 {
+				return http.StatusInternalServerError
+			}
+			return http.StatusOK
+		}}
+		sv := httptest.NewServer(rc)
+		defer sv.Close()
+		dp, rp := newDispatcher(t, sv.URL)
+		dp.Notify(context.Background(), internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: 1})
+		dead := deliveries(t, rp, 1, internal.WebhookDeliveryDead)
+		require.Len(t, dead[0].Attempts, 3)
+
+		// act
+		d, err := dp.Redeliver(context.Background(), dead[0].Id)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, dead[0].EventId, d.EventId)
+		done := deliveries(t, rp, 1, internal.WebhookDeliverySucceeded)
+		require.Equal(t, d.Id, done[0].Id)
+		old, err := rp.FindDeliveryById(context.Background(), dead[0].Id)
+		require.NoError(t, err)
+		require.Equal(t, internal.WebhookDeliveryRedelivered, old.Status)
+		_, err = dp.Redeliver(context.Background(), d.Id)
+		require.ErrorIs(t, err, internal.ErrWebhookNotDead)
+	})
+
+	t.Run("case 4: an unreachable receiver is retried like a failed answer", func(t *testing.T) {
+		// arrange
+		sv := httptest.NewServer(http.NotFoundHandler())
+		url := sv.URL
+		sv.Close()
+		dp, rp := newDispatcher(t, url)
+
+		// act
+		dp.Notify(context.Background(), internal.VehicleEvent{Type: internal.VehicleEventCreated, VehicleId: 1})
+
+		// assert
+		d := deliveries(t, rp, 1, internal.WebhookDeliveryDead)
+		require.Len(t, d[0].Attempts, 3)
+		require.Zero(t, d[0].Attempts[0].StatusCode)
+		require.NotEmpty(t, d[0].Attempts[0].Error)
+	})
+}
+
+// Tests for Verify
+func TestVerify(t *testing.T) {
+	t.Run("case 1: a signature older than the tolerance is rejected", func(t *testing.T) {
+		// arrange
+		at := time.Unix(1700000000, 0)
+		body := []byte(`{}`)
+		signature := webhook.Sign("shh", at, body)
+
+		// act
+		valid := webhook.Verify("shh", "1700000000", signature, body, at.Add(time.Minute), 5*time.Minute)
+		expired := webhook.Verify("shh", "1700000000", signature, body, at.Add(10*time.Minute), 5*time.Minute)
+		tampered := webhook.Verify("shh", "1700000000", signature, []byte(`{"a":1}`), at, 5*time.Minute)
+
+		// assert
+		require.True(t, valid)
+		require.False(t, expired)
+		require.False(t, tampered)
+	})
+}
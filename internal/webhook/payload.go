@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"app/internal"
+	"time"
+)
+
+// VehicleJSON is a struct that represents a vehicle in the payload of a delivery
+type VehicleJSON struct {
+	ID              int      `json:"id"`
+	Brand           string   `json:"brand"`
+	Model           string   `json:"model"`
+	Registration    string   `json:"registration"`
+	Color           string   `json:"color"`
+	FabricationYear int      `json:"year"`
+	Capacity        int      `json:"passengers"`
+	MaxSpeed        float64  `json:"max_speed"`
+	FuelType        string   `json:"fuel_type"`
+	Transmission    string   `json:"transmission"`
+	Weight          float64  `json:"weight"`
+	Height          float64  `json:"height"`
+	Length          float64  `json:"length"`
+	Width           float64  `json:"width"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+// FieldChangeJSON is a struct that represents the change of an attribute in the payload of a delivery
+type FieldChangeJSON struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// PayloadDataJSON is a struct that represents the change in the payload of a delivery
+type PayloadDataJSON struct {
+	VehicleID int                        `json:"vehicle_id,omitempty"`
+	Vehicle   *VehicleJSON               `json:"vehicle,omitempty"`
+	Changes   map[string]FieldChangeJSON `json:"changes,omitempty"`
+	Count     int                        `json:"count,omitempty"`
+}
+
+// PayloadJSON is a struct that represents the body posted to a receiver
+type PayloadJSON struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Tenant     string          `json:"tenant"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       PayloadDataJSON `json:"data"`
+}
+
+// newPayloadJSON is a function that returns the payload of an event.
+// The time of the event defaults to now, as the events of the service are not published on a bus.
+func newPayloadJSON(eventId string, eventType string, ev internal.VehicleEvent, now time.Time) (p PayloadJSON) {
+	p = PayloadJSON{
+		ID:         eventId,
+		Type:       eventType,
+		Tenant:     ev.Tenant,
+		OccurredAt: ev.OccurredAt,
+		Data: PayloadDataJSON{
+			VehicleID: ev.VehicleId,
+			Count:     ev.Count,
+		},
+	}
+	if p.OccurredAt.IsZero() {
+		p.OccurredAt = now
+	}
+	if ev.Type != internal.VehicleEventReplaced {
+		p.Data.Vehicle = &VehicleJSON{
+			ID:              ev.Vehicle.Id,
+			Brand:           ev.Vehicle.Brand,
+			Model:           ev.Vehicle.Model,
+			Registration:    ev.Vehicle.Registration,
+			Color:           ev.Vehicle.Color,
+			FabricationYear: ev.Vehicle.FabricationYear,
+			Capacity:        ev.Vehicle.Capacity,
+			MaxSpeed:        ev.Vehicle.MaxSpeed,
+			FuelType:        ev.Vehicle.FuelType,
+			Transmission:    ev.Vehicle.Transmission,
+			Weight:          ev.Vehicle.Weight,
+			Height:          ev.Vehicle.Height,
+			Length:          ev.Vehicle.Length,
+			Width:           ev.Vehicle.Width,
+			Tags:            ev.Vehicle.Tags,
+		}
+	}
+	if len(ev.Changes) > 0 {
+		p.Data.Changes = make(map[string]FieldChangeJSON, len(ev.Changes))
+		for name, change := range ev.Changes {
+			p.Data.Changes[name] = FieldChangeJSON{From: change.From, To: change.To}
+		}
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderEventId is the header with the id of the event, the same for every attempt
+	HeaderEventId = "X-Webhook-Id"
+	// HeaderEventType is the header with the type of the event
+	HeaderEventType = "X-Webhook-Event"
+	// HeaderTimestamp is the header with the unix time of the attempt, covered by the signature
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is the header with the signature of the attempt, as sha256=<hex>
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign is a function that returns the signature of a delivery: the HMAC-SHA256 with the secret of
+// the timestamp, a dot and the body, so a receiver can reject replays of old deliveries
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify is a function that reports whether the signature and the timestamp headers of a delivery are valid
+// for the secret and the body, and the timestamp is within the tolerance of now
+func Verify(secret string, timestampHeader string, signature string, body []byte, now time.Time, tolerance time.Duration) bool {
+	unix, err := strconv.ParseInt(strings.TrimSpace(timestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+	timestamp := time.Unix(unix, 0)
+	if diff := now.Sub(timestamp); diff > tolerance || diff < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
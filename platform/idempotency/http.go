@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"app/platform/web/response"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	// HeaderKey is the header with the idempotency key of a request
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is the header set on the responses replayed from the store
+	HeaderReplayed = "Idempotent-Replayed"
+	// maxKeyLength is the maximum length of an idempotency key
+	maxKeyLength = 255
+)
+
+// Middleware is a function that returns a middleware that makes the requests with an Idempotency-Key header idempotent.
+// The first response of a key (status, headers and body) is stored for the ttl and replayed to the retries.
+// The key is scoped by the client returned by scope (e.g. the API key), so clients can not read the responses of others.
+// A retry with a different method, path or body gets 422, and a retry while the first request is in progress gets 409.
+// Server errors (5xx) are not stored, so the request can be retried.
+func Middleware(st Store, ttl time.Duration, scope func(r *http.Request) string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				response.Problem(w, http.StatusBadRequest, "the Idempotency-Key header must have at most 255 characters", r.URL.Path)
+				return
+			}
+
+			// request
+			// - the fingerprint covers the body, which is buffered to be read again by the handler
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				response.Problem(w, http.StatusBadRequest, "the body of the request could not be read", r.URL.Path)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			key = scope(r) + ":" + key
+
+			// process
+			res, err := st.Reserve(r.Context(), key, fingerprint(r, body), ttl)
+			switch {
+			case errors.Is(err, ErrFingerprintMismatch):
+				response.Problem(w, http.StatusUnprocessableEntity, "the Idempotency-Key was already used with a different request", r.URL.Path)
+				return
+			case errors.Is(err, ErrInProgress):
+				response.Problem(w, http.StatusConflict, "a request with the Idempotency-Key is still in progress", r.URL.Path)
+				return
+			case err != nil:
+				slog.WarnContext(r.Context(), "idempotency: store failed", "error", err)
+				response.Problem(w, http.StatusServiceUnavailable, "the Idempotency-Key could not be checked, retry later", r.URL.Path)
+				return
+			case res != nil:
+				replay(w, *res)
+				return
+			}
+
+			// - the key is released when the handler does not complete it (server error or panic),
+			//   even if the client is gone
+			rec := &recorder{ResponseWriter: w, statusCode: http.StatusOK}
+			before := w.Header().Clone()
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := st.Release(context.WithoutCancel(r.Context()), key); err != nil {
+					slog.WarnContext(r.Context(), "idempotency: release failed", "error", err)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			// response
+			if rec.statusCode >= http.StatusInternalServerError {
+				return
+			}
+			// - only the headers set by the handler are recorded, the others (e.g. the request id) belong to each request
+			header := make(http.Header)
+			for name, values := range w.Header() {
+				if !slices.Equal(before[name], values) {
+					header[name] = slices.Clone(values)
+				}
+			}
+			if err := st.Complete(context.WithoutCancel(r.Context()), key, Response{StatusCode: rec.statusCode, Header: header, Body: rec.body.Bytes()}); err != nil {
+				slog.WarnContext(r.Context(), "idempotency: complete failed", "error", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// fingerprint is a function that returns the hash of the method, the path and the body of a request
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay is a function that writes a recorded response
+func replay(w http.ResponseWriter, res Response) {
+	for name, values := range res.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(res.StatusCode)
+	w.Write(res.Body)
+}
+
+// recorder is a struct that writes a response and keeps a copy of its status code and body
+type recorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader is a method that writes and keeps the status code
+func (r *recorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write is a method that writes and keeps the body
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
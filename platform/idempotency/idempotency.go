@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInProgress is returned when the first request of a key is still being served
+	ErrInProgress = errors.New("idempotency: a request with the key is in progress")
+	// ErrFingerprintMismatch is returned when a key is reused with a different request
+	ErrFingerprintMismatch = errors.New("idempotency: the key was used with a different request")
+)
+
+// Response is a struct that represents a recorded response, replayed to the retries of a request
+type Response struct {
+	// StatusCode is the status code of the response
+	StatusCode int
+	// Header is the header of the response
+	Header http.Header
+	// Body is the body of the response
+	Body []byte
+}
+
+// Store is an interface that represents the responses recorded by key
+type Store interface {
+	// Reserve is a method that marks the key as in progress for the request with the fingerprint.
+	// If the key already has a response for the same fingerprint, the response is returned to be replayed.
+	Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (res *Response, err error)
+	// Complete is a method that records the response of a reserved key
+	Complete(ctx context.Context, key string, res Response) (err error)
+	// Release is a method that removes a reserved key without response, so the request can be retried
+	Release(ctx context.Context, key string) (err error)
+}
+
+// NewMemoryStore is a function that returns a new instance of MemoryStore
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	// default clock
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryStore{
+		now:       now,
+		entries:   make(map[string]*entry),
+		lastSweep: now(),
+	}
+}
+
+// entry is a struct that represents the state of a key
+type entry struct {
+	fingerprint string
+	response    *Response
+	expires     time.Time
+}
+
+// MemoryStore is a struct that keeps the responses in memory until they expire
+type MemoryStore struct {
+	// now is the clock of the store
+	now func() time.Time
+	// mu protects the entries
+	mu sync.Mutex
+	// entries are the states by key
+	entries map[string]*entry
+	// lastSweep is the last time the expired keys were removed
+	lastSweep time.Time
+}
+
+// sweepInterval is the time between two removals of the expired keys
+const sweepInterval = time.Minute
+
+// Reserve is a method that marks the key as in progress, or returns its response
+func (s *MemoryStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (res *Response, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	switch {
+	case !ok || !now.Before(e.expires):
+		s.entries[key] = &entry{fingerprint: fingerprint, expires: now.Add(ttl)}
+	case e.fingerprint != fingerprint:
+		err = ErrFingerprintMismatch
+	case e.response == nil:
+		err = ErrInProgress
+	default:
+		res = e.response
+	}
+	return
+}
+
+// Complete is a method that records the response of a reserved key
+func (s *MemoryStore) Complete(ctx context.Context, key string, res Response) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.response = &res
+	}
+	return
+}
+
+// Release is a method that removes a reserved key without response
+func (s *MemoryStore) Release(ctx context.Context, key string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.response == nil {
+		delete(s.entries, key)
+	}
+	return
+}
+
+// Len is a method that returns the number of keys in the store
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// sweep is a method that removes the expired keys
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
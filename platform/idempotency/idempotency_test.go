@@ -0,0 +1,152 @@
+package idempotency_test
+
+import (
+	"app/platform/idempotency"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a struct that represents a clock moved by the tests
+type clock struct {
+	now time.Time
+}
+
+// Now is a method that returns the time of the clock
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+// post is a function that returns a POST request with the key and the body
+func post(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(idempotency.HeaderKey, key)
+	}
+	return r
+}
+
+// scope is a function that scopes all the keys to the same client
+func scope(r *http.Request) string {
+	return "alice"
+}
+
+// Tests for Middleware
+func TestMiddleware(t *testing.T) {
+	// creator is a handler that creates a resource per call
+	newCreator := func(calls *atomic.Int64) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":` + strconv.FormatInt(n, 10) + `}`))
+		})
+	}
+
+	t.Run("case 1: a retry gets the first response replayed", func(t *testing.T) {
+		// arrange
+		var calls atomic.Int64
+		hd := idempotency.Middleware(idempotency.NewMemoryStore(nil), time.Hour, scope)(newCreator(&calls))
+
+		// act
+		w1 := httptest.NewRecorder()
+		hd.ServeHTTP(w1, post("k1", `{"brand":"Ford"}`))
+		w2 := httptest.NewRecorder()
+		hd.ServeHTTP(w2, post("k1", `{"brand":"Ford"}`))
+
+		// assert
+		require.Equal(t, int64(1), calls.Load())
+		require.Equal(t, http.StatusCreated, w2.Code)
+		require.Equal(t, w1.Body.String(), w2.Body.String())
+		require.Equal(t, "application/json", w2.Header().Get("Content-Type"))
+		require.Equal(t, "true", w2.Header().Get(idempotency.HeaderReplayed))
+		require.Empty(t, w1.Header().Get(idempotency.HeaderReplayed))
+	})
+
+	t.Run("case 2: the same key with a different body gets 422", func(t *testing.T) {
+		// arrange
+		var calls atomic.Int64
+		hd := idempotency.Middleware(idempotency.NewMemoryStore(nil), time.Hour, scope)(newCreator(&calls))
+		hd.ServeHTTP(httptest.NewRecorder(), post("k1", `{"brand":"Ford"}`))
+
+		// act
+		w := httptest.NewRecorder()
+		hd.ServeHTTP(w, post("k1", `{"brand":"Fiat"}`))
+
+		// assert
+		require.Equal(t, int64(1), calls.Load())
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("case 3: a duplicate while the first request is in progress gets 409", func(t *testing.T) {
+		// arrange
+		started, release := make(chan struct{}), make(chan struct{})
+		hd := idempotency.Middleware(idempotency.NewMemoryStore(nil), time.Hour, scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}))
+		done := make(chan int)
+		go func() {
+			w := httptest.NewRecorder()
+			hd.ServeHTTP(w, post("k1", `{}`))
+			done <- w.Code
+		}()
+		<-started
+
+		// act
+		w := httptest.NewRecorder()
+		hd.ServeHTTP(w, post("k1", `{}`))
+		close(release)
+
+		// assert
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Equal(t, http.StatusCreated, <-done)
+	})
+
+	t.Run("case 4: server errors are not stored, so the request can be retried", func(t *testing.T) {
+		// arrange
+		var calls atomic.Int64
+		hd := idempotency.Middleware(idempotency.NewMemoryStore(nil), time.Hour, scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		// act
+		w1 := httptest.NewRecorder()
+		hd.ServeHTTP(w1, post("k1", `{}`))
+		w2 := httptest.NewRecorder()
+		hd.ServeHTTP(w2, post("k1", `{}`))
+
+		// assert
+		require.Equal(t, http.StatusInternalServerError, w1.Code)
+		require.Equal(t, http.StatusCreated, w2.Code)
+		require.Equal(t, int64(2), calls.Load())
+	})
+
+	t.Run("case 5: the keys expire after the ttl and requests without key are not stored", func(t *testing.T) {
+		// arrange
+		var calls atomic.Int64
+		c := &clock{now: time.Unix(1700000000, 0)}
+		hd := idempotency.Middleware(idempotency.NewMemoryStore(c.Now), time.Minute, scope)(newCreator(&calls))
+		hd.ServeHTTP(httptest.NewRecorder(), post("k1", `{}`))
+
+		// act
+		c.now = c.now.Add(time.Minute)
+		hd.ServeHTTP(httptest.NewRecorder(), post("k1", `{}`))
+		hd.ServeHTTP(httptest.NewRecorder(), post("", `{}`))
+		hd.ServeHTTP(httptest.NewRecorder(), post("", `{}`))
+
+		// assert
+		require.Equal(t, int64(4), calls.Load())
+	})
+}
@@ -0,0 +1,93 @@
+// Package logging builds the structured logger of the application on top of log/slog
+// and carries the request id of each request through the context.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var (
+	// ErrInvalidLevel is returned when the level of the logs is unknown
+	ErrInvalidLevel = errors.New("logging: invalid level")
+	// ErrInvalidFormat is returned when the format of the logs is unknown
+	ErrInvalidFormat = errors.New("logging: invalid format")
+)
+
+const (
+	// FormatText writes the logs as key=value pairs
+	FormatText = "text"
+	// FormatJSON writes the logs as one JSON object per line
+	FormatJSON = "json"
+)
+
+// New is a function that returns a logger writing to w with the minimum level (debug, info, warn, error)
+// and the format (text, json). The records logged with a context carry its request id.
+func New(w io.Writer, level, format string) (l *slog.Logger, err error) {
+	var lv slog.Level
+	if err = lv.UnmarshalText([]byte(level)); err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+		return
+	}
+
+	opts := &slog.HandlerOptions{Level: lv}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		err = fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+		return
+	}
+
+	l = slog.New(NewContextHandler(h))
+	return
+}
+
+// requestIDKey is the key of the request id in the context
+type requestIDKey struct{}
+
+// WithRequestID is a function that returns a copy of the context carrying the request id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID is a function that returns the request id of the context, empty if there is none
+func RequestID(ctx context.Context) (id string) {
+	id, _ = ctx.Value(requestIDKey{}).(string)
+	return
+}
+
+// NewContextHandler is a function that returns a new instance of ContextHandler
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// ContextHandler is a struct that decorates a slog handler to add the request id of the context to each record
+type ContextHandler struct {
+	slog.Handler
+}
+
+// Handle is a method that adds the request id of the context to the record
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs is a method that returns a handler with the attributes, keeping the decoration
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup is a method that returns a handler with the group, keeping the decoration
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
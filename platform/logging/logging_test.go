@@ -0,0 +1,118 @@
+package logging_test
+
+import (
+	"app/platform/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for New
+func TestNew(t *testing.T) {
+	t.Run("case 1: the records logged with a context carry its request id", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		l, err := logging.New(&buf, "debug", "json")
+		require.NoError(t, err)
+		ctx := logging.WithRequestID(context.Background(), "abc-123")
+
+		// act
+		l.DebugContext(ctx, "hello", "key", "value")
+
+		// assert
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		require.Equal(t, "hello", record["msg"])
+		require.Equal(t, "abc-123", record["request_id"])
+		require.Equal(t, "value", record["key"])
+	})
+
+	t.Run("case 2: records below the level are discarded", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		l, err := logging.New(&buf, "warn", "text")
+		require.NoError(t, err)
+
+		// act
+		l.Info("hello")
+
+		// assert
+		require.Empty(t, buf.String())
+	})
+
+	t.Run("case 3: unknown levels and formats are rejected", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		_, errLevel := logging.New(&bytes.Buffer{}, "verbose", "json")
+		_, errFormat := logging.New(&bytes.Buffer{}, "info", "xml")
+
+		// assert
+		require.ErrorIs(t, errLevel, logging.ErrInvalidLevel)
+		require.ErrorIs(t, errFormat, logging.ErrInvalidFormat)
+	})
+}
+
+// Tests for Middleware
+func TestMiddleware(t *testing.T) {
+	// newRouter is a function that returns a router whose handler logs with the context of the request
+	newRouter := func(buf *bytes.Buffer) http.Handler {
+		l, _ := logging.New(buf, "info", "json")
+		rt := chi.NewRouter()
+		rt.Use(logging.Middleware(l))
+		rt.Get("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+			l.InfoContext(r.Context(), "handler")
+			w.WriteHeader(http.StatusNotFound)
+		})
+		return rt
+	}
+
+	t.Run("case 1: the request id of the client is echoed and logged with the route, status and latency", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		rt := newRouter(&buf)
+		req := httptest.NewRequest(http.MethodGet, "/vehicles/7", nil)
+		req.Header.Set(logging.HeaderRequestID, "abc-123")
+
+		// act
+		rr := httptest.NewRecorder()
+		rt.ServeHTTP(rr, req)
+
+		// assert
+		require.Equal(t, "abc-123", rr.Header().Get(logging.HeaderRequestID))
+		dec := json.NewDecoder(&buf)
+		var handler, request map[string]any
+		require.NoError(t, dec.Decode(&handler))
+		require.NoError(t, dec.Decode(&request))
+		require.Equal(t, "abc-123", handler["request_id"])
+		require.Equal(t, "request", request["msg"])
+		require.Equal(t, "abc-123", request["request_id"])
+		require.Equal(t, "/vehicles/{id}", request["route"])
+		require.Equal(t, float64(http.StatusNotFound), request["status"])
+		require.Contains(t, request, "latency")
+	})
+
+	t.Run("case 2: an invalid request id is replaced by a generated one", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		rt := newRouter(&buf)
+		req := httptest.NewRequest(http.MethodGet, "/vehicles/7", nil)
+		req.Header.Set(logging.HeaderRequestID, "bad id\nwith newline")
+
+		// act
+		rr := httptest.NewRecorder()
+		rt.ServeHTTP(rr, req)
+
+		// assert
+		id := rr.Header().Get(logging.HeaderRequestID)
+		require.Len(t, id, 32)
+		require.Contains(t, buf.String(), `"request_id":"`+id+`"`)
+	})
+}
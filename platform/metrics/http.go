@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Handler is a function that returns a handler exposing the metrics of the registry in the Prometheus text format
+func Handler(reg *Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_ = reg.WriteText(w)
+	}
+}
+
+// unmatchedRoute is the route label of the requests that do not match any route,
+// so unknown paths do not create a series each
+const unmatchedRoute = "unmatched"
+
+// NewHTTPMetrics is a function that returns a new instance of HTTPMetrics registered in the registry
+func NewHTTPMetrics(reg *Registry) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: NewCounterVec("http_requests_total", "Number of HTTP requests by method, route pattern and status.", "method", "route", "status"),
+		duration: NewHistogramVec("http_request_duration_seconds", "Latency of the HTTP requests by method, route pattern and status.", nil, "method", "route", "status"),
+		inFlight: NewGaugeVec("http_requests_in_flight", "Number of HTTP requests being served."),
+	}
+	reg.MustRegister(m.requests, m.duration, m.inFlight)
+	return m
+}
+
+// HTTPMetrics is a struct that measures the requests served by a chi router
+type HTTPMetrics struct {
+	// requests is the number of requests
+	requests *CounterVec
+	// duration is the latency of the requests
+	duration *HistogramVec
+	// inFlight is the number of requests being served
+	inFlight *GaugeVec
+}
+
+// Middleware is a method that measures the requests of the handler.
+// The route label is the chi route pattern (e.g. /vehicles/{id}) rather than the path, to bound the number of series.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			// nothing was written, the server answers 200
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+		m.requests.Inc(r.Method, route, code)
+		m.duration.Observe(time.Since(start).Seconds(), r.Method, route, code)
+	})
+}
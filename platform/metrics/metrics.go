@@ -0,0 +1,437 @@
+// Package metrics is a minimal implementation of counters, gauges and histograms
+// exposed in the Prometheus text format (version 0.0.4).
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrDuplicateMetric is returned when a metric with the same name is already registered
+	ErrDuplicateMetric = errors.New("metrics: duplicate metric")
+)
+
+// DefBuckets are the default upper bounds of the buckets of a histogram, in seconds
+var DefBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector is an interface that represents a metric that can be exposed
+type Collector interface {
+	// Name is a method that returns the name of the metric
+	Name() string
+	// Write is a method that writes the metric in the Prometheus text format
+	Write(w io.Writer) (err error)
+}
+
+// NewRegistry is a function that returns a new instance of Registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// Registry is a struct that holds the metrics exposed by the application
+type Registry struct {
+	// mu protects the collectors
+	mu sync.RWMutex
+	// collectors are the registered metrics by name
+	collectors map[string]Collector
+}
+
+// Register is a method that adds a metric to the registry
+func (r *Registry) Register(c Collector) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collectors[c.Name()]; ok {
+		err = fmt.Errorf("%w: %s", ErrDuplicateMetric, c.Name())
+		return
+	}
+	r.collectors[c.Name()] = c
+	return
+}
+
+// MustRegister is a method that adds metrics to the registry and panics if one can not be registered
+func (r *Registry) MustRegister(cs ...Collector) {
+	for _, c := range cs {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WriteText is a method that writes all the metrics sorted by name in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) (err error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := make([]Collector, len(names))
+	sort.Strings(names)
+	for ix, name := range names {
+		collectors[ix] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		if err = c.Write(bw); err != nil {
+			return
+		}
+	}
+	err = bw.Flush()
+	return
+}
+
+// desc is a struct that describes a metric
+type desc struct {
+	// name is the name of the metric
+	name string
+	// help is the description of the metric
+	help string
+	// kind is the type of the metric (counter, gauge, histogram)
+	kind string
+	// labels are the names of the labels of the metric
+	labels []string
+}
+
+// Name is a method that returns the name of the metric
+func (d *desc) Name() string {
+	return d.name
+}
+
+// writeHeader is a method that writes the HELP and TYPE lines of the metric
+func (d *desc) writeHeader(w io.Writer) (err error) {
+	_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+	return
+}
+
+// key is a method that returns the key of the series of the label values
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs is a method that formats the labels of a series, including extra pairs (e.g. le of the buckets)
+func (d *desc) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for ix, value := range values {
+		pairs = append(pairs, d.labels[ix]+`="`+escapeLabel(value)+`"`)
+	}
+	for ix := 0; ix+1 < len(extra); ix += 2 {
+		pairs = append(pairs, extra[ix]+`="`+escapeLabel(extra[ix+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sample is a struct that represents the value of a series
+type sample struct {
+	// values are the values of the labels
+	values []string
+	// value is the value of the series
+	value float64
+}
+
+// NewCounterVec is a function that returns a new counter partitioned by labels
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{vec: newVec(name, help, "counter", labels)}
+}
+
+// CounterVec is a struct that represents a counter partitioned by labels, which only goes up
+type CounterVec struct {
+	*vec
+}
+
+// Inc is a method that increments by one the counter of the label values
+func (c *CounterVec) Inc(values ...string) {
+	c.add(1, values)
+}
+
+// Add is a method that increments the counter of the label values, it panics if delta is negative
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic("metrics: a counter can not decrease")
+	}
+	c.add(delta, values)
+}
+
+// NewGaugeVec is a function that returns a new gauge partitioned by labels
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{vec: newVec(name, help, "gauge", labels)}
+}
+
+// GaugeVec is a struct that represents a gauge partitioned by labels, which can go up and down
+type GaugeVec struct {
+	*vec
+}
+
+// Set is a method that sets the gauge of the label values
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.set(value, values)
+}
+
+// Add is a method that adds delta to the gauge of the label values
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.add(delta, values)
+}
+
+// Inc is a method that increments by one the gauge of the label values
+func (g *GaugeVec) Inc(values ...string) {
+	g.add(1, values)
+}
+
+// Dec is a method that decrements by one the gauge of the label values
+func (g *GaugeVec) Dec(values ...string) {
+	g.add(-1, values)
+}
+
+// newVec is a function that returns a new vec
+func newVec(name, help, kind string, labels []string) *vec {
+	return &vec{
+		desc:   desc{name: name, help: help, kind: kind, labels: labels},
+		series: make(map[string]*sample),
+	}
+}
+
+// vec is a struct that holds the series of a counter or a gauge
+type vec struct {
+	desc
+	// mu protects the series
+	mu sync.Mutex
+	// series are the samples by key of the label values
+	series map[string]*sample
+}
+
+// get is a method that returns the series of the label values, creating it if needed
+func (v *vec) get(values []string) *sample {
+	key := v.key(values)
+	s, ok := v.series[key]
+	if !ok {
+		s = &sample{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	return s
+}
+
+// add is a method that adds delta to the series of the label values
+func (v *vec) add(delta float64, values []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(values).value += delta
+}
+
+// set is a method that sets the series of the label values
+func (v *vec) set(value float64, values []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(values).value = value
+}
+
+// Write is a method that writes the metric in the Prometheus text format
+func (v *vec) Write(w io.Writer) (err error) {
+	v.mu.Lock()
+	samples := make([]sample, 0, len(v.series))
+	for _, s := range v.series {
+		samples = append(samples, *s)
+	}
+	v.mu.Unlock()
+
+	return writeSamples(w, &v.desc, samples)
+}
+
+// NewGaugeFunc is a function that returns a new gauge partitioned by labels whose values are computed
+// by fn on each scrape (e.g. the number of vehicles by fuel type). fn returns the value by label values.
+func NewGaugeFunc(name, help string, labels []string, fn func() map[string]float64) *GaugeFunc {
+	if len(labels) > 1 {
+		panic("metrics: a gauge func supports one label at most")
+	}
+	return &GaugeFunc{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, fn: fn}
+}
+
+// GaugeFunc is a struct that represents a gauge computed on each scrape
+type GaugeFunc struct {
+	desc
+	// fn returns the values of the gauge by label value
+	fn func() map[string]float64
+}
+
+// Write is a method that writes the metric in the Prometheus text format
+func (g *GaugeFunc) Write(w io.Writer) (err error) {
+	values := g.fn()
+	samples := make([]sample, 0, len(values))
+	for label, value := range values {
+		s := sample{value: value}
+		if len(g.labels) > 0 {
+			s.values = []string{label}
+		}
+		samples = append(samples, s)
+	}
+	return writeSamples(w, &g.desc, samples)
+}
+
+// NewGaugeVecFunc is a function that returns a new gauge partitioned by labels whose values are computed
+// by fn on each scrape, for several labels (e.g. the number of vehicles by tenant and fuel type).
+// fn calls set with the value of each series and its label values.
+func NewGaugeVecFunc(name, help string, labels []string, fn func(set func(value float64, values ...string))) *GaugeVecFunc {
+	return &GaugeVecFunc{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, fn: fn}
+}
+
+// GaugeVecFunc is a struct that represents a gauge of several labels computed on each scrape
+type GaugeVecFunc struct {
+	desc
+	// fn sets the values of the gauge by label values
+	fn func(set func(value float64, values ...string))
+}
+
+// Write is a method that writes the metric in the Prometheus text format
+func (g *GaugeVecFunc) Write(w io.Writer) (err error) {
+	v := newVec(g.name, g.help, g.kind, g.labels)
+	g.fn(func(value float64, values ...string) {
+		v.set(value, values)
+	})
+	return v.Write(w)
+}
+
+// writeSamples is a function that writes the samples of a counter or a gauge sorted by label values
+func writeSamples(w io.Writer, d *desc, samples []sample) (err error) {
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].values, "\xff") < strings.Join(samples[j].values, "\xff")
+	})
+	if err = d.writeHeader(w); err != nil {
+		return
+	}
+	for _, s := range samples {
+		if _, err = fmt.Fprintf(w, "%s%s %s\n", d.name, d.labelPairs(s.values), formatFloat(s.value)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// NewHistogramVec is a function that returns a new histogram partitioned by labels.
+// The buckets are the upper bounds sorted in increasing order, DefBuckets if nil.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+// HistogramVec is a struct that represents a histogram partitioned by labels
+type HistogramVec struct {
+	desc
+	// buckets are the upper bounds of the buckets
+	buckets []float64
+	// mu protects the series
+	mu sync.Mutex
+	// series are the observations by key of the label values
+	series map[string]*histogramSeries
+}
+
+// histogramSeries is a struct that holds the observations of the label values of a histogram
+type histogramSeries struct {
+	// values are the values of the labels
+	values []string
+	// counts are the number of observations of each bucket, not cumulative
+	counts []uint64
+	// count is the total number of observations
+	count uint64
+	// sum is the sum of the observations
+	sum float64
+}
+
+// Observe is a method that adds an observation to the histogram of the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if ix := sort.SearchFloat64s(h.buckets, value); ix < len(h.buckets) {
+		s.counts[ix]++
+	}
+	s.count++
+	s.sum += value
+}
+
+// Write is a method that writes the metric in the Prometheus text format
+func (h *HistogramVec) Write(w io.Writer) (err error) {
+	h.mu.Lock()
+	series := make([]histogramSeries, 0, len(h.series))
+	for _, s := range h.series {
+		cp := *s
+		cp.counts = append([]uint64(nil), s.counts...)
+		series = append(series, cp)
+	}
+	h.mu.Unlock()
+	sort.Slice(series, func(i, j int) bool {
+		return strings.Join(series[i].values, "\xff") < strings.Join(series[j].values, "\xff")
+	})
+
+	if err = h.writeHeader(w); err != nil {
+		return
+	}
+	for _, s := range series {
+		var cumulative uint64
+		for ix, upper := range h.buckets {
+			cumulative += s.counts[ix]
+			if _, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.values, "le", formatFloat(upper)), cumulative); err != nil {
+				return
+			}
+		}
+		if _, err = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.values, "le", "+Inf"), s.count); err != nil {
+			return
+		}
+		if _, err = fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(s.values), formatFloat(s.sum)); err != nil {
+			return
+		}
+		if _, err = fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(s.values), s.count); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// formatFloat is a function that formats a value as Prometheus expects it
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel is a function that escapes the value of a label
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// escapeHelp is a function that escapes the description of a metric
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
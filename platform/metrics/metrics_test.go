@@ -0,0 +1,140 @@
+package metrics_test
+
+import (
+	"app/platform/metrics"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Registry.WriteText
+func TestRegistry_WriteText(t *testing.T) {
+	t.Run("case 1: counters and gauges are written sorted by name and label values", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		counter := metrics.NewCounterVec("b_total", "A counter.", "code")
+		gauge := metrics.NewGaugeVec("a_gauge", "A gauge.")
+		reg.MustRegister(counter, gauge)
+		counter.Inc("500")
+		counter.Add(2, "200")
+		gauge.Inc()
+		gauge.Inc()
+		gauge.Dec()
+
+		// act
+		var buf bytes.Buffer
+		err := reg.WriteText(&buf)
+
+		// assert
+		expected := "# HELP a_gauge A gauge.\n# TYPE a_gauge gauge\na_gauge 1\n" +
+			"# HELP b_total A counter.\n# TYPE b_total counter\nb_total{code=\"200\"} 2\nb_total{code=\"500\"} 1\n"
+		require.NoError(t, err)
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("case 2: histograms write cumulative buckets, sum and count", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		histogram := metrics.NewHistogramVec("latency_seconds", "A histogram.", []float64{0.1, 1}, "method")
+		reg.MustRegister(histogram)
+		histogram.Observe(0.05, "FindAll")
+		histogram.Observe(0.1, "FindAll")
+		histogram.Observe(0.5, "FindAll")
+		histogram.Observe(3, "FindAll")
+
+		// act
+		var buf bytes.Buffer
+		err := reg.WriteText(&buf)
+
+		// assert
+		expected := "# HELP latency_seconds A histogram.\n# TYPE latency_seconds histogram\n" +
+			"latency_seconds_bucket{method=\"FindAll\",le=\"0.1\"} 2\n" +
+			"latency_seconds_bucket{method=\"FindAll\",le=\"1\"} 3\n" +
+			"latency_seconds_bucket{method=\"FindAll\",le=\"+Inf\"} 4\n" +
+			"latency_seconds_sum{method=\"FindAll\"} 3.65\n" +
+			"latency_seconds_count{method=\"FindAll\"} 4\n"
+		require.NoError(t, err)
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("case 3: gauge funcs are computed on each scrape and label values are escaped", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		reg.MustRegister(metrics.NewGaugeFunc("vehicles", "Vehicles.", []string{"fuel_type"}, func() map[string]float64 {
+			return map[string]float64{`gas "lpg"`: 3}
+		}))
+
+		// act
+		var buf bytes.Buffer
+		err := reg.WriteText(&buf)
+
+		// assert
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), `vehicles{fuel_type="gas \"lpg\""} 3`)
+	})
+
+	t.Run("case 4: a metric can not be registered twice", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		reg.MustRegister(metrics.NewGaugeVec("a_gauge", "A gauge."))
+
+		// act
+		err := reg.Register(metrics.NewCounterVec("a_gauge", "A counter."))
+
+		// assert
+		require.ErrorIs(t, err, metrics.ErrDuplicateMetric)
+	})
+
+	t.Run("case 5: gauge vec funcs are computed on each scrape with several labels", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		reg.MustRegister(metrics.NewGaugeVecFunc("vehicles", "Vehicles.", []string{"tenant", "fuel_type"}, func(set func(value float64, values ...string)) {
+			set(2, "globex", "diesel")
+			set(3, "acme", "gas")
+		}))
+
+		// act
+		var buf bytes.Buffer
+		err := reg.WriteText(&buf)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "# HELP vehicles Vehicles.\n# TYPE vehicles gauge\n"+
+			"vehicles{tenant=\"acme\",fuel_type=\"gas\"} 3\n"+
+			"vehicles{tenant=\"globex\",fuel_type=\"diesel\"} 2\n", buf.String())
+	})
+}
+
+// Tests for HTTPMetrics.Middleware
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	t.Run("case 1: requests are counted by route pattern and status", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		rt := chi.NewRouter()
+		rt.Use(metrics.NewHTTPMetrics(reg).Middleware)
+		rt.Get("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		rt.Get("/metrics", metrics.Handler(reg))
+
+		// act
+		for _, path := range []string{"/vehicles/1", "/vehicles/2", "/unknown"} {
+			rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+		rr := httptest.NewRecorder()
+		rt.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+		body := rr.Body.String()
+		require.Contains(t, body, `http_requests_total{method="GET",route="/vehicles/{id}",status="404"} 2`)
+		require.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+		require.Contains(t, body, `http_requests_in_flight 1`)
+	})
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"app/platform/web/response"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware is a function that returns a middleware that limits the requests of each client with a token bucket.
+// The buckets of the middleware are kept apart from the other middlewares by the name (e.g. read, write),
+// and the client is the key returned by key (e.g. the API key or the IP).
+// The responses carry the RateLimit-* headers, and the rejected requests get 429 with Retry-After.
+// When the store fails the request is let through, so the limiter does not take the service down.
+func Middleware(st Store, name string, l Limit, key func(r *http.Request) string) func(next http.Handler) http.Handler {
+	policy := fmt.Sprintf("%d;w=%d", l.Requests, ceilSeconds(l.Period))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := st.Take(r.Context(), name+":"+key(r), l)
+			if err != nil {
+				slog.WarnContext(r.Context(), "ratelimit: store failed, request allowed", "limit", name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				response.Problem(w, http.StatusTooManyRequests, fmt.Sprintf("the %s limit of %d requests per %s is exceeded", name, l.Requests, l.Period), r.URL.Path)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds is a function that rounds up a duration to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidLimit is returned when a limit can not be parsed
+	ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+)
+
+// Limit is a struct that represents a token bucket: it holds up to Requests tokens
+// and is refilled at Requests tokens per Period, so a client can burst the whole quota at once
+type Limit struct {
+	// Requests is the capacity of the bucket
+	Requests int
+	// Period is the time to refill the whole bucket
+	Period time.Duration
+}
+
+// ParseLimit is a function that parses a limit written as requests/period (e.g. 100/1m, 10/s)
+func ParseLimit(s string) (l Limit, err error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		err = fmt.Errorf("%w: %q, expected requests/period (e.g. 100/1m)", ErrInvalidLimit, s)
+		return
+	}
+	l.Requests, err = strconv.Atoi(requests)
+	if err != nil || l.Requests <= 0 {
+		err = fmt.Errorf("%w: %q, the requests must be a positive integer", ErrInvalidLimit, s)
+		return
+	}
+	// - a unit alone means one unit (e.g. 10/s)
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	l.Period, err = time.ParseDuration(period)
+	if err != nil || l.Period <= 0 {
+		err = fmt.Errorf("%w: %q, the period must be a positive duration", ErrInvalidLimit, s)
+		return
+	}
+	return
+}
+
+// String is a method that returns the limit as requests/period
+func (l Limit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// rate is a method that returns the number of tokens refilled per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is a struct that represents the outcome of taking a token from a bucket
+type Result struct {
+	// Allowed is true when a token was taken
+	Allowed bool
+	// Limit is the capacity of the bucket
+	Limit int
+	// Remaining is the number of tokens left in the bucket
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token, when the request is not allowed
+	RetryAfter time.Duration
+}
+
+// Store is an interface that represents the state of the buckets of the clients
+type Store interface {
+	// Take is a method that takes a token from the bucket of the key, created full with the limit
+	Take(ctx context.Context, key string, l Limit) (r Result, err error)
+}
+
+// NewMemoryStore is a function that returns a new instance of MemoryStore
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	// default clock
+	if now == nil {
+		now = time.Now
+	}
+	return &MemoryStore{
+		now:       now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: now(),
+	}
+}
+
+// bucket is a struct that represents the tokens of a client at a time
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// MemoryStore is a struct that keeps the buckets in memory, so the limits are per process
+type MemoryStore struct {
+	// now is the clock of the store
+	now func() time.Time
+	// mu protects the buckets
+	mu sync.Mutex
+	// buckets are the buckets by key
+	buckets map[string]*bucket
+	// lastSweep is the last time the full buckets were removed
+	lastSweep time.Time
+}
+
+// sweepInterval is the time between two removals of the full buckets, so idle clients do not grow the store
+const sweepInterval = time.Minute
+
+// Take is a method that takes a token from the bucket of the key
+func (s *MemoryStore) Take(ctx context.Context, key string, l Limit) (r Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != l {
+		// - a new client, or a changed limit, starts with a full bucket
+		b = &bucket{tokens: float64(l.Requests), updated: now, limit: l}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	r.Limit = l.Requests
+	if b.tokens >= 1 {
+		b.tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = seconds((1 - b.tokens) / l.rate())
+	}
+	r.Remaining = int(b.tokens)
+	r.Reset = seconds((float64(l.Requests) - b.tokens) / l.rate())
+	return
+}
+
+// Len is a method that returns the number of buckets in the store
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// sweep is a method that removes the buckets that are full, as they are the same as a new one
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// refill is a method that adds the tokens earned since the last update, up to the capacity
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.limit.rate())
+		b.updated = now
+	}
+}
+
+// seconds is a function that converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,154 @@
+package ratelimit_test
+
+import (
+	"app/platform/ratelimit"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a struct that represents a clock moved by the tests
+type clock struct {
+	now time.Time
+}
+
+// Now is a method that returns the time of the clock
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+// Tests for ParseLimit
+func TestParseLimit(t *testing.T) {
+	t.Run("case 1: the limit is requests/period, a unit alone meaning one unit", func(t *testing.T) {
+		// act
+		l1, err1 := ratelimit.ParseLimit("100/1m")
+		l2, err2 := ratelimit.ParseLimit("10/s")
+
+		// assert
+		require.NoError(t, err1)
+		require.Equal(t, ratelimit.Limit{Requests: 100, Period: time.Minute}, l1)
+		require.NoError(t, err2)
+		require.Equal(t, ratelimit.Limit{Requests: 10, Period: time.Second}, l2)
+	})
+
+	t.Run("case 2: malformed limits are rejected", func(t *testing.T) {
+		for _, s := range []string{"", "100", "0/1m", "-1/1m", "ten/1m", "10/soon", "10/0s"} {
+			// act
+			_, err := ratelimit.ParseLimit(s)
+
+			// assert
+			require.ErrorIs(t, err, ratelimit.ErrInvalidLimit, s)
+		}
+	})
+}
+
+// Tests for MemoryStore
+func TestMemoryStore_Take(t *testing.T) {
+	l := ratelimit.Limit{Requests: 2, Period: 2 * time.Second}
+
+	t.Run("case 1: the bucket allows a burst of its capacity, then refills over time", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		st := ratelimit.NewMemoryStore(c.Now)
+		ctx := context.Background()
+
+		// act & assert
+		r, err := st.Take(ctx, "alice", l)
+		require.NoError(t, err)
+		require.Equal(t, ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, r)
+		r, _ = st.Take(ctx, "alice", l)
+		require.Equal(t, ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, r)
+		r, _ = st.Take(ctx, "alice", l)
+		require.Equal(t, ratelimit.Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}, r)
+
+		c.now = c.now.Add(time.Second)
+		r, _ = st.Take(ctx, "alice", l)
+		require.True(t, r.Allowed)
+	})
+
+	t.Run("case 2: each key has its own bucket", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		st := ratelimit.NewMemoryStore(c.Now)
+		ctx := context.Background()
+		_, _ = st.Take(ctx, "alice", l)
+		_, _ = st.Take(ctx, "alice", l)
+
+		// act
+		r, err := st.Take(ctx, "bob", l)
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, r.Allowed)
+	})
+
+	t.Run("case 3: the buckets refilled by an idle client are removed", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		st := ratelimit.NewMemoryStore(c.Now)
+		ctx := context.Background()
+		_, _ = st.Take(ctx, "alice", l)
+		_, _ = st.Take(ctx, "bob", l)
+
+		// act
+		c.now = c.now.Add(time.Hour)
+		_, _ = st.Take(ctx, "carol", l)
+
+		// assert
+		require.Equal(t, 1, st.Len())
+	})
+}
+
+// Tests for Middleware
+func TestMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	byHeader := func(r *http.Request) string { return r.Header.Get("X-Client") }
+
+	t.Run("case 1: the requests get the RateLimit headers, then 429 with Retry-After", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		hd := ratelimit.Middleware(ratelimit.NewMemoryStore(c.Now), "read", ratelimit.Limit{Requests: 1, Period: time.Minute}, byHeader)(ok)
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set("X-Client", "alice")
+
+		// act
+		w1 := httptest.NewRecorder()
+		hd.ServeHTTP(w1, r)
+		w2 := httptest.NewRecorder()
+		hd.ServeHTTP(w2, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w1.Code)
+		require.Equal(t, "1;w=60", w1.Header().Get("RateLimit-Policy"))
+		require.Equal(t, "1", w1.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "0", w1.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, "60", w1.Header().Get("RateLimit-Reset"))
+		require.Equal(t, http.StatusTooManyRequests, w2.Code)
+		require.Equal(t, "60", w2.Header().Get("Retry-After"))
+		require.Equal(t, "application/problem+json", w2.Header().Get("Content-Type"))
+	})
+
+	t.Run("case 2: the middlewares with different names do not share the buckets", func(t *testing.T) {
+		// arrange
+		st := ratelimit.NewMemoryStore(nil)
+		l := ratelimit.Limit{Requests: 1, Period: time.Minute}
+		read := ratelimit.Middleware(st, "read", l, byHeader)(ok)
+		write := ratelimit.Middleware(st, "write", l, byHeader)(ok)
+		r := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+		r.Header.Set("X-Client", "alice")
+
+		// act
+		w1 := httptest.NewRecorder()
+		read.ServeHTTP(w1, r)
+		w2 := httptest.NewRecorder()
+		write.ServeHTTP(w2, r)
+
+		// assert
+		require.Equal(t, http.StatusOK, w1.Code)
+		require.Equal(t, http.StatusOK, w2.Code)
+	})
+}
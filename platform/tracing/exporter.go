@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// NewWriterExporter is a function that returns an exporter writing the spans to w as JSON lines (e.g. os.Stdout)
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{enc: json.NewEncoder(w)}
+}
+
+// WriterExporter is a struct that writes the spans as JSON lines
+type WriterExporter struct {
+	// mu serializes the writes, so lines are not interleaved
+	mu sync.Mutex
+	// enc writes the spans
+	enc *json.Encoder
+	// closer closes the destination, nil if it is not owned by the exporter
+	closer io.Closer
+}
+
+// NewFileExporter is a function that returns an exporter appending the spans to a file as JSON lines
+func NewFileExporter(path string) (e *WriterExporter, err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	e = NewWriterExporter(file)
+	e.closer = file
+	return
+}
+
+// Export is a method that writes a span
+func (e *WriterExporter) Export(s SpanData) (err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	err = e.enc.Encode(s)
+	return
+}
+
+// Close is a method that closes the file of the exporter, if it owns one
+func (e *WriterExporter) Close() (err error) {
+	if e.closer != nil {
+		err = e.closer.Close()
+	}
+	return
+}
+
+// NewInMemoryExporter is a function that returns a new instance of InMemoryExporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// InMemoryExporter is a struct that keeps the spans in memory, to inspect them in tests
+type InMemoryExporter struct {
+	// mu protects the spans
+	mu sync.Mutex
+	// spans are the exported spans in order of end
+	spans []SpanData
+}
+
+// Export is a method that keeps a span
+func (e *InMemoryExporter) Export(s SpanData) (err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, s)
+	return
+}
+
+// Spans is a method that returns a copy of the exported spans
+func (e *InMemoryExporter) Spans() (s []SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s = append(s, e.spans...)
+	return
+}
+
+// Reset is a method that discards the exported spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
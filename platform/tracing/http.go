@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Middleware is a function that returns a middleware that traces each request as a server span.
+// The span continues the trace of the traceparent header of the request, if any, and is named
+// after the method and the chi route pattern (e.g. GET /vehicles/{id}) once the request is served.
+func Middleware(t *Tracer) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := Extract(r.Context(), r.Header)
+			ctx, span := t.Start(ctx, r.Method)
+			defer span.End()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			route := ""
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			if route != "" {
+				span.SetName(r.Method + " " + route)
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(
+				"http.method", r.Method,
+				"http.route", route,
+				"http.target", r.URL.RequestURI(),
+				"http.status_code", status,
+			)
+			if status >= http.StatusInternalServerError {
+				span.RecordError(&statusError{status: status})
+			}
+		})
+	}
+}
+
+// statusError is a struct that represents a response with a server error status
+type statusError struct {
+	status int
+}
+
+// Error is a method that returns the message of the error
+func (e *statusError) Error() string {
+	return http.StatusText(e.status)
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidTraceparent is returned when a traceparent header is malformed
+	ErrInvalidTraceparent = errors.New("tracing: invalid traceparent")
+)
+
+// HeaderTraceparent is the W3C Trace Context header that carries the span context
+const HeaderTraceparent = "traceparent"
+
+// flagSampled is the trace flag of a sampled span
+const flagSampled = 0x01
+
+// ParseTraceparent is a function that parses a W3C traceparent header (version-traceid-spanid-flags).
+// Versions other than 00 are accepted as long as the first four fields are well formed, as the spec requires.
+func ParseTraceparent(value string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		err = fmt.Errorf("%w: %q", ErrInvalidTraceparent, value)
+		return
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		err = fmt.Errorf("%w: %q", ErrInvalidTraceparent, value)
+		return
+	}
+	var version, flags [1]byte
+	_, e1 := hex.Decode(version[:], []byte(parts[0]))
+	_, e2 := hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	_, e3 := hex.Decode(sc.SpanID[:], []byte(parts[2]))
+	_, e4 := hex.Decode(flags[:], []byte(parts[3]))
+	if errors.Join(e1, e2, e3, e4) != nil || !sc.IsValid() || strings.ToLower(value) != value {
+		err = fmt.Errorf("%w: %q", ErrInvalidTraceparent, value)
+		sc = SpanContext{}
+		return
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+	return
+}
+
+// FormatTraceparent is a function that formats a span context as a W3C traceparent header of version 00
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// Inject is a function that sets the traceparent header of an outgoing request from the span of the context
+func Inject(ctx context.Context, h http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		h.Set(HeaderTraceparent, FormatTraceparent(sc))
+	}
+}
+
+// Extract is a function that returns a copy of the context carrying the span context of the traceparent header
+// of an incoming request. The context is returned as is when the header is missing or invalid.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, err := ParseTraceparent(h.Get(HeaderTraceparent))
+	if err != nil {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
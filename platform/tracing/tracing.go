@@ -0,0 +1,241 @@
+// Package tracing is a minimal tracer that records spans, propagates them with the
+// W3C traceparent header and sends them to a pluggable exporter once they end.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusOK is the status of a span that ended without error
+	StatusOK = "ok"
+	// StatusError is the status of a span that recorded an error
+	StatusError = "error"
+)
+
+// TraceID is the id of a trace, shared by all its spans
+type TraceID [16]byte
+
+// String is a method that returns the id in hex
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid is a method that returns true if the id is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// SpanID is the id of a span
+type SpanID [8]byte
+
+// String is a method that returns the id in hex
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// IsValid is a method that returns true if the id is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// SpanContext is a struct that identifies a span, which can be propagated across processes
+type SpanContext struct {
+	// TraceID is the id of the trace
+	TraceID TraceID
+	// SpanID is the id of the span
+	SpanID SpanID
+	// Sampled is true when the span is recorded
+	Sampled bool
+	// Remote is true when the span context was received from another process
+	Remote bool
+}
+
+// IsValid is a method that returns true if both ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanData is a struct that represents a finished span, as received by the exporters
+type SpanData struct {
+	// Name is the name of the operation
+	Name string `json:"name"`
+	// TraceID is the id of the trace in hex
+	TraceID string `json:"trace_id"`
+	// SpanID is the id of the span in hex
+	SpanID string `json:"span_id"`
+	// ParentID is the id of the parent span in hex, empty for a root span
+	ParentID string `json:"parent_id,omitempty"`
+	// Start is the time when the span started
+	Start time.Time `json:"start"`
+	// End is the time when the span ended
+	End time.Time `json:"end"`
+	// Duration is the duration of the span
+	Duration time.Duration `json:"duration_ns"`
+	// Attributes are the values that describe the operation
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// Status is the status of the span (ok, error)
+	Status string `json:"status"`
+	// Error is the message of the error recorded by the span
+	Error string `json:"error,omitempty"`
+}
+
+// Exporter is an interface that represents the destination of the finished spans
+type Exporter interface {
+	// Export is a method that receives a finished span
+	Export(s SpanData) (err error)
+}
+
+// NewTracer is a function that returns a new instance of Tracer. A nil exporter discards the spans.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Tracer is a struct that starts spans and exports them when they end
+type Tracer struct {
+	// exporter is the destination of the finished spans
+	exporter Exporter
+}
+
+// Start is a method that starts a span, child of the span of the context if any,
+// and returns a copy of the context carrying it
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	s := &Span{
+		tracer:     t,
+		name:       name,
+		start:      time.Now(),
+		attributes: make(map[string]any),
+	}
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		s.parent = parent.SpanID
+		s.sc = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+	} else {
+		s.sc = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	}
+	return context.WithValue(ctx, spanContextKey{}, s.sc), s
+}
+
+// Span is a struct that represents an operation being traced
+type Span struct {
+	// tracer is the tracer that started the span
+	tracer *Tracer
+	// sc identifies the span
+	sc SpanContext
+	// parent is the id of the parent span
+	parent SpanID
+	// mu protects the values set while the span is in progress
+	mu sync.Mutex
+	// name is the name of the operation
+	name string
+	// start is the time when the span started
+	start time.Time
+	// attributes are the values that describe the operation
+	attributes map[string]any
+	// err is the error recorded by the span
+	err error
+	// ended is true once the span ended, so it is exported once
+	ended bool
+}
+
+// SpanContext is a method that returns the identity of the span
+func (s *Span) SpanContext() SpanContext {
+	return s.sc
+}
+
+// SetName is a method that renames the span (e.g. once the route of a request is known)
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttributes is a method that sets attributes as key-value pairs (e.g. "brand", "Ford", "count", 3)
+func (s *Span) SetAttributes(kv ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ix := 0; ix+1 < len(kv); ix += 2 {
+		if key, ok := kv[ix].(string); ok {
+			s.attributes[key] = kv[ix+1]
+		}
+	}
+}
+
+// RecordError is a method that sets the status of the span as error, a nil error is ignored
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = errors.Join(s.err, err)
+}
+
+// End is a method that finishes the span and sends it to the exporter. Only the first call has effect.
+func (s *Span) End() {
+	end := time.Now()
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:       s.name,
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Start:      s.start,
+		End:        end,
+		Duration:   end.Sub(s.start),
+		Attributes: s.attributes,
+		Status:     StatusOK,
+	}
+	if s.parent.IsValid() {
+		data.ParentID = s.parent.String()
+	}
+	if s.err != nil {
+		data.Status = StatusError
+		data.Error = s.err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.tracer.exporter == nil || !s.sc.Sampled {
+		return
+	}
+	// the exporters must not break the traced operation, so their errors are dropped
+	_ = s.tracer.exporter.Export(data)
+}
+
+// spanContextKey is the key of the span context in the context
+type spanContextKey struct{}
+
+// SpanContextFromContext is a function that returns the span context carried by the context, zero if there is none
+func SpanContextFromContext(ctx context.Context) (sc SpanContext) {
+	sc, _ = ctx.Value(spanContextKey{}).(SpanContext)
+	return
+}
+
+// ContextWithRemoteSpanContext is a function that returns a copy of the context carrying a span context
+// received from another process, so the next span started is its child
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// newTraceID is a function that returns a random trace id
+func newTraceID() (t TraceID) {
+	_, _ = rand.Read(t[:])
+	return
+}
+
+// newSpanID is a function that returns a random span id
+func newSpanID() (s SpanID) {
+	_, _ = rand.Read(s[:])
+	return
+}
@@ -0,0 +1,131 @@
+package tracing_test
+
+import (
+	"app/platform/tracing"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseTraceparent
+func TestParseTraceparent(t *testing.T) {
+	t.Run("case 1: a valid header is parsed and formatted back", func(t *testing.T) {
+		// arrange
+		header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+		// act
+		sc, err := tracing.ParseTraceparent(header)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+		require.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+		require.True(t, sc.Sampled)
+		require.Equal(t, header, tracing.FormatTraceparent(sc))
+	})
+
+	t.Run("case 2: malformed headers are rejected", func(t *testing.T) {
+		// arrange
+		headers := []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		}
+
+		for _, header := range headers {
+			// act
+			_, err := tracing.ParseTraceparent(header)
+
+			// assert
+			require.ErrorIs(t, err, tracing.ErrInvalidTraceparent, header)
+		}
+	})
+}
+
+// Tests for Tracer.Start
+func TestTracer_Start(t *testing.T) {
+	t.Run("case 1: spans started from the context of a span are its children", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		tracer := tracing.NewTracer(exporter)
+
+		// act
+		ctx, parent := tracer.Start(context.Background(), "parent")
+		_, child := tracer.Start(ctx, "child")
+		child.SetAttributes("count", 3)
+		child.RecordError(errors.New("boom"))
+		child.End()
+		child.End()
+		parent.End()
+
+		// assert
+		spans := exporter.Spans()
+		require.Len(t, spans, 2)
+		require.Equal(t, "child", spans[0].Name)
+		require.Equal(t, spans[1].TraceID, spans[0].TraceID)
+		require.Equal(t, spans[1].SpanID, spans[0].ParentID)
+		require.Equal(t, tracing.StatusError, spans[0].Status)
+		require.Equal(t, "boom", spans[0].Error)
+		require.Equal(t, 3, spans[0].Attributes["count"])
+		require.Empty(t, spans[1].ParentID)
+		require.Equal(t, tracing.StatusOK, spans[1].Status)
+	})
+
+	t.Run("case 2: spans of a trace not sampled upstream are not exported", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		tracer := tracing.NewTracer(exporter)
+		sc, err := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		require.NoError(t, err)
+		ctx := tracing.ContextWithRemoteSpanContext(context.Background(), sc)
+
+		// act
+		_, span := tracer.Start(ctx, "ignored")
+		span.End()
+
+		// assert
+		require.Empty(t, exporter.Spans())
+	})
+}
+
+// Tests for Middleware
+func TestMiddleware(t *testing.T) {
+	t.Run("case 1: the server span continues the trace of the traceparent header and is named after the route", func(t *testing.T) {
+		// arrange
+		exporter := tracing.NewInMemoryExporter()
+		tracer := tracing.NewTracer(exporter)
+		rt := chi.NewRouter()
+		rt.Use(tracing.Middleware(tracer))
+		var outgoing http.Header
+		rt.Get("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+			outgoing = http.Header{}
+			tracing.Inject(r.Context(), outgoing)
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/vehicles/7", nil)
+		req.Header.Set(tracing.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		// act
+		rt.ServeHTTP(httptest.NewRecorder(), req)
+
+		// assert
+		spans := exporter.Spans()
+		require.Len(t, spans, 1)
+		require.Equal(t, "GET /vehicles/{id}", spans[0].Name)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID)
+		require.Equal(t, "00f067aa0ba902b7", spans[0].ParentID)
+		require.Equal(t, http.StatusInternalServerError, spans[0].Attributes["http.status_code"])
+		require.Equal(t, tracing.StatusError, spans[0].Status)
+		require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spans[0].SpanID+"-01", outgoing.Get(tracing.HeaderTraceparent))
+	})
+}
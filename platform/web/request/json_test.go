@@ -1,7 +1,7 @@
 package request_test
 
 import (
-	"app/put/platform/web/request"
+	"app/platform/web/request"
 	"io"
 	"net/http"
 	"strings"
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemResponse is the body of a problem (RFC 7807)
+type problemResponse struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Problem writes a problem details response (RFC 7807) with the type about:blank,
+// so the title is the status text of the code
+func Problem(w http.ResponseWriter, statusCode int, detail string, instance string) {
+	// default status code
+	defaultStatusCode := http.StatusInternalServerError
+	// check if status code is valid
+	if statusCode > 399 && statusCode < 600 {
+		defaultStatusCode = statusCode
+	}
+
+	// response
+	body := problemResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(defaultStatusCode),
+		Status:   defaultStatusCode,
+		Detail:   detail,
+		Instance: instance,
+	}
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// write response
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(defaultStatusCode)
+	w.Write(bytes)
+}
@@ -0,0 +1,45 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Problem
+func TestProblem(t *testing.T) {
+	t.Run("case 1: should return a problem with status code 403", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		response.Problem(rr, http.StatusForbidden, "the role admin is required", "/vehicles/1")
+
+		// assert
+		expectedCode := http.StatusForbidden
+		expectedBody := `{"type":"about:blank","title":"Forbidden","status":403,"detail":"the role admin is required","instance":"/vehicles/1"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, expectedHeaders, rr.Header())
+	})
+
+	t.Run("case 2: should return status code 500 - invalid code", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		response.Problem(rr, http.StatusOK, "", "")
+
+		// assert
+		expectedCode := http.StatusInternalServerError
+		expectedBody := `{"type":"about:blank","title":"Internal Server Error","status":500}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+}
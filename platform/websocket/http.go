@@ -0,0 +1,141 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBadHandshake is returned when the opening handshake is not valid
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+)
+
+// headerHasToken is a function that reports whether a comma-separated header contains the token, ignoring the case
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade is a function that upgrades an HTTP request to a WebSocket connection.
+// When the handshake is not valid, an error response is written and ErrBadHandshake is returned.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessageSize int64) (c *Conn, err error) {
+	// request
+	key := r.Header.Get("Sec-WebSocket-Key")
+	rawKey, errKey := base64.StdEncoding.DecodeString(key)
+	switch {
+	case r.Method != http.MethodGet:
+		err = fmt.Errorf("%w: method must be GET", ErrBadHandshake)
+	case !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket"):
+		err = fmt.Errorf("%w: missing Upgrade: websocket", ErrBadHandshake)
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		err = fmt.Errorf("%w: unsupported version", ErrBadHandshake)
+	case errKey != nil || len(rawKey) != 16:
+		err = fmt.Errorf("%w: invalid Sec-WebSocket-Key", ErrBadHandshake)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// process
+	nc, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket: the connection can not be upgraded", http.StatusInternalServerError)
+		return
+	}
+	// - the timeouts of the http server do not apply to the connection
+	_ = nc.SetDeadline(time.Time{})
+
+	// response
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err = rw.WriteString(handshake); err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		nc.Close()
+		return
+	}
+	c = newConn(nc, rw.Reader, true, maxMessageSize)
+	return
+}
+
+// Dial is a function that opens a WebSocket connection to the url (ws:// or http://), e.g. for an in-process client.
+// TLS is not supported.
+func Dial(ctx context.Context, rawURL string, header http.Header) (c *Conn, res *http.Response, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	if u.Scheme != "ws" && u.Scheme != "http" {
+		err = fmt.Errorf("%w: unsupported scheme %q", ErrBadHandshake, u.Scheme)
+		return
+	}
+	u.Scheme = "http"
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = nc.SetDeadline(deadline)
+	}
+
+	// request
+	var raw [16]byte
+	if _, err = rand.Read(raw[:]); err != nil {
+		nc.Close()
+		return
+	}
+	key := base64.StdEncoding.EncodeToString(raw[:])
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err = req.Write(nc); err != nil {
+		nc.Close()
+		return
+	}
+
+	// response
+	br := bufio.NewReader(nc)
+	if res, err = http.ReadResponse(br, req); err != nil {
+		nc.Close()
+		return
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols || res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		err = fmt.Errorf("%w: status %s", ErrBadHandshake, res.Status)
+		nc.Close()
+		return
+	}
+	_ = nc.SetDeadline(time.Time{})
+	c = newConn(nc, br, false, 0)
+	return
+}
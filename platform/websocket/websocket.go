@@ -0,0 +1,339 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrMessageTooLarge is returned when a message exceeds the maximum size of the connection
+	ErrMessageTooLarge = errors.New("websocket: message too large")
+	// ErrProtocol is returned when the peer breaks the protocol
+	ErrProtocol = errors.New("websocket: protocol error")
+	// ErrClosed is returned when the connection is closed
+	ErrClosed = errors.New("websocket: connection closed")
+)
+
+const (
+	// TextMessage is the opcode of a text message
+	TextMessage = 1
+	// BinaryMessage is the opcode of a binary message
+	BinaryMessage = 2
+	// closeMessage is the opcode of a close frame
+	closeMessage = 8
+	// pingMessage is the opcode of a ping frame
+	pingMessage = 9
+	// pongMessage is the opcode of a pong frame
+	pongMessage = 10
+	// continuationFrame is the opcode of the next fragment of a message
+	continuationFrame = 0
+)
+
+const (
+	// CloseNormal is the close code of a connection closed as expected
+	CloseNormal = 1000
+	// CloseGoingAway is the close code of a server going down
+	CloseGoingAway = 1001
+	// CloseProtocolError is the close code of a peer that broke the protocol
+	CloseProtocolError = 1002
+	// CloseInvalidPayload is the close code of a text message that is not valid UTF-8
+	CloseInvalidPayload = 1007
+	// ClosePolicyViolation is the close code of a peer that broke a rule of the application (e.g. too slow)
+	ClosePolicyViolation = 1008
+	// CloseMessageTooBig is the close code of a message over the maximum size
+	CloseMessageTooBig = 1009
+	// closeNoStatus is the code reported when a close frame has no code
+	closeNoStatus = 1005
+)
+
+// acceptGUID is the GUID appended to the key of the handshake (RFC 6455)
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// CloseError is a struct that represents the close frame received from the peer
+type CloseError struct {
+	// Code is the close code
+	Code int
+	// Reason is the close reason
+	Reason string
+}
+
+// Error is a method that returns the message of the error
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed by the peer: %d %s", e.Code, e.Reason)
+}
+
+// Is is a method that reports a close of the peer as ErrClosed
+func (e *CloseError) Is(target error) bool {
+	return target == ErrClosed
+}
+
+// acceptKey is a function that returns the value of Sec-WebSocket-Accept for the key of the handshake
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newConn is a function that returns a connection over a network connection after the handshake
+func newConn(nc net.Conn, br *bufio.Reader, server bool, maxMessageSize int64) *Conn {
+	if br == nil {
+		br = bufio.NewReader(nc)
+	}
+	return &Conn{nc: nc, br: br, server: server, maxMessageSize: maxMessageSize}
+}
+
+// Conn is a struct that represents a WebSocket connection (RFC 6455), without extensions.
+// A single goroutine may read and several may write at the same time.
+type Conn struct {
+	// nc is the network connection
+	nc net.Conn
+	// br buffers the reads of the network connection
+	br *bufio.Reader
+	// server is true on the server side, which receives masked frames and sends unmasked ones
+	server bool
+	// maxMessageSize is the maximum size of a message read, 0 for no limit
+	maxMessageSize int64
+	// readTimeout is the maximum time to wait for the next frame, 0 for no limit
+	readTimeout time.Duration
+	// muWrite serializes the writes of frames
+	muWrite sync.Mutex
+	// closeSent is true once a close frame was sent, or a write failed and left a partial frame, protected by muWrite
+	closeSent bool
+}
+
+// SetReadTimeout is a method that sets the maximum time to wait for each frame, pings and pongs included,
+// so a peer that answers the pings keeps an idle connection open
+func (c *Conn) SetReadTimeout(d time.Duration) {
+	c.readTimeout = d
+}
+
+// RemoteAddr is a method that returns the address of the peer
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.nc.RemoteAddr()
+}
+
+// ReadMessage is a method that returns the next message, joining its fragments.
+// The pings are answered and the pongs discarded. A close frame is answered and returned as a *CloseError.
+func (c *Conn) ReadMessage() (opcode int, data []byte, err error) {
+	for {
+		var fin bool
+		var op int
+		var payload []byte
+		if fin, op, payload, err = c.readFrame(); err != nil {
+			return
+		}
+
+		switch op {
+		case pingMessage:
+			if err = c.writeFrame(pongMessage, payload, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+			continue
+		case pongMessage:
+			continue
+		case closeMessage:
+			ce := &CloseError{Code: closeNoStatus}
+			if len(payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Reason = string(payload[2:])
+			}
+			// - the close is echoed, 1005 can not be sent
+			code := ce.Code
+			if code == closeNoStatus {
+				code = CloseNormal
+			}
+			_ = c.Close(code, "")
+			err = ce
+			return
+		case TextMessage, BinaryMessage:
+			if opcode != 0 {
+				err = c.fail(CloseProtocolError, "new message before the end of the previous one", ErrProtocol)
+				return
+			}
+			opcode = op
+		case continuationFrame:
+			if opcode == 0 {
+				err = c.fail(CloseProtocolError, "continuation without message", ErrProtocol)
+				return
+			}
+		default:
+			err = c.fail(CloseProtocolError, "unknown opcode", ErrProtocol)
+			return
+		}
+
+		if c.maxMessageSize > 0 && int64(len(data)+len(payload)) > c.maxMessageSize {
+			err = c.fail(CloseMessageTooBig, "message too large", ErrMessageTooLarge)
+			return
+		}
+		data = append(data, payload...)
+		if fin {
+			break
+		}
+	}
+	if opcode == TextMessage && !utf8.Valid(data) {
+		err = c.fail(CloseInvalidPayload, "invalid UTF-8", ErrProtocol)
+	}
+	return
+}
+
+// WriteMessage is a method that writes a message in a single frame, waiting at most until the deadline
+// (zero for no deadline). A slow peer makes it fail once the deadline is exceeded.
+func (c *Conn) WriteMessage(opcode int, data []byte, deadline time.Time) (err error) {
+	if opcode != TextMessage && opcode != BinaryMessage {
+		return fmt.Errorf("%w: invalid opcode %d", ErrProtocol, opcode)
+	}
+	return c.writeFrame(opcode, data, deadline)
+}
+
+// Ping is a method that sends a ping, answered by a pong that extends the read timeout
+func (c *Conn) Ping(deadline time.Time) (err error) {
+	return c.writeFrame(pingMessage, nil, deadline)
+}
+
+// Close is a method that sends a close frame with the code and the reason, and closes the network connection
+func (c *Conn) Close(code int, reason string) (err error) {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > 125 {
+		payload = payload[:125]
+	}
+	err = c.writeFrame(closeMessage, payload, time.Now().Add(time.Second))
+	if errors.Is(err, ErrClosed) {
+		err = nil
+	}
+	if e := c.nc.Close(); e != nil && err == nil && !errors.Is(e, net.ErrClosed) {
+		err = e
+	}
+	return
+}
+
+// fail is a method that closes the connection after a protocol error and returns the error
+func (c *Conn) fail(code int, reason string, err error) error {
+	_ = c.Close(code, reason)
+	return fmt.Errorf("%w: %s", err, reason)
+}
+
+// readFrame is a method that reads a frame
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	if c.readTimeout > 0 {
+		_ = c.nc.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		err = c.fail(CloseProtocolError, "reserved bits set", ErrProtocol)
+		return
+	}
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	if masked != c.server {
+		err = c.fail(CloseProtocolError, "invalid masking", ErrProtocol)
+		return
+	}
+
+	// - length
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if opcode >= closeMessage && (length > 125 || !fin) {
+		err = c.fail(CloseProtocolError, "invalid control frame", ErrProtocol)
+		return
+	}
+	if length < 0 || (c.maxMessageSize > 0 && length > c.maxMessageSize) {
+		err = c.fail(CloseMessageTooBig, "message too large", ErrMessageTooLarge)
+		return
+	}
+
+	// - payload
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for ix := range payload {
+			payload[ix] ^= mask[ix%4]
+		}
+	}
+	return
+}
+
+// writeFrame is a method that writes a final frame
+func (c *Conn) writeFrame(opcode int, payload []byte, deadline time.Time) (err error) {
+	c.muWrite.Lock()
+	defer c.muWrite.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if opcode == closeMessage {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+	maskBit := byte(0)
+	if !c.server {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	if c.server {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		if _, err = rand.Read(mask[:]); err != nil {
+			return
+		}
+		frame = append(frame, mask[:]...)
+		for ix, b := range payload {
+			frame = append(frame, b^mask[ix%4])
+		}
+	}
+
+	_ = c.nc.SetWriteDeadline(deadline)
+	if _, err = c.nc.Write(frame); err != nil {
+		// the peer can not parse the next frames, so no close frame can follow
+		c.closeSent = true
+	}
+	return
+}
@@ -0,0 +1,130 @@
+package websocket_test
+
+import (
+	"app/platform/websocket"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer is a function that returns a server that echoes the messages until the client closes
+func echoServer(t *testing.T, maxMessageSize int64, closed chan<- error) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Upgrade(w, r, maxMessageSize)
+		if err != nil {
+			return
+		}
+		for {
+			opcode, data, err := c.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			if err := c.WriteMessage(opcode, data, time.Now().Add(time.Second)); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}))
+}
+
+// Tests for Upgrade and Dial
+func TestConn(t *testing.T) {
+	t.Run("case 1: the messages go both ways, small and large", func(t *testing.T) {
+		// arrange
+		closed := make(chan error, 1)
+		srv := echoServer(t, 1<<20, closed)
+		defer srv.Close()
+		c, _, err := websocket.Dial(context.Background(), srv.URL, nil)
+		require.NoError(t, err)
+		large := strings.Repeat("x", 70000)
+
+		// act & assert
+		for _, msg := range []string{"hello", strings.Repeat("y", 300), large} {
+			require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte(msg), time.Now().Add(time.Second)))
+			opcode, data, err := c.ReadMessage()
+			require.NoError(t, err)
+			require.Equal(t, websocket.TextMessage, opcode)
+			require.Equal(t, msg, string(data))
+		}
+		require.NoError(t, c.Close(websocket.CloseNormal, "bye"))
+		var ce *websocket.CloseError
+		require.ErrorAs(t, <-closed, &ce)
+		require.Equal(t, websocket.CloseNormal, ce.Code)
+		require.Equal(t, "bye", ce.Reason)
+	})
+
+	t.Run("case 2: a message over the maximum size closes the connection with 1009", func(t *testing.T) {
+		// arrange
+		closed := make(chan error, 1)
+		srv := echoServer(t, 10, closed)
+		defer srv.Close()
+		c, _, err := websocket.Dial(context.Background(), srv.URL, nil)
+		require.NoError(t, err)
+
+		// act
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte("more than ten bytes"), time.Now().Add(time.Second)))
+		_, _, err = c.ReadMessage()
+
+		// assert
+		require.ErrorIs(t, <-closed, websocket.ErrMessageTooLarge)
+		var ce *websocket.CloseError
+		require.ErrorAs(t, err, &ce)
+		require.Equal(t, websocket.CloseMessageTooBig, ce.Code)
+	})
+
+	t.Run("case 3: a request that is not a handshake gets 400", func(t *testing.T) {
+		// arrange
+		srv := echoServer(t, 0, make(chan error, 1))
+		defer srv.Close()
+
+		// act
+		res, err := http.Get(srv.URL)
+
+		// assert
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		_, _, err = websocket.Dial(context.Background(), "https://example.com", nil)
+		require.True(t, errors.Is(err, websocket.ErrBadHandshake))
+	})
+
+	t.Run("case 4: the pings are answered, which keeps the read timeout from expiring", func(t *testing.T) {
+		// arrange
+		done := make(chan error, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := websocket.Upgrade(w, r, 0)
+			if err != nil {
+				return
+			}
+			c.SetReadTimeout(200 * time.Millisecond)
+			go func() {
+				for ix := 0; ix < 5; ix++ {
+					time.Sleep(100 * time.Millisecond)
+					_ = c.Ping(time.Now().Add(time.Second))
+				}
+				_ = c.WriteMessage(websocket.TextMessage, []byte("done"), time.Now().Add(time.Second))
+			}()
+			_, _, err = c.ReadMessage()
+			done <- err
+		}))
+		defer srv.Close()
+		c, _, err := websocket.Dial(context.Background(), srv.URL, nil)
+		require.NoError(t, err)
+
+		// act
+		_, data, err := c.ReadMessage()
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "done", string(data))
+		require.NoError(t, c.Close(websocket.CloseNormal, ""))
+		require.ErrorIs(t, <-done, websocket.ErrClosed)
+	})
+}